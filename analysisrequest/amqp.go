@@ -1,41 +1,82 @@
 package analysisrequest
 
 import (
-	"encoding/json"
+	"context"
 
+	"github.com/listendev/pkg/observability/tracer"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 func ComposeAMQPPublishing(a AnalysisRequest) (*amqp.Publishing, error) {
-	body, err := json.Marshal(a)
+	return ComposeAMQPPublishingWithEncoding(a, EncodingJSON)
+}
+
+// ComposeAMQPPublishingWithEncoding creates the AMQP publishing of the analysis request using the given encoding.
+func ComposeAMQPPublishingWithEncoding(a AnalysisRequest, e Encoding) (*amqp.Publishing, error) {
+	body, err := e.Encode(a)
 	if err != nil {
 		return nil, err
 	}
 
 	ret := &amqp.Publishing{
-		ContentType: "application/json",
+		ContentType: e.ContentType(),
+		MessageId:   a.ID(),
 		Body:        body,
 	}
 	if a.Prio() > 0 {
 		ret.Priority = a.Prio()
 	}
+	if m := a.Metadata(); !m.IsZero() {
+		ret.Headers = m.headers()
+		if m.CreatedAt != nil {
+			ret.Timestamp = *m.CreatedAt
+		}
+	}
+
+	return ret, nil
+}
+
+// ComposeAMQPPublishingWithContext creates the AMQP publishing of the analysis request using the given encoding,
+// injecting the trace context of the given context into its headers.
+//
+// The consumers continue the trace of the context, instead of the one of the metadata of the analysis request, if any.
+func ComposeAMQPPublishingWithContext(ctx context.Context, a AnalysisRequest, e Encoding) (*amqp.Publishing, error) {
+	ret, err := ComposeAMQPPublishingWithEncoding(a, e)
+	if err != nil {
+		return nil, err
+	}
+	if headers := tracer.InjectAMQP(ctx, ret.Headers); len(headers) > 0 {
+		ret.Headers = headers
+	}
 
 	return ret, nil
 }
 
 func ComposeAMQPDelivery(a AnalysisRequest) (*amqp.Delivery, error) {
-	body, err := json.Marshal(a)
+	return ComposeAMQPDeliveryWithEncoding(a, EncodingJSON)
+}
+
+// ComposeAMQPDeliveryWithEncoding creates the AMQP delivery of the analysis request using the given encoding.
+func ComposeAMQPDeliveryWithEncoding(a AnalysisRequest, e Encoding) (*amqp.Delivery, error) {
+	body, err := e.Encode(a)
 	if err != nil {
 		return nil, err
 	}
 
 	ret := &amqp.Delivery{
-		ContentType: "application/json",
+		ContentType: e.ContentType(),
+		MessageId:   a.ID(),
 		Body:        body,
 	}
 	if a.Prio() > 0 {
 		ret.Priority = a.Prio()
 	}
+	if m := a.Metadata(); !m.IsZero() {
+		ret.Headers = m.headers()
+		if m.CreatedAt != nil {
+			ret.Timestamp = *m.CreatedAt
+		}
+	}
 
 	return ret, nil
 }
@@ -19,6 +19,10 @@ type BasicAnalysisRequest interface {
 	MustProcess() bool
 	// SetForce lets the user change the force attribute of the analysis request
 	SetForce(bool)
+	// Metadata returns the lineage and correlation metadata of the analysis request
+	Metadata() Metadata
+	// SetMetadata lets the user change the lineage and correlation metadata of the analysis request
+	SetMetadata(Metadata)
 	// Validate tells whether the analysis request is ok or not
 	Validate() error
 }
@@ -56,4 +60,6 @@ type Results interface {
 
 type Builder interface {
 	FromJSON(data []byte) (AnalysisRequest, error)
+	// FromDelivery decodes the AMQP delivery body with the encoding registered for its content type
+	FromDelivery(d amqp.Delivery) (AnalysisRequest, error)
 }
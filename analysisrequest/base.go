@@ -18,6 +18,8 @@ type base struct {
 	Snowflake   string `json:"snowflake_id"`
 	Priority    uint8  `json:"priority,omitempty"`
 	Force       bool   `json:"force"`
+	// Meta contains the optional lineage and correlation metadata
+	Meta *Metadata `json:"metadata,omitempty"`
 }
 
 func (arb *base) UnmarshalJSON(data []byte) error {
@@ -35,6 +37,9 @@ func (arb base) Validate() error {
 	if len(arb.Snowflake) == 0 {
 		return errBaseSnowflakeEmpty
 	}
+	if arb.Meta != nil {
+		return arb.Meta.Validate()
+	}
 
 	return nil
 }
@@ -66,3 +71,20 @@ func (arb *base) SetPrio(p uint8) {
 func (arb *base) SetForce(force bool) {
 	arb.Force = force
 }
+
+func (arb base) Metadata() Metadata {
+	if arb.Meta == nil {
+		return Metadata{}
+	}
+
+	return *arb.Meta.clone()
+}
+
+func (arb *base) SetMetadata(m Metadata) {
+	if m.IsZero() {
+		arb.Meta = nil
+
+		return
+	}
+	arb.Meta = m.clone()
+}
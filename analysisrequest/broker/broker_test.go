@@ -0,0 +1,497 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/listendev/pkg/analysisrequest/scheduler"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/observability"
+	"github.com/listendev/pkg/observability/tracer"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func amqpPublishing(t analysisrequest.Type) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType: "application/json",
+		Body:        []byte(`{"type":"` + t.String() + `"}`),
+	}
+}
+
+// notFoundRegistryClient is a npm registry client that never finds package versions.
+type notFoundRegistryClient struct {
+	npm.NoOpRegistryClient
+}
+
+func (c *notFoundRegistryClient) GetPackageVersion(_ context.Context, _, _ string) (*npm.PackageVersion, error) {
+	return nil, npm.ErrVersionNotFound
+}
+
+// cancelingRegistryClient is a npm registry client canceling the consumption when getting package versions.
+type cancelingRegistryClient struct {
+	npm.Registry
+	cancel context.CancelFunc
+}
+
+func (c *cancelingRegistryClient) GetPackageVersion(ctx context.Context, name, version string) (*npm.PackageVersion, error) {
+	c.cancel()
+
+	return c.Registry.GetPackageVersion(ctx, name, version)
+}
+
+func newTestBuilder(t *testing.T, client npm.Registry) analysisrequest.Builder {
+	t.Helper()
+
+	b, err := analysisrequest.NewBuilder(observability.NewNopContext())
+	require.Nil(t, err)
+	b.WithNPMRegistryClient(client)
+
+	return b
+}
+
+func setup(t *testing.T, f analysisrequest.Framework) (*MockBroker, *Publisher) {
+	t.Helper()
+
+	b := NewMockBroker()
+	require.Nil(t, NewTopology(f).Declare(b.Channel()))
+	p, err := NewPublisher(b.Channel(), PublisherConfig{})
+	require.Nil(t, err)
+
+	return b, p
+}
+
+func TestPublish(t *testing.T) {
+	b, p := setup(t, analysisrequest.Hoarding)
+
+	a, err := analysisrequest.NewNPM(analysisrequest.NPMStaticAnalysisShadyLinks, "1524854487523524608", 3, false, "chalk", "5.2.0", "249623b7d66869c673699fb66d65723e54dfcfb3")
+	require.Nil(t, err)
+	require.Nil(t, p.Publish(context.Background(), a))
+	require.Nil(t, p.Publish(context.Background(), a))
+
+	msgs := b.Messages(QueueName(analysisrequest.Hoarding, analysisrequest.StaticAnalysisCollector))
+	require.Len(t, msgs, 2)
+	assert.Equal(t, ExchangeName(analysisrequest.Hoarding), msgs[0].Exchange)
+	assert.Equal(t, "static.shady_links.npm", msgs[0].RoutingKey)
+	assert.Equal(t, uint8(3), msgs[0].Priority)
+	assert.Equal(t, "application/json", msgs[0].ContentType)
+}
+
+func TestPublishToUndeclaredExchange(t *testing.T) {
+	_, p := setup(t, analysisrequest.Hoarding)
+
+	a, err := analysisrequest.NewNPM(analysisrequest.NPMInstallWhileDynamicInstrumentation, "1524854487523524608", 0, false, "chalk", "5.2.0", "249623b7d66869c673699fb66d65723e54dfcfb3")
+	require.Nil(t, err)
+	assert.Error(t, p.Publish(context.Background(), a))
+}
+
+func TestPublishOnClosedChannel(t *testing.T) {
+	b := NewMockBroker()
+	ch := b.Channel()
+	require.Nil(t, NewTopology(analysisrequest.None).Declare(ch))
+	p, err := NewPublisher(ch, PublisherConfig{})
+	require.Nil(t, err)
+	require.Nil(t, ch.Close())
+
+	assert.Error(t, p.Publish(context.Background(), analysisrequest.NewNOP("1524854487523524609", 0, false)))
+}
+
+// unconfirmedChannel is a channel whose publishings the test confirms.
+type unconfirmedChannel struct {
+	*MockChannel
+	seq      uint64
+	confirms chan amqp.Confirmation
+}
+
+func (c *unconfirmedChannel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	c.confirms = confirm
+
+	return confirm
+}
+
+func (c *unconfirmedChannel) PublishWithContext(_ context.Context, _, _ string, _, _ bool, _ amqp.Publishing) error {
+	c.seq++
+
+	return nil
+}
+
+func TestPublishLateConfirmations(t *testing.T) {
+	ch := &unconfirmedChannel{MockChannel: NewMockBroker().Channel()}
+	p, err := NewPublisher(ch, PublisherConfig{ConfirmTimeout: 10 * time.Millisecond})
+	require.Nil(t, err)
+	a := analysisrequest.NewNOP("1524854487523524609", 0, false)
+
+	assert.ErrorIs(t, p.Publish(context.Background(), a), ErrPublishNotConfirmed)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, p.Publish(ctx, a), context.Canceled)
+
+	// The late confirmations, and the unknown ones, never block the connection
+	for tag := uint64(1); tag <= 10; tag++ {
+		select {
+		case ch.confirms <- amqp.Confirmation{DeliveryTag: tag, Ack: true}:
+		case <-time.After(time.Second):
+			require.FailNow(t, "confirmation not drained", tag)
+		}
+	}
+
+	// The confirmations go to the publishings with their delivery tag
+	done := make(chan error)
+	go func() {
+		done <- p.Publish(context.Background(), a)
+	}()
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		return p.pending[3] != nil
+	}, time.Second, time.Millisecond)
+	ch.confirms <- amqp.Confirmation{DeliveryTag: 3, Ack: false}
+	assert.ErrorIs(t, <-done, ErrPublishNacked)
+
+	// The pending publishings fail when the channel closes
+	p.timeout = time.Minute
+	go func() {
+		done <- p.Publish(context.Background(), a)
+	}()
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		return p.pending[4] != nil
+	}, time.Second, time.Millisecond)
+	close(ch.confirms)
+	assert.ErrorIs(t, <-done, ErrChannelClosed)
+	assert.ErrorIs(t, p.Publish(context.Background(), a), ErrChannelClosed)
+}
+
+func TestDispose(t *testing.T) {
+	cases := []struct {
+		input error
+		want  Disposition
+	}{
+		{nil, Ack},
+		{analysisrequest.ErrGivenVersionNotFoundOnNPM, Reject},
+		{analysisrequest.ErrGivenBlake2b256DoesNotMatchOnPyPi, Reject},
+		{analysisrequest.ErrMalfunctioningNPMRegistryClient, Requeue},
+		{errors.Join(analysisrequest.ErrMalfunctioningPyPiRegistryClient, errors.New("timeout")), Requeue},
+		{errors.New("invalid analysis request"), Reject},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, Dispose(tc.input), tc.input)
+	}
+}
+
+func TestConsume(t *testing.T) {
+	b, p := setup(t, analysisrequest.None)
+	queue := QueueName(analysisrequest.None, analysisrequest.NoCollector)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.Nil(t, p.Publish(context.Background(), analysisrequest.NewNOP(id, 0, false)))
+	}
+	require.Equal(t, 3, b.Len(queue))
+
+	c, err := NewConsumer(b.Channel(), newTestBuilder(t, nil), ConsumerConfig{
+		Framework: analysisrequest.None,
+		Collector: analysisrequest.NoCollector,
+		Prefetch:  2,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, queue, c.Queue())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	got := []string{}
+	done := make(chan error)
+	go func() {
+		done <- c.Consume(ctx, func(_ context.Context, a analysisrequest.AnalysisRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, a.ID())
+			if len(got) == 3 {
+				cancel()
+			}
+
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("consumer didn't stop")
+	}
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, got)
+	assert.Equal(t, 0, b.Len(queue))
+}
+
+func TestConsumeEncodings(t *testing.T) {
+	for _, e := range []analysisrequest.Encoding{analysisrequest.EncodingCBOR, analysisrequest.EncodingProtobuf} {
+		t.Run(e.ContentType(), func(t *testing.T) {
+			b := NewMockBroker()
+			require.Nil(t, NewTopology(analysisrequest.None).Declare(b.Channel()))
+			p, err := NewPublisher(b.Channel(), PublisherConfig{Encoding: e})
+			require.Nil(t, err)
+			queue := QueueName(analysisrequest.None, analysisrequest.NoCollector)
+
+			want := analysisrequest.NewNOP("1524854487523524608", 2, true)
+			require.Nil(t, p.Publish(context.Background(), want))
+			msgs := b.Messages(queue)
+			require.Len(t, msgs, 1)
+			assert.Equal(t, e.ContentType(), msgs[0].ContentType)
+
+			c, err := NewConsumer(b.Channel(), newTestBuilder(t, nil), ConsumerConfig{
+				Framework: analysisrequest.None,
+				Collector: analysisrequest.NoCollector,
+			})
+			require.Nil(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var got analysisrequest.AnalysisRequest
+			err = c.Consume(ctx, func(_ context.Context, a analysisrequest.AnalysisRequest) error {
+				got = a
+				cancel()
+
+				return nil
+			})
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, want, got)
+			assert.Equal(t, 0, b.Len(queue))
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	policy := &scheduler.Policy{Aging: time.Minute, Now: func() time.Time { return now }}
+
+	b := NewMockBroker()
+	require.Nil(t, NewTopology(analysisrequest.None).Declare(b.Channel()))
+	p, err := NewPublisher(b.Channel(), PublisherConfig{Policy: policy})
+	require.Nil(t, err)
+	queue := QueueName(analysisrequest.None, analysisrequest.NoCollector)
+
+	// The publisher drops the expired analysis requests
+	expired := analysisrequest.NewNOP("1", 0, false)
+	expired.SetMetadata(analysisrequest.Metadata{Deadline: &now})
+	assert.ErrorIs(t, p.Publish(context.Background(), expired), scheduler.ErrDeadlineExceeded)
+	assert.Equal(t, 0, b.Len(queue))
+
+	// The publisher ages the priority of the other ones
+	created := now.Add(-2 * time.Minute)
+	deadline := now.Add(time.Minute)
+	aged := analysisrequest.NewNOP("2", 0, false)
+	scheduler.Rescan.Apply(aged)
+	aged.SetMetadata(analysisrequest.Metadata{CreatedAt: &created, Deadline: &deadline})
+	require.Nil(t, p.Publish(context.Background(), aged))
+	msgs := b.Messages(queue)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, scheduler.RescanPriority+2, msgs[0].Priority)
+
+	// The consumer drops the analysis requests that expired while queued
+	now = now.Add(time.Hour)
+	ch := b.Channel()
+	c, err := NewConsumer(ch, newTestBuilder(t, nil), ConsumerConfig{
+		Framework: analysisrequest.None,
+		Collector: analysisrequest.NoCollector,
+		Policy:    policy,
+	})
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	// Wait for the consumer to settle the message, otherwise canceling requeues it
+	go cancelWhen(cancel, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		return len(b.queues[queue].messages) == 0 && len(ch.unacked) == 0
+	})
+
+	handled := 0
+	err = c.Consume(ctx, func(_ context.Context, _ analysisrequest.AnalysisRequest) error {
+		handled++
+
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, handled)
+	assert.Equal(t, 0, b.Len(queue))
+}
+
+func TestConsumeDispositions(t *testing.T) {
+	cases := []struct {
+		name        string
+		client      npm.Registry
+		handlerErr  error
+		wantLen     int
+		wantHandled bool
+	}{
+		{
+			name:        "handled",
+			client:      &notFoundRegistryClient{},
+			wantLen:     0,
+			wantHandled: true,
+		},
+		{
+			name:        "handler error is rejected",
+			handlerErr:  errors.New("boom"),
+			wantLen:     0,
+			wantHandled: true,
+		},
+		{
+			name:        "handler transient error is requeued",
+			handlerErr:  analysisrequest.ErrMalfunctioningNPMRegistryClient,
+			wantLen:     1,
+			wantHandled: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, p := setup(t, analysisrequest.None)
+			queue := QueueName(analysisrequest.None, analysisrequest.NoCollector)
+			require.Nil(t, p.Publish(context.Background(), analysisrequest.NewNOP("1", 0, false)))
+
+			c, err := NewConsumer(b.Channel(), newTestBuilder(t, tc.client), ConsumerConfig{
+				Framework: analysisrequest.None,
+				Collector: analysisrequest.NoCollector,
+			})
+			require.Nil(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			handled := false
+			err = c.Consume(ctx, func(_ context.Context, _ analysisrequest.AnalysisRequest) error {
+				handled = true
+				cancel()
+
+				return tc.handlerErr
+			})
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, tc.wantHandled, handled)
+			assert.Equal(t, tc.wantLen, b.Len(queue))
+		})
+	}
+}
+
+func TestConsumeDecodingErrors(t *testing.T) {
+	cases := []struct {
+		name            string
+		client          npm.Registry
+		wantLen         int
+		wantRedelivered bool
+	}{
+		{
+			name:            "malfunctioning registry",
+			client:          npm.NewNoOpRegistryClient(),
+			wantLen:         1,
+			wantRedelivered: true,
+		},
+		{
+			name:    "version not found",
+			client:  &notFoundRegistryClient{},
+			wantLen: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, p := setup(t, analysisrequest.Hoarding)
+			queue := QueueName(analysisrequest.Hoarding, analysisrequest.TyposquatCollector)
+			a, err := analysisrequest.NewNPM(analysisrequest.NPMTyposquat, "1524854487523524608", 0, false, "chalk", "5.2.0", "")
+			require.Nil(t, err)
+			require.Nil(t, p.Publish(context.Background(), a))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			client := &cancelingRegistryClient{Registry: tc.client, cancel: cancel}
+			c, err := NewConsumer(b.Channel(), newTestBuilder(t, client), ConsumerConfig{
+				Framework: analysisrequest.Hoarding,
+				Collector: analysisrequest.TyposquatCollector,
+			})
+			require.Nil(t, err)
+
+			err = c.Consume(ctx, func(_ context.Context, _ analysisrequest.AnalysisRequest) error {
+				t.Fatal("unexpected call to the handler")
+
+				return nil
+			})
+			assert.ErrorIs(t, err, context.Canceled)
+
+			msgs := b.Messages(queue)
+			require.Len(t, msgs, tc.wantLen)
+			if tc.wantLen > 0 {
+				assert.Equal(t, tc.wantRedelivered, msgs[0].Redelivered)
+			}
+		})
+	}
+}
+
+func TestConsumeStopsWhenChannelCloses(t *testing.T) {
+	b, _ := setup(t, analysisrequest.None)
+	ch := b.Channel()
+	c, err := NewConsumer(ch, newTestBuilder(t, nil), ConsumerConfig{
+		Framework: analysisrequest.None,
+		Collector: analysisrequest.NoCollector,
+	})
+	require.Nil(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = ch.Close()
+	}()
+	err = c.Consume(context.Background(), func(_ context.Context, _ analysisrequest.AnalysisRequest) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrChannelClosed)
+}
+
+func TestConsumeContinuesTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx := tracer.NewContext(context.Background(), tp.Tracer("test"))
+
+	b, p := setup(t, analysisrequest.None)
+	require.Nil(t, p.Publish(ctx, analysisrequest.NewNOP("1524854487523524609", 0, false)))
+
+	c, err := NewConsumer(b.Channel(), newTestBuilder(t, nil), ConsumerConfig{
+		Framework: analysisrequest.None,
+		Collector: analysisrequest.NoCollector,
+	})
+	require.Nil(t, err)
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	var got trace.SpanContext
+	err = c.Consume(consumeCtx, func(ctx context.Context, _ analysisrequest.AnalysisRequest) error {
+		_, span := tracer.FromContext(ctx).Start(ctx, "handler")
+		defer span.End()
+		got = span.SpanContext()
+		cancel()
+
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	publishing, handling := spans[0], spans[2]
+	assert.Equal(t, "broker.Publisher.Publish", publishing.Name())
+	assert.Equal(t, "broker.Consumer.handle", handling.Name())
+	assert.Equal(t, publishing.SpanContext().TraceID(), got.TraceID())
+	assert.Equal(t, publishing.SpanContext().TraceID(), handling.SpanContext().TraceID())
+	assert.Contains(t, handling.Attributes(), semconv.MessagingMessageIDKey.String("1524854487523524609"))
+	assert.Contains(t, handling.Attributes(), semconv.MessagingRabbitmqRoutingKeyKey.String("nop"))
+	assert.Contains(t, handling.Attributes(), tracer.MessagingRabbitmqRedeliveredKey.Bool(false))
+}
@@ -0,0 +1,209 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/listendev/pkg/analysisrequest/scheduler"
+	"github.com/listendev/pkg/observability/tracer"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultPrefetch = 1
+
+var errConsumerMissingHandler = errors.New("missing handler")
+
+// Disposition tells what to do with a delivery once handled.
+type Disposition int
+
+const (
+	// Ack acknowledges the delivery.
+	Ack Disposition = iota
+	// Requeue negatively acknowledges the delivery, putting it back in its queue.
+	Requeue
+	// Reject negatively acknowledges the delivery, discarding it (or dead-lettering it).
+	Reject
+)
+
+func (d Disposition) String() string {
+	switch d {
+	case Ack:
+		return "ack"
+	case Requeue:
+		return "requeue"
+	case Reject:
+		return "reject"
+	}
+
+	return fmt.Sprintf("Disposition(%d)", int(d))
+}
+
+// Dispose tells what to do with a delivery given the error its decoding or handling returned.
+//
+// Deliveries failing with transient errors (see IsTransient) get requeued, the other failing ones get rejected.
+func Dispose(err error) Disposition {
+	if err == nil {
+		return Ack
+	}
+	if IsTransient(err) {
+		return Requeue
+	}
+
+	return Reject
+}
+
+// Handler processes an analysis request consumed from a queue.
+type Handler func(ctx context.Context, a analysisrequest.AnalysisRequest) error
+
+type ConsumerConfig struct {
+	Framework analysisrequest.Framework
+	Collector analysisrequest.Collector
+	// Tag is the consumer tag (defaults to an unique tag generated by the broker)
+	Tag string
+	// Prefetch is the number of unacknowledged deliveries the broker sends at once (defaults to 1)
+	//
+	// It is also the number of deliveries handled concurrently.
+	Prefetch int
+	// Retry makes the deliveries failing with transient errors go through the retry queues
+	// rather than being requeued straight away (it must match the one of the topology)
+	Retry *RetryPolicy
+	// Policy, when set, makes the consumer drop the expired analysis requests without handling them
+	Policy *scheduler.Policy
+}
+
+// Consumer consumes the analysis requests from the queue of a collector.
+//
+// It decodes every delivery through a Builder, according to its content type, and then acknowledges, requeues, or rejects it
+// depending on the outcome of the decoding and of the handling (see Dispose).
+type Consumer struct {
+	ch          Channel
+	builder     analysisrequest.Builder
+	framework   analysisrequest.Framework
+	collector   analysisrequest.Collector
+	queue       string
+	tag         string
+	workers     int
+	retryPolicy *RetryPolicy
+	policy      *scheduler.Policy
+}
+
+func NewConsumer(ch Channel, builder analysisrequest.Builder, config ConsumerConfig) (*Consumer, error) {
+	prefetch := defaultPrefetch
+	if config.Prefetch > 0 {
+		prefetch = config.Prefetch
+	}
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		return nil, fmt.Errorf("couldn't set the prefetch count: %w", err)
+	}
+
+	return &Consumer{
+		ch:          ch,
+		builder:     builder,
+		framework:   config.Framework,
+		collector:   config.Collector,
+		queue:       QueueName(config.Framework, config.Collector),
+		tag:         config.Tag,
+		workers:     prefetch,
+		retryPolicy: config.Retry,
+		policy:      config.Policy,
+	}, nil
+}
+
+// Queue returns the name of the queue the consumer consumes from.
+func (c *Consumer) Queue() string {
+	return c.queue
+}
+
+// Consume handles the deliveries until the context is done or the channel gets closed.
+//
+// It returns the context error in the first case, and ErrChannelClosed in the second one.
+func (c *Consumer) Consume(ctx context.Context, h Handler) error {
+	if h == nil {
+		return errConsumerMissingHandler
+	}
+	consumeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	deliveries, err := c.ch.ConsumeWithContext(consumeCtx, c.queue, c.tag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't consume from queue %q: %w", c.queue, err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, c.workers)
+	for range c.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range deliveries {
+				if err := c.handle(consumeCtx, d, h); err != nil {
+					errs <- err
+					// Stop consuming
+					cancel()
+
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return ErrChannelClosed
+}
+
+// handle decodes and handles a delivery, returning an error only when it fails to (negatively) acknowledge it.
+//
+// The handler gets a context continuing the trace the delivery carries.
+func (c *Consumer) handle(ctx context.Context, d amqp.Delivery, h Handler) error {
+	ctx, span := tracer.StartAMQPDeliverySpan(ctx, "broker.Consumer.handle", d)
+	defer span.End()
+
+	a, err := c.builder.FromDelivery(d)
+	if err == nil && c.policy != nil && c.policy.Expired(a) {
+		span.AddEvent("dropped", trace.WithAttributes(attribute.String("reason", scheduler.ErrDeadlineExceeded.Error())))
+
+		return settle(d, Ack)
+	}
+	if err == nil {
+		err = h(ctx, a)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	disposition := Dispose(err)
+	if disposition == Requeue && c.retryPolicy != nil {
+		return c.retry(ctx, d)
+	}
+
+	return settle(d, disposition)
+}
+
+func settle(d amqp.Delivery, disposition Disposition) error {
+	switch disposition {
+	case Ack:
+		return d.Ack(false)
+	case Requeue:
+		return d.Nack(false, true)
+	case Reject:
+		// Dead-letter it, if the queue has a dead letter exchange
+		return d.Nack(false, false)
+	}
+
+	return fmt.Errorf("unknown disposition %q", disposition)
+}
@@ -0,0 +1,603 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var _ Channel = (*MockChannel)(nil)
+
+var (
+	errMockChannelClosed = errors.New("mock channel closed")
+	errMockNotFound      = errors.New("not found")
+	errMockUnknownTag    = errors.New("unknown delivery tag")
+)
+
+type mockBinding struct {
+	queue string
+	key   string
+}
+
+type mockExchange struct {
+	kind     string
+	bindings []mockBinding
+}
+
+type mockQueue struct {
+	name     string
+	args     amqp.Table
+	messages []amqp.Delivery
+	expiries []time.Time
+}
+
+// ttl returns the time-to-live of the messages of the queue, if any.
+func (q *mockQueue) ttl() (time.Duration, bool) {
+	switch v := q.args["x-message-ttl"].(type) {
+	case int:
+		return time.Duration(v) * time.Millisecond, true
+	case int32:
+		return time.Duration(v) * time.Millisecond, true
+	case int64:
+		return time.Duration(v) * time.Millisecond, true
+	}
+
+	return 0, false
+}
+
+// MockBroker is an in-process AMQP broker meant for tests.
+//
+// It supports direct, fanout, and topic exchanges, the default exchange,
+// publisher confirms, prefetch, priority queues (x-max-priority), acknowledgements,
+// dead letter exchanges (x-dead-letter-exchange), and per-queue message TTLs (x-message-ttl).
+type MockBroker struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	exchanges map[string]*mockExchange
+	queues    map[string]*mockQueue
+	consumers atomic.Uint64
+}
+
+func NewMockBroker() *MockBroker {
+	b := &MockBroker{
+		exchanges: map[string]*mockExchange{},
+		queues:    map[string]*mockQueue{},
+	}
+	b.cond = sync.NewCond(&b.mu)
+
+	return b
+}
+
+// Channel opens a new channel on the broker.
+func (b *MockBroker) Channel() *MockChannel {
+	return &MockChannel{
+		broker:  b,
+		unacked: map[uint64]mockUnacked{},
+	}
+}
+
+// Len returns the number of messages ready in the given queue.
+func (b *MockBroker) Len(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queue]
+	if !ok {
+		return 0
+	}
+
+	return len(q.messages)
+}
+
+// Messages returns a copy of the messages ready in the given queue.
+func (b *MockBroker) Messages(queue string) []amqp.Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queue]
+	if !ok {
+		return nil
+	}
+
+	return append([]amqp.Delivery{}, q.messages...)
+}
+
+// route enqueues the given message in the queues the exchange routes it to.
+//
+// It must be called while holding the lock.
+func (b *MockBroker) route(exchange, key string, msg amqp.Delivery) {
+	if exchange == "" {
+		if q, ok := b.queues[key]; ok {
+			b.enqueue(q, msg)
+		}
+
+		return
+	}
+	e, ok := b.exchanges[exchange]
+	if !ok {
+		return
+	}
+	seen := map[string]bool{}
+	for _, bind := range e.bindings {
+		if seen[bind.queue] || !mockMatch(e.kind, bind.key, key) {
+			continue
+		}
+		seen[bind.queue] = true
+		if q, ok := b.queues[bind.queue]; ok {
+			b.enqueue(q, msg)
+		}
+	}
+}
+
+// enqueue appends the given message to the queue.
+//
+// It must be called while holding the lock.
+func (b *MockBroker) enqueue(q *mockQueue, msg amqp.Delivery) {
+	var expiry time.Time
+	if ttl, ok := q.ttl(); ok {
+		expiry = time.Now().Add(ttl)
+		time.AfterFunc(ttl, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.expire(q)
+		})
+	}
+	q.messages = append(q.messages, msg)
+	q.expiries = append(q.expiries, expiry)
+	b.cond.Broadcast()
+}
+
+// expire dead-letters the expired messages of the queue.
+//
+// It must be called while holding the lock.
+func (b *MockBroker) expire(q *mockQueue) {
+	now := time.Now()
+	for i := 0; i < len(q.messages); {
+		if q.expiries[i].IsZero() || q.expiries[i].After(now) {
+			i++
+
+			continue
+		}
+		msg := q.messages[i]
+		b.remove(q, i)
+		b.deadLetter(q, msg)
+	}
+}
+
+// remove removes the i-th message of the queue.
+//
+// It must be called while holding the lock.
+func (b *MockBroker) remove(q *mockQueue, i int) {
+	q.messages = append(q.messages[:i], q.messages[i+1:]...)
+	q.expiries = append(q.expiries[:i], q.expiries[i+1:]...)
+}
+
+// requeue puts the given message back in front of the queue.
+//
+// It must be called while holding the lock.
+func (b *MockBroker) requeue(q *mockQueue, msg amqp.Delivery) {
+	msg.Redelivered = true
+	q.messages = append([]amqp.Delivery{msg}, q.messages...)
+	q.expiries = append([]time.Time{{}}, q.expiries...)
+	b.cond.Broadcast()
+}
+
+// pop removes the next message from the queue.
+//
+// Messages with higher priority come first when the queue is a priority one.
+// It must be called while holding the lock.
+func (b *MockBroker) pop(q *mockQueue) (amqp.Delivery, bool) {
+	if len(q.messages) == 0 {
+		return amqp.Delivery{}, false
+	}
+	idx := 0
+	if _, prio := q.args["x-max-priority"]; prio {
+		for i, m := range q.messages {
+			if m.Priority > q.messages[idx].Priority {
+				idx = i
+			}
+		}
+	}
+	msg := q.messages[idx]
+	b.remove(q, idx)
+
+	return msg, true
+}
+
+// deadLetter routes the given message to the dead letter exchange of its queue, if any, otherwise it drops it.
+//
+// It must be called while holding the lock.
+func (b *MockBroker) deadLetter(q *mockQueue, msg amqp.Delivery) {
+	dlx, ok := q.args["x-dead-letter-exchange"].(string)
+	if !ok {
+		return
+	}
+	key := msg.RoutingKey
+	if k, ok := q.args["x-dead-letter-routing-key"].(string); ok {
+		key = k
+	}
+	msg.Redelivered = false
+	msg.Exchange = dlx
+	msg.RoutingKey = key
+	b.route(dlx, key, msg)
+}
+
+// mockMatch tells whether the binding key matches the routing key for the given kind of exchange.
+func mockMatch(kind, binding, key string) bool {
+	switch kind {
+	case amqp.ExchangeFanout:
+		return true
+	case amqp.ExchangeTopic:
+		return mockTopicMatch(strings.Split(binding, "."), strings.Split(key, "."))
+	}
+
+	return binding == key
+}
+
+func mockTopicMatch(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if mockTopicMatch(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+
+		return false
+	case "*":
+		return len(words) > 0 && mockTopicMatch(pattern[1:], words[1:])
+	}
+
+	return len(words) > 0 && pattern[0] == words[0] && mockTopicMatch(pattern[1:], words[1:])
+}
+
+type mockUnacked struct {
+	queue *mockQueue
+	msg   amqp.Delivery
+}
+
+// MockChannel is a channel of the MockBroker.
+type MockChannel struct {
+	broker   *MockBroker
+	closed   bool
+	confirm  bool
+	seq      uint64
+	tag      uint64
+	prefetch int
+	notifies []chan amqp.Confirmation
+	unacked  map[uint64]mockUnacked
+}
+
+func (c *MockChannel) ExchangeDeclare(name, kind string, _, _, _, _ bool, _ amqp.Table) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return errMockChannelClosed
+	}
+	if e, ok := c.broker.exchanges[name]; ok {
+		if e.kind != kind {
+			return fmt.Errorf("exchange %q already declared with kind %q", name, e.kind)
+		}
+
+		return nil
+	}
+	c.broker.exchanges[name] = &mockExchange{kind: kind}
+
+	return nil
+}
+
+func (c *MockChannel) QueueDeclare(name string, _, _, _, _ bool, args amqp.Table) (amqp.Queue, error) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return amqp.Queue{}, errMockChannelClosed
+	}
+	if name == "" {
+		name = fmt.Sprintf("amq.gen-%d", len(c.broker.queues))
+	}
+	q, ok := c.broker.queues[name]
+	if !ok {
+		q = &mockQueue{name: name, args: args}
+		c.broker.queues[name] = q
+	}
+
+	return amqp.Queue{Name: name, Messages: len(q.messages)}, nil
+}
+
+func (c *MockChannel) QueueBind(name, key, exchange string, _ bool, _ amqp.Table) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return errMockChannelClosed
+	}
+	e, ok := c.broker.exchanges[exchange]
+	if !ok {
+		return fmt.Errorf("exchange %q: %w", exchange, errMockNotFound)
+	}
+	if _, ok := c.broker.queues[name]; !ok {
+		return fmt.Errorf("queue %q: %w", name, errMockNotFound)
+	}
+	e.bindings = append(e.bindings, mockBinding{queue: name, key: key})
+
+	return nil
+}
+
+func (c *MockChannel) Qos(prefetchCount, _ int, _ bool) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return errMockChannelClosed
+	}
+	c.prefetch = prefetchCount
+
+	return nil
+}
+
+func (c *MockChannel) Confirm(_ bool) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return errMockChannelClosed
+	}
+	c.confirm = true
+
+	return nil
+}
+
+func (c *MockChannel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		close(confirm)
+	} else {
+		c.notifies = append(c.notifies, confirm)
+	}
+
+	return confirm
+}
+
+// PublishWithContext routes the message and, when in confirm mode, acknowledges it to the NotifyPublish listeners.
+//
+// Like for the real channels, the listeners must be drained.
+func (c *MockChannel) PublishWithContext(_ context.Context, exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	c.broker.mu.Lock()
+	if c.closed {
+		c.broker.mu.Unlock()
+
+		return errMockChannelClosed
+	}
+	if exchange != "" {
+		if _, ok := c.broker.exchanges[exchange]; !ok {
+			c.broker.mu.Unlock()
+
+			return fmt.Errorf("exchange %q: %w", exchange, errMockNotFound)
+		}
+	}
+	c.broker.route(exchange, key, amqp.Delivery{
+		Headers:         msg.Headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    msg.DeliveryMode,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		Expiration:      msg.Expiration,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		UserId:          msg.UserId,
+		AppId:           msg.AppId,
+		Exchange:        exchange,
+		RoutingKey:      key,
+		Body:            msg.Body,
+	})
+	var confirmation *amqp.Confirmation
+	if c.confirm {
+		c.seq++
+		confirmation = &amqp.Confirmation{DeliveryTag: c.seq, Ack: true}
+	}
+	notifies := append([]chan amqp.Confirmation{}, c.notifies...)
+	c.broker.mu.Unlock()
+
+	if confirmation != nil {
+		for _, n := range notifies {
+			n <- *confirmation
+		}
+	}
+
+	return nil
+}
+
+func (c *MockChannel) ConsumeWithContext(ctx context.Context, queue, consumer string, autoAck, _, _, _ bool, _ amqp.Table) (<-chan amqp.Delivery, error) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return nil, errMockChannelClosed
+	}
+	q, ok := c.broker.queues[queue]
+	if !ok {
+		return nil, fmt.Errorf("queue %q: %w", queue, errMockNotFound)
+	}
+	if consumer == "" {
+		consumer = fmt.Sprintf("ctag-%d", c.broker.consumers.Add(1))
+	}
+
+	// Wake up the consumer when the context is done
+	stop := context.AfterFunc(ctx, func() {
+		c.broker.mu.Lock()
+		defer c.broker.mu.Unlock()
+		c.broker.cond.Broadcast()
+	})
+
+	out := make(chan amqp.Delivery)
+	go func() {
+		defer stop()
+		defer close(out)
+		for {
+			d, ok := c.next(ctx, q, consumer, autoAck)
+			if !ok {
+				return
+			}
+			select {
+			case out <- d:
+			case <-ctx.Done():
+				c.broker.mu.Lock()
+				if !autoAck {
+					delete(c.unacked, d.DeliveryTag)
+					c.broker.requeue(q, d)
+				}
+				c.broker.mu.Unlock()
+
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// next waits for the next message of the queue respecting the prefetch count.
+func (c *MockChannel) next(ctx context.Context, q *mockQueue, consumer string, autoAck bool) (amqp.Delivery, bool) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	for {
+		if c.closed || ctx.Err() != nil {
+			return amqp.Delivery{}, false
+		}
+		if autoAck || c.prefetch <= 0 || len(c.unacked) < c.prefetch {
+			if msg, ok := c.broker.pop(q); ok {
+				return c.deliver(q, msg, consumer, autoAck), true
+			}
+		}
+		c.broker.cond.Wait()
+	}
+}
+
+// deliver assigns a delivery tag to the message, tracking it until acknowledged.
+//
+// It must be called while holding the lock.
+func (c *MockChannel) deliver(q *mockQueue, msg amqp.Delivery, consumer string, autoAck bool) amqp.Delivery {
+	c.tag++
+	msg.DeliveryTag = c.tag
+	msg.ConsumerTag = consumer
+	msg.Acknowledger = c
+	if !autoAck {
+		c.unacked[msg.DeliveryTag] = mockUnacked{queue: q, msg: msg}
+	}
+
+	return msg
+}
+
+func (c *MockChannel) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return amqp.Delivery{}, false, errMockChannelClosed
+	}
+	q, ok := c.broker.queues[queue]
+	if !ok {
+		return amqp.Delivery{}, false, fmt.Errorf("queue %q: %w", queue, errMockNotFound)
+	}
+	msg, ok := c.broker.pop(q)
+	if !ok {
+		return amqp.Delivery{}, false, nil
+	}
+	d := c.deliver(q, msg, "", autoAck)
+	d.MessageCount = uint32(len(q.messages))
+
+	return d, true, nil
+}
+
+// Close closes the channel, requeueing all its unacknowledged messages.
+func (c *MockChannel) Close() error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	for tag, u := range c.unacked {
+		c.broker.requeue(u.queue, u.msg)
+		delete(c.unacked, tag)
+	}
+	for _, n := range c.notifies {
+		close(n)
+	}
+	c.notifies = nil
+	c.broker.cond.Broadcast()
+
+	return nil
+}
+
+func (c *MockChannel) Ack(tag uint64, multiple bool) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	return c.settle(tag, multiple, func(_ mockUnacked) {})
+}
+
+func (c *MockChannel) Nack(tag uint64, multiple, requeue bool) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	return c.settle(tag, multiple, func(u mockUnacked) {
+		if requeue {
+			c.broker.requeue(u.queue, u.msg)
+
+			return
+		}
+		c.broker.deadLetter(u.queue, u.msg)
+	})
+}
+
+func (c *MockChannel) Reject(tag uint64, requeue bool) error {
+	return c.Nack(tag, false, requeue)
+}
+
+// settle applies the given function to the unacknowledged messages with the given tag (or up to it, when multiple).
+//
+// It must be called while holding the lock.
+func (c *MockChannel) settle(tag uint64, multiple bool, f func(u mockUnacked)) error {
+	if c.closed {
+		return errMockChannelClosed
+	}
+	if !multiple {
+		u, ok := c.unacked[tag]
+		if !ok {
+			return fmt.Errorf("%w: %d", errMockUnknownTag, tag)
+		}
+		delete(c.unacked, tag)
+		f(u)
+		c.broker.cond.Broadcast()
+
+		return nil
+	}
+	for t, u := range c.unacked {
+		if t <= tag {
+			delete(c.unacked, t)
+			f(u)
+		}
+	}
+	c.broker.cond.Broadcast()
+
+	return nil
+}
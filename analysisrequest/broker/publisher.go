@@ -0,0 +1,195 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/listendev/pkg/analysisrequest/scheduler"
+	"github.com/listendev/pkg/observability/tracer"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const defaultConfirmTimeout = 5 * time.Second
+
+var (
+	ErrPublishNotConfirmed = errors.New("publishing not confirmed by the broker")
+	ErrPublishNacked       = errors.New("publishing nacked by the broker")
+	ErrChannelClosed       = errors.New("channel closed")
+)
+
+type PublisherConfig struct {
+	// ConfirmTimeout is how long to wait for the broker to confirm a publishing (defaults to 5 seconds)
+	ConfirmTimeout time.Duration
+	// Encoding is the wire encoding of the analysis requests (defaults to JSON)
+	Encoding analysisrequest.Encoding
+	// Policy, when set, makes the publisher drop the expired analysis requests
+	// and age the priority of the other ones since their creation time
+	Policy *scheduler.Policy
+}
+
+// Publisher publishes the analysis requests to the exchange of their framework.
+//
+// It puts the channel in confirm mode and waits for the broker to confirm every publishing.
+// Thus, it expects to be the only one publishing on its channel.
+//
+// A dedicated goroutine drains the confirmations, dispatching them by delivery tag,
+// so that late ones never block the connection.
+type Publisher struct {
+	ch         Channel
+	publishing sync.Mutex
+	seq        uint64
+	mu         sync.Mutex
+	pending    map[uint64]chan amqp.Confirmation
+	closed     bool
+	timeout    time.Duration
+	encoding   analysisrequest.Encoding
+	policy     *scheduler.Policy
+}
+
+func NewPublisher(ch Channel, config PublisherConfig) (*Publisher, error) {
+	if err := ch.Confirm(false); err != nil {
+		return nil, fmt.Errorf("couldn't put the channel in confirm mode: %w", err)
+	}
+	timeout := defaultConfirmTimeout
+	if config.ConfirmTimeout != 0 {
+		timeout = config.ConfirmTimeout
+	}
+	encoding := analysisrequest.EncodingJSON
+	if config.Encoding != nil {
+		encoding = config.Encoding
+	}
+
+	p := &Publisher{
+		ch:       ch,
+		pending:  map[uint64]chan amqp.Confirmation{},
+		timeout:  timeout,
+		encoding: encoding,
+		policy:   config.Policy,
+	}
+	go p.dispatch(ch.NotifyPublish(make(chan amqp.Confirmation, 1)))
+
+	return p, nil
+}
+
+// dispatch hands the confirmations to the publishings waiting for them, until the channel closes.
+//
+// It drops the confirmations nobody waits for anymore (eg., the ones of the publishings that timed out).
+func (p *Publisher) dispatch(confirms <-chan amqp.Confirmation) {
+	for c := range confirms {
+		p.mu.Lock()
+		if w, ok := p.pending[c.DeliveryTag]; ok {
+			delete(p.pending, c.DeliveryTag)
+			w <- c
+		}
+		p.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for tag, w := range p.pending {
+		delete(p.pending, tag)
+		close(w)
+	}
+}
+
+// Publish publishes the given analysis request and waits for the broker to confirm it.
+//
+// It returns scheduler.ErrDeadlineExceeded, without publishing it, when the policy says the analysis request expired.
+func (p *Publisher) Publish(ctx context.Context, a analysisrequest.AnalysisRequest) error {
+	if p.policy != nil && p.policy.Expired(a) {
+		return scheduler.ErrDeadlineExceeded
+	}
+	msg, err := analysisrequest.ComposeAMQPPublishingWithEncoding(a, p.encoding)
+	if err != nil {
+		return err
+	}
+	if p.policy != nil {
+		msg.Priority = p.policy.Age(a)
+	}
+	t := a.Type()
+	exchange := ExchangeName(t.Components().Framework)
+	key := RoutingKey(t)
+
+	// Propagate the trace context to the consumers
+	ctx, span := tracer.StartAMQPPublishingSpan(ctx, "broker.Publisher.Publish", exchange, key, msg)
+	defer span.End()
+
+	if err := p.publish(ctx, exchange, key, *msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+func (p *Publisher) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	tag, confirmation, err := p.send(ctx, exchange, key, msg)
+	if err != nil {
+		return err
+	}
+	defer p.forget(tag)
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case c, ok := <-confirmation:
+		if !ok {
+			return ErrChannelClosed
+		}
+		if !c.Ack {
+			return ErrPublishNacked
+		}
+
+		return nil
+	case <-timer.C:
+		return ErrPublishNotConfirmed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// send publishes the message, returning its delivery tag and where its confirmation will arrive.
+//
+// It registers the wait for the confirmation before publishing, since the broker can confirm before the publishing returns.
+func (p *Publisher) send(ctx context.Context, exchange, key string, msg amqp.Publishing) (uint64, <-chan amqp.Confirmation, error) {
+	p.publishing.Lock()
+	defer p.publishing.Unlock()
+
+	// Delivery tags start from 1 when the channel enters confirm mode
+	tag := p.seq + 1
+	confirmation := make(chan amqp.Confirmation, 1)
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return 0, nil, ErrChannelClosed
+	}
+	p.pending[tag] = confirmation
+	p.mu.Unlock()
+
+	if err := p.ch.PublishWithContext(ctx, exchange, key, false, false, msg); err != nil {
+		p.forget(tag)
+
+		return 0, nil, err
+	}
+	p.seq = tag
+
+	return tag, confirmation, nil
+}
+
+// forget stops waiting for the confirmation of the given delivery tag.
+func (p *Publisher) forget(tag uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.pending, tag)
+}
@@ -0,0 +1,215 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/pypi"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// HeaderAttempt is the AMQP header counting the retries of a delivery.
+const HeaderAttempt = "x-retry-attempt"
+
+// RetryPolicy configures the dead letter queue and the retry queues of the collector queues.
+//
+// Every retry attempt has its own queue whose messages expire after the attempt delay,
+// going back to the collector queue through the default exchange.
+type RetryPolicy struct {
+	// Delays are the delays before each retry attempt, thus their number is the maximum number of retries
+	Delays []time.Duration
+}
+
+// MaxAttempts returns the maximum number of retries.
+func (r *RetryPolicy) MaxAttempts() int {
+	if r == nil {
+		return 0
+	}
+
+	return len(r.Delays)
+}
+
+// DeadLetterQueueName returns the name of the queue collecting the deliveries of the given collector queue that failed for good.
+func DeadLetterQueueName(f analysisrequest.Framework, c analysisrequest.Collector) string {
+	return QueueName(f, c) + ".dead"
+}
+
+// RetryQueueName returns the name of the queue delaying the given retry attempt of the deliveries of the given collector queue.
+func RetryQueueName(f analysisrequest.Framework, c analysisrequest.Collector, attempt int) string {
+	return QueueName(f, c) + ".retry." + strconv.Itoa(attempt)
+}
+
+// declare declares the dead letter queue and the retry queues of the given collector queue.
+func (r *RetryPolicy) declare(ch Channel, f analysisrequest.Framework, c analysisrequest.Collector) error {
+	dlq := DeadLetterQueueName(f, c)
+	if _, err := ch.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("couldn't declare dead letter queue %q: %w", dlq, err)
+	}
+	for i, d := range r.Delays {
+		name := RetryQueueName(f, c, i+1)
+		args := amqp.Table{
+			"x-message-ttl":             d.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": QueueName(f, c),
+		}
+		if _, err := ch.QueueDeclare(name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("couldn't declare retry queue %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Attempt returns the number of times the given delivery has been retried.
+func Attempt(d amqp.Delivery) int {
+	switch v := d.Headers[HeaderAttempt].(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	}
+
+	return 0
+}
+
+// TransientError marks an error as transient, so that the delivery it refers to gets retried.
+type TransientError struct {
+	Err error
+}
+
+func (e TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e TransientError) Unwrap() error {
+	return e.Err
+}
+
+// PermanentError marks an error as permanent, so that the delivery it refers to gets dead-lettered.
+type PermanentError struct {
+	Err error
+}
+
+func (e PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient tells whether the given error is worth retrying.
+//
+// Errors marked with PermanentError are never transient.
+// Errors marked with TransientError, errors due to malfunctioning registry clients, and
+// registry service errors with 5xx status codes are transient.
+// All the other ones (eg., versions not found on the registry, validation errors) are permanent.
+func IsTransient(err error) bool {
+	if err == nil || errors.As(err, &PermanentError{}) {
+		return false
+	}
+	if errors.As(err, &TransientError{}) {
+		return true
+	}
+	if errors.Is(err, analysisrequest.ErrMalfunctioningNPMRegistryClient) || errors.Is(err, analysisrequest.ErrMalfunctioningPyPiRegistryClient) {
+		return true
+	}
+	var npmErr *npm.ServiceError
+	if errors.As(err, &npmErr) && npmErr.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+	var pypiErr *pypi.ServiceError
+	if errors.As(err, &pypiErr) && pypiErr.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+
+	return false
+}
+
+// retry publishes a copy of the given delivery to the queue of its next retry attempt, and then acknowledges it.
+//
+// When the delivery exhausted its attempts it rejects it, so that it gets dead-lettered.
+func (c *Consumer) retry(ctx context.Context, d amqp.Delivery) error {
+	next := Attempt(d) + 1
+	if next > c.retryPolicy.MaxAttempts() {
+		return d.Nack(false, false)
+	}
+
+	msg := publishingFromDelivery(d)
+	msg.Headers[HeaderAttempt] = int64(next)
+	if err := c.ch.PublishWithContext(ctx, "", RetryQueueName(c.framework, c.collector, next), false, false, msg); err != nil {
+		// Let the broker redeliver it
+		return errors.Join(err, d.Nack(false, true))
+	}
+
+	return d.Ack(false)
+}
+
+// Replay moves up to limit messages from the dead letter queue of the given collector back to its queue.
+//
+// It moves all the messages that were in the dead letter queue when called if the limit is not positive.
+// The moved messages start over with their retry attempts.
+// It returns the number of moved messages.
+func (p *Publisher) Replay(ctx context.Context, f analysisrequest.Framework, c analysisrequest.Collector, limit int) (int, error) {
+	dlq := DeadLetterQueueName(f, c)
+	moved := 0
+	for limit <= 0 || moved < limit {
+		if err := ctx.Err(); err != nil {
+			return moved, err
+		}
+		d, ok, err := p.ch.Get(dlq, false)
+		if err != nil {
+			return moved, fmt.Errorf("couldn't get from dead letter queue %q: %w", dlq, err)
+		}
+		if !ok {
+			break
+		}
+		msg := publishingFromDelivery(d)
+		delete(msg.Headers, HeaderAttempt)
+		if err := p.publish(ctx, "", QueueName(f, c), msg); err != nil {
+			return moved, errors.Join(err, d.Nack(false, true))
+		}
+		if err := d.Ack(false); err != nil {
+			return moved, err
+		}
+		moved++
+		if limit <= 0 {
+			// Do not replay the messages dead-lettered again in the meantime
+			limit = moved + int(d.MessageCount)
+		}
+	}
+
+	return moved, nil
+}
+
+func publishingFromDelivery(d amqp.Delivery) amqp.Publishing {
+	headers := make(amqp.Table, len(d.Headers)+1)
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    d.DeliveryMode,
+		Priority:        d.Priority,
+		CorrelationId:   d.CorrelationId,
+		ReplyTo:         d.ReplyTo,
+		Expiration:      d.Expiration,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		UserId:          d.UserId,
+		AppId:           d.AppId,
+		Body:            d.Body,
+	}
+}
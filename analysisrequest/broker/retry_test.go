@@ -0,0 +1,156 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/pypi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		input error
+		want  bool
+	}{
+		{nil, false},
+		{analysisrequest.ErrGivenVersionNotFoundOnNPM, false},
+		{analysisrequest.ErrGivenSha256DoesNotMatchOnPyPi, false},
+		{errors.New("NPM package name is empty"), false},
+		{analysisrequest.ErrMalfunctioningNPMRegistryClient, true},
+		{errors.Join(analysisrequest.ErrMalfunctioningPyPiRegistryClient, errors.New("timeout")), true},
+		{&npm.ServiceError{StatusCode: 503, Message: "unavailable"}, true},
+		{fmt.Errorf("wrapped: %w", &pypi.ServiceError{StatusCode: 500, Message: "internal"}), true},
+		{&npm.ServiceError{StatusCode: 404, Message: "not found"}, false},
+		{TransientError{errors.New("boom")}, true},
+		{PermanentError{analysisrequest.ErrMalfunctioningNPMRegistryClient}, false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, IsTransient(tc.input), fmt.Sprintf("%v", tc.input))
+	}
+}
+
+func setupWithRetry(t *testing.T, retry *RetryPolicy) (*MockBroker, *Publisher) {
+	t.Helper()
+
+	b := NewMockBroker()
+	top := NewTopology(analysisrequest.None)
+	top.Retry = retry
+	require.Nil(t, top.Declare(b.Channel()))
+	p, err := NewPublisher(b.Channel(), PublisherConfig{})
+	require.Nil(t, err)
+
+	return b, p
+}
+
+// cancelWhen cancels the context as soon as the condition is met (or after a while).
+func cancelWhen(cancel context.CancelFunc, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+}
+
+func TestConsumeRetries(t *testing.T) {
+	retry := &RetryPolicy{Delays: []time.Duration{5 * time.Millisecond, 10 * time.Millisecond}}
+	b, p := setupWithRetry(t, retry)
+	require.Nil(t, p.Publish(context.Background(), analysisrequest.NewNOP("1", 0, false)))
+
+	c, err := NewConsumer(b.Channel(), newTestBuilder(t, nil), ConsumerConfig{
+		Framework: analysisrequest.None,
+		Collector: analysisrequest.NoCollector,
+		Retry:     retry,
+	})
+	require.Nil(t, err)
+
+	dlq := DeadLetterQueueName(analysisrequest.None, analysisrequest.NoCollector)
+	ctx, cancel := context.WithCancel(context.Background())
+	go cancelWhen(cancel, func() bool { return b.Len(dlq) == 1 })
+
+	var calls atomic.Int32
+	err = c.Consume(ctx, func(_ context.Context, _ analysisrequest.AnalysisRequest) error {
+		calls.Add(1)
+
+		return TransientError{errors.New("try again")}
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// First attempt plus the retries
+	assert.Equal(t, int32(3), calls.Load())
+	dead := b.Messages(dlq)
+	require.Len(t, dead, 1)
+	assert.Equal(t, 2, Attempt(dead[0]))
+	assert.Equal(t, 0, b.Len(QueueName(analysisrequest.None, analysisrequest.NoCollector)))
+}
+
+func TestConsumePermanentErrorIsDeadLettered(t *testing.T) {
+	retry := &RetryPolicy{Delays: []time.Duration{time.Millisecond}}
+	b, p := setupWithRetry(t, retry)
+	require.Nil(t, p.Publish(context.Background(), analysisrequest.NewNOP("1", 0, false)))
+
+	c, err := NewConsumer(b.Channel(), newTestBuilder(t, nil), ConsumerConfig{
+		Framework: analysisrequest.None,
+		Collector: analysisrequest.NoCollector,
+		Retry:     retry,
+	})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = c.Consume(ctx, func(_ context.Context, _ analysisrequest.AnalysisRequest) error {
+		cancel()
+
+		return analysisrequest.ErrGivenVersionNotFoundOnNPM
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	dead := b.Messages(DeadLetterQueueName(analysisrequest.None, analysisrequest.NoCollector))
+	require.Len(t, dead, 1)
+	assert.Equal(t, 0, Attempt(dead[0]))
+	assert.Equal(t, 0, b.Len(RetryQueueName(analysisrequest.None, analysisrequest.NoCollector, 1)))
+}
+
+func TestReplay(t *testing.T) {
+	retry := &RetryPolicy{Delays: []time.Duration{time.Minute}}
+	b, p := setupWithRetry(t, retry)
+	queue := QueueName(analysisrequest.None, analysisrequest.NoCollector)
+	dlq := DeadLetterQueueName(analysisrequest.None, analysisrequest.NoCollector)
+
+	// Dead-letter some messages
+	ch := b.Channel()
+	for _, id := range []string{"1", "2", "3"} {
+		a := analysisrequest.NewNOP(id, 0, false)
+		msg, err := a.Publishing()
+		require.Nil(t, err)
+		msg.Headers = map[string]interface{}{HeaderAttempt: int64(1)}
+		require.Nil(t, ch.PublishWithContext(context.Background(), "", dlq, false, false, *msg))
+	}
+	require.Equal(t, 3, b.Len(dlq))
+
+	moved, err := p.Replay(context.Background(), analysisrequest.None, analysisrequest.NoCollector, 2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, moved)
+	assert.Equal(t, 1, b.Len(dlq))
+	assert.Equal(t, 2, b.Len(queue))
+	for _, m := range b.Messages(queue) {
+		assert.Equal(t, 0, Attempt(m))
+	}
+
+	moved, err = p.Replay(context.Background(), analysisrequest.None, analysisrequest.NoCollector, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 1, moved)
+	assert.Equal(t, 0, b.Len(dlq))
+	assert.Equal(t, 3, b.Len(queue))
+
+	moved, err = p.Replay(context.Background(), analysisrequest.None, analysisrequest.NoCollector, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 0, moved)
+}
@@ -0,0 +1,148 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/listendev/pkg/analysisrequest"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	exchangePrefix     = "analysisrequest"
+	defaultMaxPriority = uint8(10)
+)
+
+var errTopologyMissingFramework = errors.New("missing framework")
+
+// Channel is the subset of the *amqp.Channel methods the runtime needs.
+//
+// It lets us run the publishers and the consumers against the MockBroker.
+type Channel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	ConsumeWithContext(ctx context.Context, queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Close() error
+}
+
+// ExchangeName returns the name of the topic exchange of the given framework.
+func ExchangeName(f analysisrequest.Framework) string {
+	return exchangePrefix + "." + string(f)
+}
+
+// QueueName returns the name of the queue of the given collector of the given framework.
+func QueueName(f analysisrequest.Framework, c analysisrequest.Collector) string {
+	return string(f) + "." + string(c)
+}
+
+// RoutingKey returns the routing key of the analysis requests with the given type.
+//
+// The format is <collector>[.<collector action>][.<ecosystem>[.<ecosystem action>]].
+func RoutingKey(t analysisrequest.Type) string {
+	c := t.Components()
+	parts := []string{string(c.Collector)}
+	if c.HasCollectorAction() {
+		parts = append(parts, c.CollectorAction)
+	}
+	if c.HasEcosystem() {
+		parts = append(parts, c.Ecosystem.Case())
+		if c.HasEcosystemAction() {
+			parts = append(parts, c.EcosystemAction)
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// BindingKey returns the binding key matching all the routing keys of the given collector.
+func BindingKey(c analysisrequest.Collector) string {
+	return string(c) + ".#"
+}
+
+// Topology describes the exchange and the queues of a framework.
+type Topology struct {
+	Framework  analysisrequest.Framework
+	Collectors []analysisrequest.Collector
+	// MaxPriority is the maximum priority of the queues (defaults to 10)
+	MaxPriority uint8
+	// Retry configures the dead letter queue and the retry queues of every collector queue (none when nil)
+	Retry *RetryPolicy
+}
+
+// NewTopology returns the topology of the given framework.
+//
+// It contains a queue for every collector the analysis request types of the framework refer to.
+func NewTopology(f analysisrequest.Framework) Topology {
+	seen := map[analysisrequest.Collector]bool{}
+	collectors := []analysisrequest.Collector{}
+	for _, t := range analysisrequest.Types() {
+		c := t.Components()
+		if c.Framework == f && !seen[c.Collector] {
+			seen[c.Collector] = true
+			collectors = append(collectors, c.Collector)
+		}
+	}
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i] < collectors[j]
+	})
+
+	return Topology{
+		Framework:   f,
+		Collectors:  collectors,
+		MaxPriority: defaultMaxPriority,
+	}
+}
+
+// QueueArgs returns the arguments of the queue of the given collector.
+func (t Topology) QueueArgs(c analysisrequest.Collector) amqp.Table {
+	maxPrio := t.MaxPriority
+	if maxPrio == 0 {
+		maxPrio = defaultMaxPriority
+	}
+
+	ret := amqp.Table{
+		"x-max-priority": int32(maxPrio),
+	}
+	if t.Retry != nil {
+		ret["x-dead-letter-exchange"] = ""
+		ret["x-dead-letter-routing-key"] = DeadLetterQueueName(t.Framework, c)
+	}
+
+	return ret
+}
+
+// Declare declares the exchange and the queues of the topology, binding them together.
+func (t Topology) Declare(ch Channel) error {
+	if len(t.Framework) == 0 {
+		return errTopologyMissingFramework
+	}
+	exchange := ExchangeName(t.Framework)
+	if err := ch.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("couldn't declare exchange %q: %w", exchange, err)
+	}
+	for _, c := range t.Collectors {
+		if t.Retry != nil {
+			if err := t.Retry.declare(ch, t.Framework, c); err != nil {
+				return err
+			}
+		}
+		q, err := ch.QueueDeclare(QueueName(t.Framework, c), true, false, false, false, t.QueueArgs(c))
+		if err != nil {
+			return fmt.Errorf("couldn't declare queue for collector %q: %w", c, err)
+		}
+		if err := ch.QueueBind(q.Name, BindingKey(c), exchange, false, nil); err != nil {
+			return fmt.Errorf("couldn't bind queue %q to exchange %q: %w", q.Name, exchange, err)
+		}
+	}
+
+	return nil
+}
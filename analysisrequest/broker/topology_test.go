@@ -0,0 +1,90 @@
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutingKey(t *testing.T) {
+	cases := []struct {
+		input analysisrequest.Type
+		want  string
+	}{
+		{analysisrequest.Nop, "nop"},
+		{analysisrequest.NPMInstallWhileDynamicInstrumentation, "dynamic.npm.install"},
+		{analysisrequest.NPMInstallWhileDynamicInstrumentationAIEnriched, "ai.context.npm.install"},
+		{analysisrequest.NPMTyposquat, "typosquat.npm"},
+		{analysisrequest.NPMStaticAnalysisShadyLinks, "static.shady_links.npm"},
+		{analysisrequest.PypiMetadataMaintainersEmailCheck, "metadata.email_check.pypi"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, RoutingKey(tc.input), tc.input.String())
+	}
+}
+
+func TestNewTopology(t *testing.T) {
+	top := NewTopology(analysisrequest.Hoarding)
+	assert.Equal(t, analysisrequest.Hoarding, top.Framework)
+	assert.Equal(t, []analysisrequest.Collector{
+		analysisrequest.AdvisoryCollector,
+		analysisrequest.AICollector,
+		analysisrequest.MetadataCollector,
+		analysisrequest.StaticAnalysisCollector,
+		analysisrequest.TyposquatCollector,
+	}, top.Collectors)
+
+	sch := NewTopology(analysisrequest.Scheduler)
+	assert.Equal(t, []analysisrequest.Collector{analysisrequest.DynamicInstrumentationCollector}, sch.Collectors)
+}
+
+func TestTopologyRouting(t *testing.T) {
+	b := NewMockBroker()
+	ch := b.Channel()
+	require.Nil(t, NewTopology(analysisrequest.Hoarding).Declare(ch))
+	require.Nil(t, NewTopology(analysisrequest.Scheduler).Declare(ch))
+
+	for _, typ := range analysisrequest.Types() {
+		c := typ.Components()
+		if c.Framework == analysisrequest.None {
+			continue
+		}
+		require.Nil(t, ch.PublishWithContext(context.Background(), ExchangeName(c.Framework), RoutingKey(typ), false, false, amqpPublishing(typ)))
+	}
+
+	assert.Equal(t, 1, b.Len(QueueName(analysisrequest.Scheduler, analysisrequest.DynamicInstrumentationCollector)))
+	assert.Equal(t, 1, b.Len(QueueName(analysisrequest.Hoarding, analysisrequest.AdvisoryCollector)))
+	assert.Equal(t, 1, b.Len(QueueName(analysisrequest.Hoarding, analysisrequest.AICollector)))
+	assert.Equal(t, 2, b.Len(QueueName(analysisrequest.Hoarding, analysisrequest.TyposquatCollector)))
+	assert.Equal(t, 5, b.Len(QueueName(analysisrequest.Hoarding, analysisrequest.MetadataCollector)))
+	assert.Equal(t, 12, b.Len(QueueName(analysisrequest.Hoarding, analysisrequest.StaticAnalysisCollector)))
+}
+
+func TestTopologyWithoutFramework(t *testing.T) {
+	assert.Error(t, Topology{}.Declare(NewMockBroker().Channel()))
+}
+
+func TestMockTopicMatch(t *testing.T) {
+	cases := []struct {
+		binding string
+		key     string
+		want    bool
+	}{
+		{"static.#", "static", true},
+		{"static.#", "static.shady_links.npm", true},
+		{"static.*", "static.npm", true},
+		{"static.*", "static.shady_links.npm", false},
+		{"*.npm", "typosquat.npm", true},
+		{"#.npm", "static.shady_links.npm", true},
+		{"#.pypi", "static.shady_links.npm", false},
+		{"static", "static.npm", false},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, mockMatch("topic", tc.binding, tc.key), tc.binding+" vs "+tc.key)
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"path/filepath"
 	"reflect"
 
+	"github.com/listendev/pkg/ecosystem"
 	"github.com/listendev/pkg/npm"
 	"github.com/listendev/pkg/observability/tracer"
 	"github.com/listendev/pkg/pypi"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 var errBuilderInvalidAnalysisRequest = errors.New("invalid analysis request")
@@ -133,65 +135,94 @@ func (b *builder) FromJSON(body []byte) (AnalysisRequest, error) {
 		return nil, err
 	}
 
-	// TODO: adjust condition while evolving
-	switch arb.RequestType {
+	if !buildableTypes[arb.RequestType] {
+		return nil, errBuilderInvalidAnalysisRequest
+	}
+
+	switch arb.RequestType.Components().Ecosystem {
+	case ecosystem.Npm:
+		return b.getNPMAnalysisRequest(body)
+	case ecosystem.Pypi:
+		return b.getPyPiAnalysisRequest(body)
+	}
+
+	return &NOP{arb}, nil
+}
+
+// buildableTypes are the types of the analysis requests the builders build, whatever their encoding.
+//
+// TODO: adjust while evolving
+var buildableTypes = map[Type]bool{
 	// NPM
 	// TODO: uncomment when ready
-	// case NPMTestWhileDynamicInstrumentation:
-	// 	fallthrough
-	case NPMInstallWhileDynamicInstrumentationAIEnriched:
-		fallthrough
-	case NPMAdvisory:
-		fallthrough
-	case NPMTyposquat:
-		fallthrough
-	case NPMMetadataEmptyDescription:
-		fallthrough
-	case NPMMetadataMaintainersEmailCheck:
-		fallthrough
-	case NPMMetadataVersion:
-		fallthrough
-	case NPMMetadataMismatches:
-		fallthrough
-	case NPMStaticAnalysisEnvExfiltration:
-		fallthrough
-	case NPMStaticAnalysisDetachedProcessExecution:
-		fallthrough
-	case NPMStaticAnalysisEvalBase64:
-		fallthrough
-	case NPMStaticAnalysisShadyLinks:
-		fallthrough
-	case NPMStaticAnalysisInstallScript:
-		fallthrough
-	case NPMStaticNonRegistryDependency:
-		fallthrough
-	case NPMInstallWhileDynamicInstrumentation:
-		return b.getNPMAnalysisRequest(body)
+	// NPMTestWhileDynamicInstrumentation: true,
+	NPMInstallWhileDynamicInstrumentationAIEnriched: true,
+	NPMAdvisory:                               true,
+	NPMTyposquat:                              true,
+	NPMMetadataEmptyDescription:               true,
+	NPMMetadataMaintainersEmailCheck:          true,
+	NPMMetadataVersion:                        true,
+	NPMMetadataMismatches:                     true,
+	NPMStaticAnalysisEnvExfiltration:          true,
+	NPMStaticAnalysisDetachedProcessExecution: true,
+	NPMStaticAnalysisEvalBase64:               true,
+	NPMStaticAnalysisShadyLinks:               true,
+	NPMStaticAnalysisInstallScript:            true,
+	NPMStaticNonRegistryDependency:            true,
+	NPMInstallWhileDynamicInstrumentation:     true,
 
 	// PyPi
-	case PypiTyposquat:
-		fallthrough
-	case PypiMetadataMaintainersEmailCheck:
-		fallthrough
-	case PypiStaticAnalysisEnvExfiltration:
-		fallthrough
-	case PypiStaticAnalysisDetachedProcessExecution:
-		fallthrough
-	case PypiStaticAnalysisEvalBase64:
-		fallthrough
-	case PypiStaticAnalysisCodeExecutionAtSetup:
-		fallthrough
-	case PypiStaticAnalysisShadyLinks:
-		fallthrough
-	case PypiStaticNonRegistryDependency:
-		return b.getPyPiAnalysisRequest(body)
+	PypiTyposquat:                              true,
+	PypiMetadataMaintainersEmailCheck:          true,
+	PypiStaticAnalysisEnvExfiltration:          true,
+	PypiStaticAnalysisDetachedProcessExecution: true,
+	PypiStaticAnalysisEvalBase64:               true,
+	PypiStaticAnalysisCodeExecutionAtSetup:     true,
+	PypiStaticAnalysisShadyLinks:               true,
+	PypiStaticNonRegistryDependency:            true,
 
 	// NOP
-	case Nop:
-		return &NOP{arb}, nil
+	Nop: true,
+}
+
+func (b *builder) FromDelivery(d amqp.Delivery) (AnalysisRequest, error) {
+	return b.Decode(d.ContentType, d.Body)
+}
+
+// Decode builds the analysis request from the given body using the encoding registered for the given content type.
+func (b *builder) Decode(contentType string, body []byte) (AnalysisRequest, error) {
+	e, err := GetEncoding(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if e == EncodingJSON {
+		return b.FromJSON(body)
+	}
+
+	t := tracer.FromContext(b.ctx)
+	_, span := t.Start(b.ctx, "analysysrequest.Builder.Decode")
+	defer span.End()
+
+	ar, err := e.Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	if !buildableTypes[ar.Type()] {
+		return nil, errBuilderInvalidAnalysisRequest
+	}
+
+	switch arx := ar.(type) {
+	case *NPM:
+		if err := arx.fillMissingData(b.ctx, b.npmRegistryClient); err != nil {
+			return nil, err
+		}
+	case *PyPi:
+		if err := arx.fillMissingData(b.ctx, b.pypiRegistryClient); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil, errBuilderInvalidAnalysisRequest
+	return ar, nil
 }
 
 type noOpBuilder struct{}
@@ -204,3 +235,7 @@ func NewNoOpBuilder() *noOpBuilder {
 func (b *noOpBuilder) FromJSON(_ []byte) (AnalysisRequest, error) {
 	return &NOP{}, nil
 }
+
+func (b *noOpBuilder) FromDelivery(_ amqp.Delivery) (AnalysisRequest, error) {
+	return &NOP{}, nil
+}
@@ -0,0 +1,78 @@
+package analysisrequest
+
+import (
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	cborEncMode, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	cborDecMode, _ = cbor.DecOptions{}.DecMode()
+)
+
+// cborRequest is the CBOR representation of the analysis requests.
+//
+// It has the same keys of the JSON representation.
+type cborRequest struct {
+	Type       string    `cbor:"type"`
+	Snowflake  string    `cbor:"snowflake_id"`
+	Priority   uint8     `cbor:"priority,omitempty"`
+	Force      bool      `cbor:"force"`
+	Meta       *Metadata `cbor:"metadata,omitempty"`
+	Name       string    `cbor:"name,omitempty"`
+	Version    string    `cbor:"version,omitempty"`
+	Shasum     string    `cbor:"shasum,omitempty"`
+	Sha256     string    `cbor:"sha256,omitempty"`
+	Blake2b256 string    `cbor:"blake2b_256,omitempty"`
+}
+
+type cborEncoding struct{}
+
+func (cborEncoding) ContentType() string {
+	return ContentTypeCBOR
+}
+
+func (cborEncoding) Encode(a AnalysisRequest) ([]byte, error) {
+	w, err := toWire(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return cborEncMode.Marshal(cborRequest{
+		Type:       w.RequestType.String(),
+		Snowflake:  w.Snowflake,
+		Priority:   w.Priority,
+		Force:      w.Force,
+		Meta:       w.Meta,
+		Name:       w.Name,
+		Version:    w.Version,
+		Shasum:     w.Shasum,
+		Sha256:     w.Sha256,
+		Blake2b256: w.Blake2b256,
+	})
+}
+
+func (cborEncoding) Decode(data []byte) (AnalysisRequest, error) {
+	var r cborRequest
+	if err := cborDecMode.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	t, err := ToType(r.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return wire{
+		base: base{
+			RequestType: t,
+			Snowflake:   r.Snowflake,
+			Priority:    r.Priority,
+			Force:       r.Force,
+			Meta:        r.Meta,
+		},
+		Name:       r.Name,
+		Version:    r.Version,
+		Shasum:     r.Shasum,
+		Sha256:     r.Sha256,
+		Blake2b256: r.Blake2b256,
+	}.toAnalysisRequest()
+}
@@ -0,0 +1,80 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+)
+
+// DefaultWindow is the default time window within which the deduplicator suppresses duplicates.
+const DefaultWindow = time.Hour
+
+// ErrDuplicate tells an analysis request got suppressed because it duplicates a recent one.
+var ErrDuplicate = errors.New("duplicate analysis request")
+
+// Enqueuer enqueues analysis requests (eg., the broker publisher).
+type Enqueuer interface {
+	Publish(ctx context.Context, a analysisrequest.AnalysisRequest) error
+}
+
+var _ Enqueuer = (*Deduplicator)(nil)
+
+// Deduplicator wraps an Enqueuer suppressing the analysis requests
+// having the same idempotency key (see analysisrequest.IdempotencyKey) of one enqueued within the window.
+//
+// It always enqueues the analysis requests that must be processed (see MustProcess),
+// restarting their window.
+type Deduplicator struct {
+	next   Enqueuer
+	store  Store
+	window time.Duration
+}
+
+// New creates a deduplicator enqueuing through the given Enqueuer.
+//
+// The window defaults to DefaultWindow when not positive.
+func New(next Enqueuer, store Store, window time.Duration) *Deduplicator {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	return &Deduplicator{
+		next:   next,
+		store:  store,
+		window: window,
+	}
+}
+
+// Publish enqueues the given analysis request unless it is a duplicate, returning ErrDuplicate in such case.
+//
+// It forgets the analysis requests it fails to enqueue, so that they can be enqueued again straight away.
+func (d *Deduplicator) Publish(ctx context.Context, a analysisrequest.AnalysisRequest) error {
+	key := analysisrequest.IdempotencyKey(a)
+	if a.MustProcess() {
+		if err := d.store.Put(ctx, key, d.window); err != nil {
+			return fmt.Errorf("couldn't record the idempotency key: %w", err)
+		}
+	} else {
+		claimed, err := d.store.Claim(ctx, key, d.window)
+		if err != nil {
+			return fmt.Errorf("couldn't claim the idempotency key: %w", err)
+		}
+		if !claimed {
+			return fmt.Errorf("%w: %s", ErrDuplicate, a.String())
+		}
+	}
+
+	if err := d.next.Publish(ctx, a); err != nil {
+		// Do not let the failure suppress the next attempts
+		if releaseErr := d.store.Release(context.WithoutCancel(ctx), key); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+
+		return err
+	}
+
+	return nil
+}
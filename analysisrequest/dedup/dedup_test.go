@@ -0,0 +1,84 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is an Enqueuer recording the analysis requests it enqueues.
+type recorder struct {
+	ids []string
+	err error
+}
+
+func (r *recorder) Publish(_ context.Context, a analysisrequest.AnalysisRequest) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.ids = append(r.ids, a.ID())
+
+	return nil
+}
+
+func newRequest(t *testing.T, id string, force bool) analysisrequest.AnalysisRequest {
+	t.Helper()
+
+	a, err := analysisrequest.NewNPM(analysisrequest.NPMTyposquat, id, 0, force, "chalk", "5.1.2", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	require.Nil(t, err)
+
+	return a
+}
+
+func TestDeduplicator(t *testing.T) {
+	ctx := context.Background()
+
+	for _, name := range []string{"memory", "redis"} {
+		t.Run(name, func(t *testing.T) {
+			c := newClock()
+			r := &recorder{}
+			d := New(r, stores(c)[name], time.Hour)
+
+			require.Nil(t, d.Publish(ctx, newRequest(t, "1", false)))
+			err := d.Publish(ctx, newRequest(t, "2", false))
+			assert.ErrorIs(t, err, ErrDuplicate)
+
+			// Other packages are not duplicates
+			other, err := analysisrequest.NewNPM(analysisrequest.NPMTyposquat, "3", 0, false, "chalk", "5.2.0", "249623b7d66869c673699fb66d65723e54dfcfb3")
+			require.Nil(t, err)
+			require.Nil(t, d.Publish(ctx, other))
+
+			// Forced analysis requests are never duplicates
+			c.Advance(30 * time.Minute)
+			require.Nil(t, d.Publish(ctx, newRequest(t, "4", true)))
+			require.Nil(t, d.Publish(ctx, newRequest(t, "5", true)))
+
+			// They restarted the window
+			c.Advance(59 * time.Minute)
+			assert.ErrorIs(t, d.Publish(ctx, newRequest(t, "6", false)), ErrDuplicate)
+			c.Advance(time.Minute)
+			require.Nil(t, d.Publish(ctx, newRequest(t, "7", false)))
+
+			assert.Equal(t, []string{"1", "3", "4", "5", "7"}, r.ids)
+		})
+	}
+}
+
+func TestDeduplicatorReleasesFailures(t *testing.T) {
+	ctx := context.Background()
+	r := &recorder{err: errors.New("broker unavailable")}
+	d := New(r, NewMemoryStore(nil), 0)
+	assert.Equal(t, DefaultWindow, d.window)
+
+	assert.ErrorIs(t, d.Publish(ctx, newRequest(t, "1", false)), r.err)
+
+	// The failed attempt doesn't suppress the next one
+	r.err = nil
+	require.Nil(t, d.Publish(ctx, newRequest(t, "2", false)))
+	assert.Equal(t, []string{"2"}, r.ids)
+}
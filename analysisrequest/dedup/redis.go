@@ -0,0 +1,57 @@
+package dedup
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultRedisPrefix is the prefix of the keys the Redis store writes.
+const DefaultRedisPrefix = "analysisrequest:dedup:"
+
+// RedisClient is the subset of Redis commands the Redis store needs.
+//
+// Adapt your Redis client of choice to it.
+type RedisClient interface {
+	// SetNX sets the given key with the given expiration, only if it does not exist (SET key value NX PX ttl)
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Set sets the given key with the given expiration (SET key value PX ttl)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Del deletes the given key (DEL key)
+	Del(ctx context.Context, key string) error
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// RedisStore is a Store keeping the keys in Redis, so that many processes can share them.
+//
+// It lets Redis expire the keys.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a store writing the keys through the given client.
+//
+// It prefixes the keys with the given prefix, it defaults to DefaultRedisPrefix when empty.
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = DefaultRedisPrefix
+	}
+
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Claim(ctx context.Context, key string, window time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key, "1", window)
+}
+
+func (s *RedisStore) Put(ctx context.Context, key string, window time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, "1", window)
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key)
+}
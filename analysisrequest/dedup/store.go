@@ -0,0 +1,101 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store records the idempotency keys of the analysis requests for a window of time.
+type Store interface {
+	// Claim records the given key for the given window, unless it is already recorded.
+	//
+	// It returns false when the key is already recorded.
+	Claim(ctx context.Context, key string, window time.Duration) (bool, error)
+	// Put records the given key for the given window, whether it is already recorded or not.
+	Put(ctx context.Context, key string, window time.Duration) error
+	// Release forgets the given key.
+	Release(ctx context.Context, key string) error
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store keeping the keys in memory.
+//
+// It forgets the expired keys lazily.
+type MemoryStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	// sweepAt is the number of keys triggering the removal of the expired ones
+	sweepAt int
+	now     func() time.Time
+}
+
+const minSweepAt = 1024
+
+// NewMemoryStore creates an empty in-memory store.
+//
+// The given function returns the current time, it defaults to time.Now when nil.
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryStore{
+		expires: map[string]time.Time{},
+		sweepAt: minSweepAt,
+		now:     now,
+	}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if expiry, ok := s.expires[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.put(now, key, window)
+
+	return true, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, key string, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(s.now(), key, window)
+
+	return nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.expires, key)
+
+	return nil
+}
+
+// Len returns the number of recorded keys, including the expired ones not forgotten yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.expires)
+}
+
+func (s *MemoryStore) put(now time.Time, key string, window time.Duration) {
+	s.expires[key] = now.Add(window)
+	if len(s.expires) < s.sweepAt {
+		return
+	}
+	for k, expiry := range s.expires {
+		if !now.Before(expiry) {
+			delete(s.expires, k)
+		}
+	}
+	s.sweepAt = max(minSweepAt, 2*len(s.expires))
+}
@@ -0,0 +1,200 @@
+package dedup
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a manually advanced clock.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// fakeRedis is a local fake of a Redis server, expiring keys like Redis does.
+type fakeRedis struct {
+	mu      sync.Mutex
+	clock   *clock
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeRedis(c *clock) *fakeRedis {
+	return &fakeRedis{
+		clock:   c,
+		values:  map[string]string{},
+		expires: map[string]time.Time{},
+	}
+}
+
+func (r *fakeRedis) exists(key string) bool {
+	if _, ok := r.values[key]; !ok {
+		return false
+	}
+	if expiry, ok := r.expires[key]; ok && !r.clock.Now().Before(expiry) {
+		delete(r.values, key)
+		delete(r.expires, key)
+
+		return false
+	}
+
+	return true
+}
+
+func (r *fakeRedis) set(key, value string, ttl time.Duration) {
+	r.values[key] = value
+	delete(r.expires, key)
+	if ttl > 0 {
+		r.expires[key] = r.clock.Now().Add(ttl)
+	}
+}
+
+func (r *fakeRedis) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exists(key) {
+		return false, nil
+	}
+	r.set(key, value, ttl)
+
+	return true, nil
+}
+
+func (r *fakeRedis) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.set(key, value, ttl)
+
+	return nil
+}
+
+func (r *fakeRedis) Del(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.values, key)
+	delete(r.expires, key)
+
+	return nil
+}
+
+func (r *fakeRedis) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := []string{}
+	for k := range r.values {
+		if r.exists(k) {
+			ret = append(ret, k)
+		}
+	}
+
+	return ret
+}
+
+func stores(c *clock) map[string]Store {
+	return map[string]Store{
+		"memory": NewMemoryStore(c.Now),
+		"redis":  NewRedisStore(newFakeRedis(c), ""),
+	}
+}
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+
+	for _, name := range []string{"memory", "redis"} {
+		t.Run(name, func(t *testing.T) {
+			c := newClock()
+			s := stores(c)[name]
+
+			claimed, err := s.Claim(ctx, "k", time.Minute)
+			require.Nil(t, err)
+			assert.True(t, claimed)
+			claimed, err = s.Claim(ctx, "k", time.Minute)
+			require.Nil(t, err)
+			assert.False(t, claimed)
+
+			// Other keys are independent
+			claimed, err = s.Claim(ctx, "other", time.Minute)
+			require.Nil(t, err)
+			assert.True(t, claimed)
+
+			// Keys expire at the end of their window
+			c.Advance(time.Minute)
+			claimed, err = s.Claim(ctx, "k", time.Minute)
+			require.Nil(t, err)
+			assert.True(t, claimed)
+
+			// Released keys can be claimed again
+			require.Nil(t, s.Release(ctx, "k"))
+			claimed, err = s.Claim(ctx, "k", time.Minute)
+			require.Nil(t, err)
+			assert.True(t, claimed)
+
+			// Putting keys restarts their window
+			c.Advance(59 * time.Second)
+			require.Nil(t, s.Put(ctx, "k", time.Minute))
+			c.Advance(59 * time.Second)
+			claimed, err = s.Claim(ctx, "k", time.Minute)
+			require.Nil(t, err)
+			assert.False(t, claimed)
+
+			// Releasing unknown keys is fine
+			assert.Nil(t, s.Release(ctx, "unknown"))
+		})
+	}
+}
+
+func TestRedisStorePrefix(t *testing.T) {
+	c := newClock()
+	r := newFakeRedis(c)
+
+	_, err := NewRedisStore(r, "").Claim(context.Background(), "k", time.Minute)
+	require.Nil(t, err)
+	_, err = NewRedisStore(r, "custom:").Claim(context.Background(), "k", time.Minute)
+	require.Nil(t, err)
+
+	assert.ElementsMatch(t, []string{DefaultRedisPrefix + "k", "custom:k"}, r.Keys())
+}
+
+func TestMemoryStoreForgetsExpiredKeys(t *testing.T) {
+	c := newClock()
+	s := NewMemoryStore(c.Now)
+
+	for i := range minSweepAt - 1 {
+		_, err := s.Claim(context.Background(), strconv.Itoa(i), time.Minute)
+		require.Nil(t, err)
+	}
+	assert.Equal(t, minSweepAt-1, s.Len())
+
+	c.Advance(time.Minute)
+	_, err := s.Claim(context.Background(), "last", time.Minute)
+	require.Nil(t, err)
+	assert.Equal(t, 1, s.Len())
+}
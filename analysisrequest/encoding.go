@@ -0,0 +1,167 @@
+package analysisrequest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"sync"
+
+	"github.com/listendev/pkg/ecosystem"
+)
+
+// Those are the content types of the built-in encodings.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeCBOR     = "application/cbor"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+var (
+	ErrUnknownContentType    = errors.New("unknown content type")
+	errEncodingUnknownType   = errors.New("unknown analysis request type")
+	errEncodingUnsupportedAR = errors.New("unsupported analysis request")
+)
+
+// Encoding marshals analysis requests to and unmarshals them from the wire.
+type Encoding interface {
+	// ContentType returns the AMQP content type of the encoding
+	ContentType() string
+	// Encode marshals the analysis request
+	Encode(a AnalysisRequest) ([]byte, error)
+	// Decode unmarshals and validates an analysis request without filling its missing data
+	Decode(data []byte) (AnalysisRequest, error)
+}
+
+var (
+	// EncodingJSON is the default encoding.
+	EncodingJSON Encoding = jsonEncoding{}
+	// EncodingCBOR encodes the analysis requests as CBOR maps having the same keys of the JSON encoding.
+	EncodingCBOR Encoding = cborEncoding{}
+	// EncodingProtobuf encodes the analysis requests as protobuf messages carrying the numeric type ID.
+	EncodingProtobuf Encoding = protobufEncoding{}
+)
+
+var (
+	encodingsMu sync.RWMutex
+	encodings   = map[string]Encoding{
+		ContentTypeJSON:     EncodingJSON,
+		ContentTypeCBOR:     EncodingCBOR,
+		ContentTypeProtobuf: EncodingProtobuf,
+	}
+)
+
+// RegisterEncoding makes the given encoding available for its content type, replacing any existing one.
+func RegisterEncoding(e Encoding) {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+
+	encodings[e.ContentType()] = e
+}
+
+// GetEncoding returns the encoding registered for the given content type.
+//
+// It ignores the content type parameters (eg., charset) and defaults to JSON when the content type is empty.
+func GetEncoding(contentType string) (Encoding, error) {
+	if contentType == "" {
+		return EncodingJSON, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrUnknownContentType, contentType, err)
+	}
+
+	encodingsMu.RLock()
+	defer encodingsMu.RUnlock()
+
+	e, ok := encodings[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownContentType, contentType)
+	}
+
+	return e, nil
+}
+
+// wire is the encoding agnostic representation of the analysis requests.
+type wire struct {
+	base
+	Name       string
+	Version    string
+	Shasum     string
+	Sha256     string
+	Blake2b256 string
+}
+
+func toWire(a AnalysisRequest) (wire, error) {
+	switch v := a.(type) {
+	case *NOP:
+		return wire{base: v.base}, nil
+	case *NPM:
+		return wire{base: v.base, Name: v.Name, Version: v.Version, Shasum: v.Shasum}, nil
+	case *PyPi:
+		return wire{base: v.base, Name: v.Name, Version: v.Version, Sha256: v.Sha256, Blake2b256: v.Blake2b256}, nil
+	}
+
+	return wire{}, fmt.Errorf("%w: %T", errEncodingUnsupportedAR, a)
+}
+
+// toAnalysisRequest returns the validated analysis request the wire representation refers to.
+func (w wire) toAnalysisRequest() (AnalysisRequest, error) {
+	if _, ok := typeURNs[w.RequestType]; !ok {
+		return nil, fmt.Errorf("%w: %d", errEncodingUnknownType, w.RequestType)
+	}
+
+	var ret AnalysisRequest
+	c := w.RequestType.Components()
+	switch {
+	case w.RequestType == Nop:
+		ret = &NOP{base: w.base}
+	case c.Ecosystem == ecosystem.Npm:
+		ret = &NPM{base: w.base, npmPackage: npmPackage{Name: w.Name, Version: w.Version, Shasum: w.Shasum}}
+	case c.Ecosystem == ecosystem.Pypi:
+		ret = &PyPi{base: w.base, pypiPackage: pypiPackage{Name: w.Name, Version: w.Version, Sha256: w.Sha256, Blake2b256: w.Blake2b256}}
+	default:
+		return nil, fmt.Errorf("%w: %s", errEncodingUnsupportedAR, w.RequestType.String())
+	}
+
+	if err := ret.Validate(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+type jsonEncoding struct{}
+
+func (jsonEncoding) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (jsonEncoding) Encode(a AnalysisRequest) ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (jsonEncoding) Decode(data []byte) (AnalysisRequest, error) {
+	var arb base
+	if err := json.Unmarshal(data, &arb); err != nil {
+		return nil, err
+	}
+	w := wire{base: arb}
+	if arb.RequestType != Nop {
+		var pkg struct {
+			npmPackage
+			Sha256     string `json:"sha256,omitempty"`
+			Blake2b256 string `json:"blake2b_256,omitempty"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, err
+		}
+		w.Name = pkg.Name
+		w.Version = pkg.Version
+		w.Shasum = pkg.Shasum
+		w.Sha256 = pkg.Sha256
+		w.Blake2b256 = pkg.Blake2b256
+	}
+
+	return w.toAnalysisRequest()
+}
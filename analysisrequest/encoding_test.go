@@ -0,0 +1,284 @@
+package analysisrequest
+
+import (
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func newEncodingTestRequest(t *testing.T, typ Type) AnalysisRequest {
+	t.Helper()
+
+	var a AnalysisRequest
+	var err error
+	switch typ.Components().Ecosystem {
+	case ecosystem.Npm:
+		a, err = NewNPM(typ, "1524854487523524608", 5, true, "chalk", "5.1.2", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	case ecosystem.Pypi:
+		a, err = NewPyPi(typ, "1524854487523524608", 5, true, "boto3", "1.33.8", "0b9d9ab5f5bc8d0bd1f2bd8e3e1ba8cc2a48e0bbd5e5e1c3c4a5e2b4bd34d8d2")
+	default:
+		a = NewNOP("1524854487523524608", 5, true)
+	}
+	require.Nil(t, err)
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	deadline := created.Add(time.Hour)
+	a.SetMetadata(Metadata{
+		ParentID:    "1524854487523524607",
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Origin: &Origin{
+			Lockfile:    "package-lock.json",
+			Repository:  "listendev/pkg",
+			PullRequest: 42,
+		},
+		CreatedAt: &created,
+		Deadline:  &deadline,
+	})
+
+	return a
+}
+
+func TestEncodingsRoundTrip(t *testing.T) {
+	for _, e := range []Encoding{EncodingJSON, EncodingCBOR, EncodingProtobuf} {
+		for _, typ := range Types() {
+			t.Run(e.ContentType()+"/"+typ.String(), func(t *testing.T) {
+				want := newEncodingTestRequest(t, typ)
+
+				data, err := e.Encode(want)
+				require.Nil(t, err)
+				got, err := e.Decode(data)
+				require.Nil(t, err)
+
+				assert.Equal(t, want, got)
+				assert.Equal(t, want.ResultsPath(), got.ResultsPath())
+			})
+		}
+	}
+}
+
+func TestEncodingsWithoutMetadata(t *testing.T) {
+	for _, e := range []Encoding{EncodingJSON, EncodingCBOR, EncodingProtobuf} {
+		t.Run(e.ContentType(), func(t *testing.T) {
+			want := NewNOP("1524854487523524608", 0, false)
+
+			data, err := e.Encode(want)
+			require.Nil(t, err)
+			got, err := e.Decode(data)
+			require.Nil(t, err)
+
+			assert.Equal(t, want, got)
+			assert.True(t, got.Metadata().IsZero())
+		})
+	}
+}
+
+func TestEncodingsInvalidInput(t *testing.T) {
+	for _, e := range []Encoding{EncodingJSON, EncodingCBOR, EncodingProtobuf} {
+		t.Run(e.ContentType(), func(t *testing.T) {
+			_, err := e.Decode([]byte{0xff, 0xff, 0xff})
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("protobuf unknown numeric type", func(t *testing.T) {
+		data := protowire.AppendTag(nil, pbType, protowire.VarintType)
+		data = protowire.AppendVarint(data, 999999)
+		data = pbAppendString(data, pbSnowflake, "1524854487523524608")
+
+		_, err := EncodingProtobuf.Decode(data)
+		assert.ErrorIs(t, err, errEncodingUnknownType)
+	})
+
+	t.Run("protobuf out of range", func(t *testing.T) {
+		for _, tc := range []struct {
+			num   protowire.Number
+			value uint64
+		}{
+			{pbPriority, 256},
+			{pbType, math.MaxUint32 + 1},
+		} {
+			data := pbAppendString(nil, pbSnowflake, "1524854487523524608")
+			data = protowire.AppendTag(data, tc.num, protowire.VarintType)
+			data = protowire.AppendVarint(data, tc.value)
+
+			_, err := EncodingProtobuf.Decode(data)
+			assert.ErrorIs(t, err, errProtobufOutOfRange)
+		}
+	})
+
+	t.Run("protobuf unknown fields are skipped", func(t *testing.T) {
+		want := NewNOP("1524854487523524608", 0, false)
+		data, err := EncodingProtobuf.Encode(want)
+		require.Nil(t, err)
+		data = pbAppendString(data, 99, "future")
+
+		got, err := EncodingProtobuf.Decode(data)
+		require.Nil(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("cbor npm without name", func(t *testing.T) {
+		data, err := cborEncMode.Marshal(cborRequest{Type: NPMTyposquat.String(), Snowflake: "1524854487523524608"})
+		require.Nil(t, err)
+
+		_, err = EncodingCBOR.Decode(data)
+		assert.ErrorIs(t, err, errNPMNameEmpty)
+	})
+}
+
+func TestProtobufSchema(t *testing.T) {
+	schema, err := os.ReadFile("analysisrequest.proto")
+	require.Nil(t, err)
+
+	// The schema has the field numbers of the encoding
+	fields := map[string]protowire.Number{}
+	message := ""
+	for _, line := range strings.Split(string(schema), "\n") {
+		if m := regexp.MustCompile(`^message (\w+) \{`).FindStringSubmatch(line); m != nil {
+			message = m[1]
+		}
+		if m := regexp.MustCompile(`^\s+\w+ (\w+) = (\d+);`).FindStringSubmatch(line); m != nil {
+			n, err := strconv.Atoi(m[2])
+			require.Nil(t, err)
+			fields[message+"."+m[1]] = protowire.Number(n)
+		}
+	}
+	assert.Equal(t, map[string]protowire.Number{
+		"Timestamp.seconds":            pbTimestampSeconds,
+		"Timestamp.nanos":              pbTimestampNanos,
+		"Origin.lockfile":              pbOriginLockfile,
+		"Origin.repository":            pbOriginRepository,
+		"Origin.pull_request":          pbOriginPullRequest,
+		"Metadata.parent_id":           pbMetadataParentID,
+		"Metadata.traceparent":         pbMetadataTraceParent,
+		"Metadata.origin":              pbMetadataOrigin,
+		"Metadata.created_at":          pbMetadataCreatedAt,
+		"Metadata.deadline":            pbMetadataDeadline,
+		"AnalysisRequest.type":         pbType,
+		"AnalysisRequest.snowflake_id": pbSnowflake,
+		"AnalysisRequest.priority":     pbPriority,
+		"AnalysisRequest.force":        pbForce,
+		"AnalysisRequest.name":         pbName,
+		"AnalysisRequest.version":      pbVersion,
+		"AnalysisRequest.shasum":       pbShasum,
+		"AnalysisRequest.sha256":       pbSha256,
+		"AnalysisRequest.blake2b_256":  pbBlake2b256,
+		"AnalysisRequest.metadata":     pbMetadata,
+	}, fields)
+}
+
+func TestGetEncoding(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Encoding
+		wantErr     bool
+	}{
+		{"", EncodingJSON, false},
+		{"application/json", EncodingJSON, false},
+		{"application/json; charset=utf-8", EncodingJSON, false},
+		{"application/cbor", EncodingCBOR, false},
+		{"application/x-protobuf", EncodingProtobuf, false},
+		{"text/plain", nil, true},
+		{"application/", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, err := GetEncoding(tt.contentType)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownContentType)
+
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestComposeAMQPPublishingWithEncoding(t *testing.T) {
+	a := newEncodingTestRequest(t, NPMTyposquat)
+
+	msg, err := ComposeAMQPPublishingWithEncoding(a, EncodingProtobuf)
+	require.Nil(t, err)
+	assert.Equal(t, ContentTypeProtobuf, msg.ContentType)
+	assert.Equal(t, uint8(5), msg.Priority)
+	assert.Equal(t, "1524854487523524607", msg.Headers[HeaderParentID])
+
+	d, err := ComposeAMQPDeliveryWithEncoding(a, EncodingCBOR)
+	require.Nil(t, err)
+	assert.Equal(t, ContentTypeCBOR, d.ContentType)
+	assert.Equal(t, msg.Timestamp, d.Timestamp)
+}
+
+func TestBuilderFromDelivery(t *testing.T) {
+	for _, e := range []Encoding{EncodingJSON, EncodingCBOR, EncodingProtobuf} {
+		t.Run(e.ContentType(), func(t *testing.T) {
+			arbuilder, err := NewBuilder(observability.NewNopContext())
+			require.Nil(t, err)
+			mockClient, err := npm.NewMockRegistryClient("chalk.json", "chalk_512.json")
+			require.Nil(t, err)
+			arbuilder.WithNPMRegistryClient(mockClient)
+
+			// The builder fills the missing shasum whatever the encoding
+			a, err := NewNPM(NPMTyposquat, "1524854487523524608", 5, false, "chalk", "5.1.2", "")
+			require.Nil(t, err)
+			d, err := ComposeAMQPDeliveryWithEncoding(a, e)
+			require.Nil(t, err)
+
+			got, err := arbuilder.FromDelivery(*d)
+			require.Nil(t, err)
+			require.IsType(t, &NPM{}, got)
+			assert.Equal(t, "d957f370038b75ac572471e83be4c5ca9f8e8c45", got.(*NPM).Shasum)
+			assert.Equal(t, "npm/chalk/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/typosquat.json", got.ResultsPath().Key())
+		})
+	}
+
+	t.Run("disallowed type", func(t *testing.T) {
+		arbuilder, err := NewBuilder(observability.NewNopContext())
+		require.Nil(t, err)
+
+		// The builders reject the types they do not build, whatever the encoding
+		delete(buildableTypes, NPMMetadataVersion)
+		t.Cleanup(func() {
+			buildableTypes[NPMMetadataVersion] = true
+		})
+		a, err := NewNPM(NPMMetadataVersion, "1524854487523524608", 0, false, "chalk", "5.1.2", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+		require.Nil(t, err)
+		data, err := cborEncMode.Marshal(cborRequest{Type: NPMMetadataVersion.String(), Snowflake: "1524854487523524608", Name: "chalk", Version: "5.1.2"})
+		require.Nil(t, err)
+		_, err = arbuilder.Decode(ContentTypeCBOR, data)
+		assert.ErrorIs(t, err, errBuilderInvalidAnalysisRequest)
+
+		for _, e := range []Encoding{EncodingJSON, EncodingCBOR, EncodingProtobuf} {
+			d, err := ComposeAMQPDeliveryWithEncoding(a, e)
+			require.Nil(t, err)
+
+			_, err = arbuilder.FromDelivery(*d)
+			assert.ErrorIs(t, err, errBuilderInvalidAnalysisRequest, e.ContentType())
+		}
+	})
+
+	t.Run("unknown content type", func(t *testing.T) {
+		arbuilder, err := NewBuilder(observability.NewNopContext())
+		require.Nil(t, err)
+
+		d, err := NewNOP("1524854487523524608", 0, false).Delivery()
+		require.Nil(t, err)
+		d.ContentType = "application/xml"
+
+		_, err = arbuilder.FromDelivery(*d)
+		assert.ErrorIs(t, err, ErrUnknownContentType)
+	})
+}
@@ -0,0 +1,118 @@
+package analysisrequest
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/listendev/pkg/observability/tracer"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Those are the AMQP headers carrying the lineage and correlation metadata of the analysis requests.
+const (
+	HeaderParentID          = "x-request-parent-id"
+	HeaderTraceParent       = tracer.HeaderRequestTraceParent
+	HeaderOriginLockfile    = "x-request-origin-lockfile"
+	HeaderOriginRepository  = "x-request-origin-repository"
+	HeaderOriginPullRequest = "x-request-origin-pull-request"
+	HeaderCreatedAt         = "x-request-created-at"
+	HeaderDeadline          = "x-request-deadline"
+)
+
+var (
+	errMetadataInvalidTraceParent = errors.New("invalid W3C traceparent")
+	errMetadataDeadlineBeforeTime = errors.New("deadline is before the creation time")
+)
+
+// traceParentRegex matches the W3C traceparent format (see https://www.w3.org/TR/trace-context/#traceparent-header).
+var traceParentRegex = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// Origin describes what caused an analysis request.
+type Origin struct {
+	Lockfile    string `json:"lockfile,omitempty"`
+	Repository  string `json:"repository,omitempty"`
+	PullRequest int    `json:"pull_request,omitempty"`
+}
+
+// Metadata contains the optional lineage and correlation information of an analysis request.
+//
+// It lets us trace which CI run, lockfile, or parent request caused a verdict.
+type Metadata struct {
+	// ParentID is the snowflake ID of the analysis request that caused the current one
+	ParentID string `json:"parent_id,omitempty"`
+	// TraceParent is the W3C traceparent of the trace the analysis request belongs to
+	TraceParent string     `json:"traceparent,omitempty"`
+	Origin      *Origin    `json:"origin,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+}
+
+// IsZero tells whether the metadata is empty.
+func (m Metadata) IsZero() bool {
+	return m.ParentID == "" && m.TraceParent == "" && m.Origin == nil && m.CreatedAt == nil && m.Deadline == nil
+}
+
+func (m Metadata) Validate() error {
+	if m.TraceParent != "" && !traceParentRegex.MatchString(m.TraceParent) {
+		return fmt.Errorf("%w: %q", errMetadataInvalidTraceParent, m.TraceParent)
+	}
+	if m.CreatedAt != nil && m.Deadline != nil && m.Deadline.Before(*m.CreatedAt) {
+		return errMetadataDeadlineBeforeTime
+	}
+
+	return nil
+}
+
+// clone returns a deep copy of the metadata.
+func (m *Metadata) clone() *Metadata {
+	if m == nil {
+		return nil
+	}
+	ret := *m
+	if m.Origin != nil {
+		o := *m.Origin
+		ret.Origin = &o
+	}
+	if m.CreatedAt != nil {
+		t := *m.CreatedAt
+		ret.CreatedAt = &t
+	}
+	if m.Deadline != nil {
+		t := *m.Deadline
+		ret.Deadline = &t
+	}
+
+	return &ret
+}
+
+// headers returns the AMQP headers representing the metadata.
+func (m Metadata) headers() amqp.Table {
+	ret := amqp.Table{}
+	if m.ParentID != "" {
+		ret[HeaderParentID] = m.ParentID
+	}
+	if m.TraceParent != "" {
+		ret[HeaderTraceParent] = m.TraceParent
+	}
+	if m.Origin != nil {
+		if m.Origin.Lockfile != "" {
+			ret[HeaderOriginLockfile] = m.Origin.Lockfile
+		}
+		if m.Origin.Repository != "" {
+			ret[HeaderOriginRepository] = m.Origin.Repository
+		}
+		if m.Origin.PullRequest != 0 {
+			ret[HeaderOriginPullRequest] = int64(m.Origin.PullRequest)
+		}
+	}
+	if m.CreatedAt != nil {
+		ret[HeaderCreatedAt] = m.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	if m.Deadline != nil {
+		ret[HeaderDeadline] = m.Deadline.UTC().Format(time.RFC3339Nano)
+	}
+
+	return ret
+}
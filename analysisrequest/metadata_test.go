@@ -0,0 +1,191 @@
+package analysisrequest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hgsgtk/jsoncmp"
+	"github.com/listendev/pkg/observability"
+	"github.com/listendev/pkg/observability/tracer"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMetadataFromJSON(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deadline := created.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		body    []byte
+		want    Metadata
+		wantErr bool
+	}{
+		{
+			name: "without metadata",
+			body: []byte(`{"type": "urn:nop:nop", "snowflake_id": "1524854487523524609"}`),
+			want: Metadata{},
+		},
+		{
+			name: "with full metadata",
+			body: []byte(`{"type": "urn:nop:nop", "snowflake_id": "1524854487523524609", "metadata": {"parent_id": "1524854487523524608", "traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "origin": {"lockfile": "package-lock.json", "repository": "listendev/pkg", "pull_request": 42}, "created_at": "2024-01-02T03:04:05Z", "deadline": "2024-01-02T04:04:05Z"}}`),
+			want: Metadata{
+				ParentID:    "1524854487523524608",
+				TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				Origin: &Origin{
+					Lockfile:    "package-lock.json",
+					Repository:  "listendev/pkg",
+					PullRequest: 42,
+				},
+				CreatedAt: &created,
+				Deadline:  &deadline,
+			},
+		},
+		{
+			name:    "with invalid traceparent",
+			body:    []byte(`{"type": "urn:nop:nop", "snowflake_id": "1524854487523524609", "metadata": {"traceparent": "xyz"}}`),
+			wantErr: true,
+		},
+		{
+			name:    "with deadline before creation time",
+			body:    []byte(`{"type": "urn:nop:nop", "snowflake_id": "1524854487523524609", "metadata": {"created_at": "2024-01-02T04:04:05Z", "deadline": "2024-01-02T03:04:05Z"}}`),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arbuilder, err := NewBuilder(observability.NewNopContext())
+			require.Nil(t, err)
+
+			got, err := arbuilder.FromJSON(tt.body)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got.Metadata())
+		})
+	}
+}
+
+func TestMetadataBackwardCompatibleJSON(t *testing.T) {
+	a := NewNOP("1524854487523524609", 0, false)
+	a.SetMetadata(Metadata{})
+
+	res, err := json.Marshal(a)
+	require.Nil(t, err)
+	if diff := jsoncmp.Diff(`{"type":"urn:nop:nop","snowflake_id":"1524854487523524609","force":false}`, string(res)); diff != "" {
+		t.Errorf("diff: (-got +want)\n%s", diff)
+	}
+}
+
+func TestMetadataAMQPHeaders(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	deadline := created.Add(time.Hour)
+
+	a, err := NewNPM(NPMTyposquat, "1524854487523524608", 0, false, "chalk", "5.2.0", "249623b7d66869c673699fb66d65723e54dfcfb3")
+	require.Nil(t, err)
+	a.SetMetadata(Metadata{
+		ParentID:    "1524854487523524607",
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Origin: &Origin{
+			Lockfile:    "package-lock.json",
+			Repository:  "listendev/pkg",
+			PullRequest: 42,
+		},
+		CreatedAt: &created,
+		Deadline:  &deadline,
+	})
+
+	wantHeaders := amqp.Table{
+		HeaderParentID:          "1524854487523524607",
+		HeaderTraceParent:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		HeaderOriginLockfile:    "package-lock.json",
+		HeaderOriginRepository:  "listendev/pkg",
+		HeaderOriginPullRequest: int64(42),
+		HeaderCreatedAt:         "2024-01-02T03:04:05Z",
+		HeaderDeadline:          "2024-01-02T04:04:05Z",
+	}
+
+	pub, err := a.Publishing()
+	require.Nil(t, err)
+	assert.Equal(t, wantHeaders, pub.Headers)
+	assert.Nil(t, pub.Headers.Validate())
+	assert.Equal(t, created, pub.Timestamp)
+
+	del, err := a.Delivery()
+	require.Nil(t, err)
+	assert.Equal(t, wantHeaders, del.Headers)
+	assert.Equal(t, created, del.Timestamp)
+
+	// Switching type keeps the metadata
+	switched, err := a.(*NPM).Switch(NPMMetadataVersion)
+	require.Nil(t, err)
+	assert.Equal(t, a.Metadata(), switched.Metadata())
+	switchedPub, err := switched.Publishing()
+	require.Nil(t, err)
+	assert.Equal(t, wantHeaders, switchedPub.Headers)
+
+	// Changing the metadata of the switched request doesn't affect the original one
+	m := switched.Metadata()
+	m.ParentID = a.ID()
+	m.Origin.PullRequest = 43
+	switched.SetMetadata(m)
+	assert.Equal(t, "1524854487523524607", a.Metadata().ParentID)
+	assert.Equal(t, 42, a.Metadata().Origin.PullRequest)
+}
+
+func TestNoMetadataNoAMQPHeaders(t *testing.T) {
+	a := NewNOP("1524854487523524609", 0, false)
+
+	pub, err := a.Publishing()
+	require.Nil(t, err)
+	assert.Nil(t, pub.Headers)
+	assert.True(t, pub.Timestamp.IsZero())
+}
+
+func TestAMQPTraceContextWithoutBroker(t *testing.T) {
+	a, err := NewNPM(NPMTyposquat, "1524854487523524608", 0, false, "chalk", "5.2.0", "249623b7d66869c673699fb66d65723e54dfcfb3")
+	require.Nil(t, err)
+	a.SetMetadata(Metadata{TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+
+	consume := func(pub *amqp.Publishing) trace.SpanContext {
+		t.Helper()
+
+		ctx, span := tracer.StartAMQPDeliverySpan(context.Background(), "consume", amqp.Delivery{Headers: pub.Headers})
+		defer span.End()
+
+		return trace.SpanContextFromContext(ctx)
+	}
+
+	// The consumers continue the trace of the metadata
+	pub, err := ComposeAMQPPublishing(a)
+	require.Nil(t, err)
+	assert.NotContains(t, pub.Headers, tracer.HeaderTraceParent)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", consume(pub).TraceID().String())
+
+	// The consumers continue the trace of the context of the publisher
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := tp.Tracer("test").Start(context.Background(), "publish")
+	defer span.End()
+	pub, err = ComposeAMQPPublishingWithContext(ctx, a, EncodingJSON)
+	require.Nil(t, err)
+	assert.Contains(t, pub.Headers, tracer.HeaderTraceParent)
+	assert.Equal(t, a.Metadata().TraceParent, pub.Headers[HeaderTraceParent])
+	assert.Nil(t, pub.Headers.Validate())
+	assert.Equal(t, span.SpanContext().TraceID(), consume(pub).TraceID())
+
+	// Nothing to inject
+	pub, err = ComposeAMQPPublishingWithContext(context.Background(), NewNOP("1524854487523524609", 0, false), EncodingJSON)
+	require.Nil(t, err)
+	assert.Nil(t, pub.Headers)
+}
@@ -179,6 +179,8 @@ func (arn NPM) Switch(t Type) (AnalysisRequest, error) {
 		return nil, errors.New("couldn't switch the current NPM analysis request to a non NPM one")
 	}
 	arn.RequestType = t
+	// Keep the lineage without sharing it with the current analysis request
+	arn.Meta = arn.Meta.clone()
 
 	return &arn, nil
 }
@@ -1,6 +1,8 @@
 package analysisrequest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path"
 
@@ -14,6 +16,20 @@ func (r ResultUploadPath) Key() string {
 	return path.Join(r...)
 }
 
+// IdempotencyKey returns the canonical key identifying the work the given analysis request asks for.
+//
+// Two analysis requests have the same key when they have the same type and the same results path,
+// which means same ecosystem, package name, version, and digest.
+// The key is the hex-encoded SHA-256 of the type URN and of the results path key.
+func IdempotencyKey(a AnalysisRequest) string {
+	h := sha256.New()
+	h.Write([]byte(a.Type().String()))
+	h.Write([]byte{0})
+	h.Write([]byte(a.ResultsPath().Key()))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func ComposeResultUploadPath(a AnalysisRequest) ResultUploadPath {
 	t := a.Type()
 	c := t.Components()
@@ -132,3 +132,22 @@ func TestGetTypesFromResultFile(t *testing.T) {
 		assert.Equal(t, `couldn't find any type in any ecosystem matching the results file "unknown.json"`, err.Error())
 	}
 }
+
+func TestIdempotencyKey(t *testing.T) {
+	a, _ := NewNPM(NPMTyposquat, "1524854487523524608", 5, false, "chalk", "5.1.2", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	// Same work, different request
+	b, _ := NewNPM(NPMTyposquat, "1524854487523524609", 1, true, "chalk", "5.1.2", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	// Different digest
+	c, _ := NewNPM(NPMTyposquat, "1524854487523524608", 5, false, "chalk", "5.1.2", "0000000000000000000000000000000000000000")
+	// Different type sharing the results file
+	d, _ := NewNPM(NPMTyposquat, "1524854487523524608", 5, false, "chalk", "5.1.2", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	d.(*NPM).RequestType = NPMMetadataMaintainersEmailCheck
+
+	// The key must be stable, since it outlives the processes computing it
+	assert.Equal(t, "632a3a789ca97304a2b4d1067634c4099683870e400451c35c9cdc3fb3d24e52", IdempotencyKey(a))
+	assert.Equal(t, IdempotencyKey(a), IdempotencyKey(b))
+	assert.NotEqual(t, IdempotencyKey(a), IdempotencyKey(c))
+	assert.NotEqual(t, IdempotencyKey(a), IdempotencyKey(d))
+	// NOP analysis requests are always unique
+	assert.NotEqual(t, IdempotencyKey(NewNOP("1", 0, false)), IdempotencyKey(NewNOP("2", 0, false)))
+}
@@ -0,0 +1,284 @@
+package analysisrequest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Those are the field numbers of the protobuf messages.
+//
+// The schema is in analysisrequest.proto, which the other languages can generate their code from.
+//
+// Never change the existing field numbers: append new fields.
+const (
+	pbTimestampSeconds protowire.Number = 1
+	pbTimestampNanos   protowire.Number = 2
+
+	pbOriginLockfile    protowire.Number = 1
+	pbOriginRepository  protowire.Number = 2
+	pbOriginPullRequest protowire.Number = 3
+
+	pbMetadataParentID    protowire.Number = 1
+	pbMetadataTraceParent protowire.Number = 2
+	pbMetadataOrigin      protowire.Number = 3
+	pbMetadataCreatedAt   protowire.Number = 4
+	pbMetadataDeadline    protowire.Number = 5
+
+	pbType       protowire.Number = 1
+	pbSnowflake  protowire.Number = 2
+	pbPriority   protowire.Number = 3
+	pbForce      protowire.Number = 4
+	pbName       protowire.Number = 5
+	pbVersion    protowire.Number = 6
+	pbShasum     protowire.Number = 7
+	pbSha256     protowire.Number = 8
+	pbBlake2b256 protowire.Number = 9
+	pbMetadata   protowire.Number = 10
+)
+
+var (
+	errProtobufWireType   = errors.New("unexpected protobuf wire type")
+	errProtobufOutOfRange = errors.New("protobuf field value out of range")
+)
+
+type protobufEncoding struct{}
+
+func (protobufEncoding) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (protobufEncoding) Encode(a AnalysisRequest) ([]byte, error) {
+	w, err := toWire(a)
+	if err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = pbAppendVarint(b, pbType, uint64(w.RequestType))
+	b = pbAppendString(b, pbSnowflake, w.Snowflake)
+	b = pbAppendVarint(b, pbPriority, uint64(w.Priority))
+	if w.Force {
+		b = pbAppendVarint(b, pbForce, 1)
+	}
+	b = pbAppendString(b, pbName, w.Name)
+	b = pbAppendString(b, pbVersion, w.Version)
+	b = pbAppendString(b, pbShasum, w.Shasum)
+	b = pbAppendString(b, pbSha256, w.Sha256)
+	b = pbAppendString(b, pbBlake2b256, w.Blake2b256)
+	if w.Meta != nil {
+		b = protowire.AppendTag(b, pbMetadata, protowire.BytesType)
+		b = protowire.AppendBytes(b, pbEncodeMetadata(w.Meta))
+	}
+
+	return b, nil
+}
+
+func (protobufEncoding) Decode(data []byte) (AnalysisRequest, error) {
+	var w wire
+	err := pbRange(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == pbType && typ == protowire.VarintType:
+			if varint > math.MaxUint32 {
+				return fmt.Errorf("%w: type %d", errProtobufOutOfRange, varint)
+			}
+			w.RequestType = Type(varint)
+		case num == pbSnowflake && typ == protowire.BytesType:
+			w.Snowflake = string(value)
+		case num == pbPriority && typ == protowire.VarintType:
+			if varint > math.MaxUint8 {
+				return fmt.Errorf("%w: priority %d", errProtobufOutOfRange, varint)
+			}
+			w.Priority = uint8(varint)
+		case num == pbForce && typ == protowire.VarintType:
+			w.Force = varint != 0
+		case num == pbName && typ == protowire.BytesType:
+			w.Name = string(value)
+		case num == pbVersion && typ == protowire.BytesType:
+			w.Version = string(value)
+		case num == pbShasum && typ == protowire.BytesType:
+			w.Shasum = string(value)
+		case num == pbSha256 && typ == protowire.BytesType:
+			w.Sha256 = string(value)
+		case num == pbBlake2b256 && typ == protowire.BytesType:
+			w.Blake2b256 = string(value)
+		case num == pbMetadata && typ == protowire.BytesType:
+			m, err := pbDecodeMetadata(value)
+			if err != nil {
+				return err
+			}
+			w.Meta = m
+		case num <= pbMetadata:
+			return fmt.Errorf("%w %d for field %d", errProtobufWireType, typ, num)
+		}
+
+		// Skip unknown fields
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return w.toAnalysisRequest()
+}
+
+func pbEncodeMetadata(m *Metadata) []byte {
+	var b []byte
+	b = pbAppendString(b, pbMetadataParentID, m.ParentID)
+	b = pbAppendString(b, pbMetadataTraceParent, m.TraceParent)
+	if m.Origin != nil {
+		var o []byte
+		o = pbAppendString(o, pbOriginLockfile, m.Origin.Lockfile)
+		o = pbAppendString(o, pbOriginRepository, m.Origin.Repository)
+		o = pbAppendVarint(o, pbOriginPullRequest, uint64(m.Origin.PullRequest))
+		b = protowire.AppendTag(b, pbMetadataOrigin, protowire.BytesType)
+		b = protowire.AppendBytes(b, o)
+	}
+	b = pbAppendTimestamp(b, pbMetadataCreatedAt, m.CreatedAt)
+	b = pbAppendTimestamp(b, pbMetadataDeadline, m.Deadline)
+
+	return b
+}
+
+func pbDecodeMetadata(data []byte) (*Metadata, error) {
+	m := &Metadata{}
+	err := pbRange(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case pbMetadataParentID:
+			m.ParentID = string(value)
+		case pbMetadataTraceParent:
+			m.TraceParent = string(value)
+		case pbMetadataOrigin:
+			o := &Origin{}
+			if err := pbRange(value, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+				switch {
+				case num == pbOriginLockfile && typ == protowire.BytesType:
+					o.Lockfile = string(value)
+				case num == pbOriginRepository && typ == protowire.BytesType:
+					o.Repository = string(value)
+				case num == pbOriginPullRequest && typ == protowire.VarintType:
+					o.PullRequest = int(int64(varint))
+				}
+
+				return nil
+			}); err != nil {
+				return err
+			}
+			m.Origin = o
+		case pbMetadataCreatedAt:
+			t, err := pbDecodeTimestamp(value)
+			if err != nil {
+				return err
+			}
+			m.CreatedAt = t
+		case pbMetadataDeadline:
+			t, err := pbDecodeTimestamp(value)
+			if err != nil {
+				return err
+			}
+			m.Deadline = t
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func pbAppendTimestamp(b []byte, num protowire.Number, t *time.Time) []byte {
+	if t == nil {
+		return b
+	}
+	var ts []byte
+	ts = pbAppendVarint(ts, pbTimestampSeconds, uint64(t.Unix()))
+	ts = pbAppendVarint(ts, pbTimestampNanos, uint64(t.Nanosecond()))
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+
+	return protowire.AppendBytes(b, ts)
+}
+
+func pbDecodeTimestamp(data []byte) (*time.Time, error) {
+	var secs, nanos int64
+	err := pbRange(data, func(num protowire.Number, typ protowire.Type, _ []byte, varint uint64) error {
+		if typ != protowire.VarintType {
+			return nil
+		}
+		switch num {
+		case pbTimestampSeconds:
+			secs = int64(varint)
+		case pbTimestampNanos:
+			nanos = int64(varint)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	t := time.Unix(secs, nanos).UTC()
+
+	return &t, nil
+}
+
+// pbAppendVarint appends the given varint field, unless it has the default value.
+func pbAppendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+
+	return protowire.AppendVarint(b, v)
+}
+
+// pbAppendString appends the given string field, unless it has the default value.
+func pbAppendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+
+	return protowire.AppendString(b, s)
+}
+
+// pbRange calls the given function for every field of the given protobuf message.
+//
+// It passes the value of the length-delimited fields and of the varint fields, skipping the values of the other fields.
+func pbRange(data []byte, f func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := f(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
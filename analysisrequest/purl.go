@@ -0,0 +1,53 @@
+package analysisrequest
+
+import (
+	"fmt"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/purl"
+)
+
+// NewFromPURL creates an AnalysisRequest of the given type for the package the given package URL identifies.
+//
+// The ecosystem of the package URL must be the one of the type,
+// while its checksum qualifier, if any, provides the package digest (see purl.PURL.Digest).
+func NewFromPURL(request Type, snowflake string, priority uint8, force bool, packageURL string) (AnalysisRequest, error) {
+	p, err := purl.Parse(packageURL)
+	if err != nil {
+		return nil, err
+	}
+	eco, err := p.Ecosystem()
+	if err != nil {
+		return nil, err
+	}
+
+	switch eco {
+	case ecosystem.Npm:
+		return NewNPMFromPURL(request, snowflake, priority, force, p)
+	case ecosystem.Pypi:
+		return NewPyPiFromPURL(request, snowflake, priority, force, p)
+	}
+
+	return nil, fmt.Errorf("couldn't instantiate an analysis request for the %s ecosystem", eco.Case())
+}
+
+// NewNPMFromPURL creates an AnalysisRequest for the NPM ecosystem from the given package URL.
+func NewNPMFromPURL(request Type, snowflake string, priority uint8, force bool, p purl.PURL) (AnalysisRequest, error) {
+	if eco, _ := p.Ecosystem(); eco != ecosystem.Npm {
+		return nil, fmt.Errorf("couldn't instantiate an analysis request for NPM from a %s package URL", p.Type)
+	}
+
+	return NewNPM(request, snowflake, priority, force, p.FullName(), p.Version, p.Digest())
+}
+
+// NewPyPiFromPURL creates an AnalysisRequest for the PyPi ecosystem from the given package URL.
+func NewPyPiFromPURL(request Type, snowflake string, priority uint8, force bool, p purl.PURL) (AnalysisRequest, error) {
+	if eco, _ := p.Ecosystem(); eco != ecosystem.Pypi {
+		return nil, fmt.Errorf("couldn't instantiate an analysis request for PyPi from a %s package URL", p.Type)
+	}
+	if p.Namespace != "" {
+		return nil, fmt.Errorf("couldn't instantiate an analysis request for PyPi from a package URL with namespace %q", p.Namespace)
+	}
+
+	return NewPyPi(request, snowflake, priority, force, p.Name, p.Version, p.Digest())
+}
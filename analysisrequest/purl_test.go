@@ -0,0 +1,50 @@
+package analysisrequest
+
+import (
+	"testing"
+
+	"github.com/listendev/pkg/purl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromPURL(t *testing.T) {
+	a, err := NewFromPURL(NPMTyposquat, "1524854487523524608", 5, true, "pkg:npm/%40vue/devtools@6.5.0?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	require.Nil(t, err)
+	npm, ok := a.(*NPM)
+	require.True(t, ok)
+	assert.Equal(t, "@vue/devtools", npm.Name)
+	assert.Equal(t, "6.5.0", npm.Version)
+	assert.Equal(t, "d957f370038b75ac572471e83be4c5ca9f8e8c45", npm.Shasum)
+	assert.Equal(t, NPMTyposquat, npm.Type())
+	assert.Equal(t, uint8(5), npm.Prio())
+	assert.True(t, npm.MustProcess())
+
+	a, err = NewFromPURL(PypiTyposquat, "1524854487523524608", 0, false, "pkg:pypi/Typing_Extensions@4.9.0")
+	require.Nil(t, err)
+	pypi, ok := a.(*PyPi)
+	require.True(t, ok)
+	assert.Equal(t, "typing-extensions", pypi.Name)
+	assert.Equal(t, "4.9.0", pypi.Version)
+	assert.Empty(t, pypi.Blake2b256)
+
+	cases := []struct {
+		typ Type
+		url string
+	}{
+		{NPMTyposquat, "npm/chalk@5.1.2"},
+		{NPMTyposquat, "pkg:maven/org.apache.commons/io@1.3.4"},
+		{NPMTyposquat, "pkg:pypi/requests@2.31.0"},
+		{PypiTyposquat, "pkg:npm/chalk@5.1.2"},
+		{Nop, "pkg:npm/chalk@5.1.2"},
+	}
+	for _, tc := range cases {
+		_, err := NewFromPURL(tc.typ, "1524854487523524608", 0, false, tc.url)
+		assert.Error(t, err, tc.url)
+	}
+
+	_, err = NewPyPiFromPURL(PypiTyposquat, "1524854487523524608", 0, false, purl.PURL{Type: "pypi", Namespace: "org", Name: "requests"})
+	assert.Error(t, err)
+	_, err = NewNPMFromPURL(NPMTyposquat, "1524854487523524608", 0, false, purl.PURL{Type: "pypi", Name: "requests"})
+	assert.Error(t, err)
+}
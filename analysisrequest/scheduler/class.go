@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/listendev/pkg/analysisrequest"
+)
+
+// Class is a named priority class of the analysis requests.
+type Class uint8
+
+const (
+	// Backfill is the class of the analysis requests populating the results of packages nobody is waiting for.
+	Backfill Class = iota
+	// Rescan is the class of the analysis requests refreshing the results of already analyzed packages.
+	Rescan
+	// Interactive is the class of the analysis requests someone is waiting for (eg., a pull request check).
+	Interactive
+)
+
+// Those are the AMQP priorities of the classes.
+//
+// They leave room for aging within the default maximum priority of the queues.
+const (
+	BackfillPriority    uint8 = 1
+	RescanPriority      uint8 = 4
+	InteractivePriority uint8 = 7
+)
+
+func (c Class) String() string {
+	switch c {
+	case Backfill:
+		return "backfill"
+	case Rescan:
+		return "rescan"
+	case Interactive:
+		return "interactive"
+	}
+
+	return fmt.Sprintf("Class(%d)", uint8(c))
+}
+
+// Priority returns the AMQP priority of the class.
+func (c Class) Priority() uint8 {
+	switch c {
+	case Rescan:
+		return RescanPriority
+	case Interactive:
+		return InteractivePriority
+	}
+
+	return BackfillPriority
+}
+
+// Apply sets the priority of the given analysis request to the one of the class.
+func (c Class) Apply(a analysisrequest.AnalysisRequest) {
+	a.SetPrio(c.Priority())
+}
+
+// ClassOf returns the class the priority of the given analysis request falls into.
+func ClassOf(a analysisrequest.AnalysisRequest) Class {
+	switch p := a.Prio(); {
+	case p >= InteractivePriority:
+		return Interactive
+	case p >= RescanPriority:
+		return Rescan
+	}
+
+	return Backfill
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+)
+
+// DefaultMaxPriority is the priority the aging stops at.
+//
+// It matches the default maximum priority of the broker queues.
+const DefaultMaxPriority uint8 = 10
+
+// ErrDeadlineExceeded tells an analysis request got dropped because its deadline passed.
+var ErrDeadlineExceeded = errors.New("analysis request deadline exceeded")
+
+// Policy computes the effective priority of the analysis requests and tells which ones to drop.
+//
+// The zero value is usable: it doesn't age the analysis requests and it puts all of them under the same tenant.
+type Policy struct {
+	// Aging is how long an analysis request waits to gain one priority level (zero disables the aging)
+	Aging time.Duration
+	// MaxPriority is the priority the aging stops at (defaults to DefaultMaxPriority)
+	MaxPriority uint8
+	// Tenant returns the tenant of an analysis request (defaults to DefaultTenant)
+	Tenant func(a analysisrequest.AnalysisRequest) string
+	// Now returns the current time (defaults to time.Now)
+	Now func() time.Time
+}
+
+// DefaultTenant returns the owner of the repository the analysis request originates from, if any.
+func DefaultTenant(a analysisrequest.AnalysisRequest) string {
+	m := a.Metadata()
+	if m.Origin == nil {
+		return ""
+	}
+	owner, _, _ := strings.Cut(m.Origin.Repository, "/")
+
+	return owner
+}
+
+func (p *Policy) now() time.Time {
+	if p == nil || p.Now == nil {
+		return time.Now()
+	}
+
+	return p.Now()
+}
+
+func (p *Policy) maxPriority() uint8 {
+	if p == nil || p.MaxPriority == 0 {
+		return DefaultMaxPriority
+	}
+
+	return p.MaxPriority
+}
+
+// TenantOf returns the tenant of the given analysis request.
+func (p *Policy) TenantOf(a analysisrequest.AnalysisRequest) string {
+	if p == nil || p.Tenant == nil {
+		return DefaultTenant(a)
+	}
+
+	return p.Tenant(a)
+}
+
+// Expired tells whether the deadline of the given analysis request passed.
+func (p *Policy) Expired(a analysisrequest.AnalysisRequest) bool {
+	m := a.Metadata()
+
+	return m.Deadline != nil && !p.now().Before(*m.Deadline)
+}
+
+// Priority returns the priority of the given analysis request aged since the given time.
+//
+// The analysis requests gain one priority level every Aging, up to MaxPriority,
+// without ever losing their priority when it already exceeds MaxPriority.
+func (p *Policy) Priority(a analysisrequest.AnalysisRequest, since time.Time) uint8 {
+	prio := a.Prio()
+	maxPrio := p.maxPriority()
+	if p == nil || p.Aging <= 0 || prio >= maxPrio {
+		return prio
+	}
+	waited := p.now().Sub(since)
+	if waited <= 0 {
+		return prio
+	}
+	levels := int64(waited / p.Aging)
+	if levels >= int64(maxPrio-prio) {
+		return maxPrio
+	}
+
+	return prio + uint8(levels)
+}
+
+// Age returns the priority of the given analysis request aged since its creation time.
+//
+// It returns the priority of the analysis request as is when it lacks a creation time.
+func (p *Policy) Age(a analysisrequest.AnalysisRequest) uint8 {
+	m := a.Metadata()
+	if m.CreatedAt == nil {
+		return a.Prio()
+	}
+
+	return p.Priority(a, *m.CreatedAt)
+}
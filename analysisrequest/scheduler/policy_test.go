@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClass(t *testing.T) {
+	cases := []struct {
+		class Class
+		name  string
+		prio  uint8
+	}{
+		{Backfill, "backfill", BackfillPriority},
+		{Rescan, "rescan", RescanPriority},
+		{Interactive, "interactive", InteractivePriority},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.name, tc.class.String())
+		assert.Equal(t, tc.prio, tc.class.Priority())
+
+		a := analysisrequest.NewNOP("1524854487523524608", 0, false)
+		tc.class.Apply(a)
+		assert.Equal(t, tc.prio, a.Prio())
+		assert.Equal(t, tc.class, ClassOf(a))
+	}
+
+	assert.Equal(t, "Class(9)", Class(9).String())
+	assert.Equal(t, Backfill, ClassOf(analysisrequest.NewNOP("1524854487523524608", 0, false)))
+	assert.Equal(t, Rescan, ClassOf(analysisrequest.NewNOP("1524854487523524608", 5, false)))
+	assert.Equal(t, Interactive, ClassOf(analysisrequest.NewNOP("1524854487523524608", 10, false)))
+}
+
+func TestPolicyPriority(t *testing.T) {
+	c := newClock()
+	since := c.Now()
+	p := &Policy{Aging: time.Minute, MaxPriority: 8, Now: c.Now}
+	backfill := newRequest("1", Backfill, "")
+
+	assert.Equal(t, BackfillPriority, p.Priority(backfill, since))
+	c.Advance(59 * time.Second)
+	assert.Equal(t, BackfillPriority, p.Priority(backfill, since))
+	c.Advance(time.Second)
+	assert.Equal(t, BackfillPriority+1, p.Priority(backfill, since))
+	c.Advance(time.Hour)
+	assert.Equal(t, uint8(8), p.Priority(backfill, since))
+
+	// Aging never lowers priorities
+	assert.Equal(t, uint8(9), p.Priority(analysisrequest.NewNOP("2", 9, false), since))
+	// Nor it applies to future times
+	assert.Equal(t, BackfillPriority, p.Priority(backfill, c.Now().Add(time.Minute)))
+	// Nor when disabled
+	assert.Equal(t, BackfillPriority, (&Policy{Now: c.Now}).Priority(backfill, since))
+	assert.Equal(t, BackfillPriority, (*Policy)(nil).Priority(backfill, since))
+}
+
+func TestPolicyAge(t *testing.T) {
+	c := newClock()
+	p := &Policy{Aging: time.Minute, Now: c.Now}
+
+	a := newRequest("1", Rescan, "")
+	assert.Equal(t, RescanPriority, p.Age(a))
+
+	created := c.Now().Add(-3 * time.Minute)
+	a.SetMetadata(analysisrequest.Metadata{CreatedAt: &created})
+	assert.Equal(t, RescanPriority+3, p.Age(a))
+
+	created = c.Now().Add(-time.Hour)
+	a.SetMetadata(analysisrequest.Metadata{CreatedAt: &created})
+	assert.Equal(t, DefaultMaxPriority, p.Age(a))
+}
+
+func TestPolicyExpired(t *testing.T) {
+	c := newClock()
+	p := &Policy{Now: c.Now}
+
+	assert.False(t, p.Expired(newRequest("1", Backfill, "")))
+	assert.False(t, p.Expired(withDeadline(newRequest("1", Backfill, ""), c.Now().Add(time.Second))))
+	assert.True(t, p.Expired(withDeadline(newRequest("1", Backfill, ""), c.Now())))
+	assert.True(t, p.Expired(withDeadline(newRequest("1", Backfill, ""), c.Now().Add(-time.Second))))
+}
+
+func TestDefaultTenant(t *testing.T) {
+	assert.Equal(t, "", DefaultTenant(newRequest("1", Backfill, "")))
+	assert.Equal(t, "listendev", DefaultTenant(newRequest("1", Backfill, "listendev/pkg")))
+	assert.Equal(t, "listendev", DefaultTenant(newRequest("1", Backfill, "listendev")))
+	assert.Equal(t, "listendev", (*Policy)(nil).TenantOf(newRequest("1", Backfill, "listendev/pkg")))
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+)
+
+type item struct {
+	a        analysisrequest.AnalysisRequest
+	enqueued time.Time
+	seq      uint64
+}
+
+type tenant struct {
+	items []*item
+	// served is the last turn the tenant got served at
+	served uint64
+}
+
+// Scheduler is an in-memory queue of analysis requests.
+//
+// It pops the analysis request having the highest aged priority.
+// On ties, it serves the tenant that was served least recently, and then the oldest analysis request of that tenant.
+// It drops the analysis requests whose deadline passed, both when pushing and when popping.
+//
+// Since the aged priorities change over time, popping takes linear time in the number of queued analysis requests.
+// The tenants start from the current turn, as if they just got served, so that draining its queue doesn't let a tenant skip the turn of the waiting ones.
+// Thus, the scheduler forgets the tenants without queued analysis requests.
+type Scheduler struct {
+	policy *Policy
+	onDrop func(a analysisrequest.AnalysisRequest)
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+	size    int
+	seq     uint64
+	turn    uint64
+}
+
+// New creates a scheduler using the given policy.
+//
+// The given function, when not nil, gets called with every analysis request the scheduler drops.
+func New(policy *Policy, onDrop func(a analysisrequest.AnalysisRequest)) *Scheduler {
+	if policy == nil {
+		policy = &Policy{}
+	}
+
+	return &Scheduler{
+		policy:  policy,
+		onDrop:  onDrop,
+		tenants: map[string]*tenant{},
+	}
+}
+
+// Push queues the given analysis request.
+//
+// It returns ErrDeadlineExceeded, dropping it, when the deadline of the analysis request already passed.
+func (s *Scheduler) Push(a analysisrequest.AnalysisRequest) error {
+	if s.policy.Expired(a) {
+		s.drop(a)
+
+		return ErrDeadlineExceeded
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := s.policy.TenantOf(a)
+	t, ok := s.tenants[name]
+	if !ok {
+		t = &tenant{served: s.turn}
+		s.tenants[name] = t
+	}
+	s.seq++
+	t.items = append(t.items, &item{a: a, enqueued: s.policy.now(), seq: s.seq})
+	s.size++
+
+	return nil
+}
+
+// Pop dequeues the next analysis request, if any.
+func (s *Scheduler) Pop() (analysisrequest.AnalysisRequest, bool) {
+	s.mu.Lock()
+	dropped := s.expire()
+
+	var next *item
+	var nextTenant *tenant
+	var nextName string
+	var nextPrio uint8
+	for name, t := range s.tenants {
+		for _, it := range t.items {
+			prio := s.policy.Priority(it.a, it.enqueued)
+			if next == nil || before(prio, t, it, nextPrio, nextTenant, next) {
+				next, nextTenant, nextName, nextPrio = it, t, name, prio
+			}
+		}
+	}
+	if next != nil {
+		s.turn++
+		nextTenant.served = s.turn
+		s.remove(nextName, nextTenant, next)
+	}
+	s.mu.Unlock()
+
+	for _, a := range dropped {
+		s.drop(a)
+	}
+	if next == nil {
+		return nil, false
+	}
+
+	return next.a, true
+}
+
+// Len returns the number of queued analysis requests, including the expired ones not dropped yet.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.size
+}
+
+// expire removes the expired analysis requests, returning them.
+func (s *Scheduler) expire() []analysisrequest.AnalysisRequest {
+	var ret []analysisrequest.AnalysisRequest
+	for name, t := range s.tenants {
+		kept := t.items[:0]
+		for _, it := range t.items {
+			if s.policy.Expired(it.a) {
+				ret = append(ret, it.a)
+
+				continue
+			}
+			kept = append(kept, it)
+		}
+		s.size -= len(t.items) - len(kept)
+		t.items = kept
+		if len(t.items) == 0 {
+			delete(s.tenants, name)
+		}
+	}
+
+	return ret
+}
+
+// remove removes the given analysis request of the tenant, forgetting the tenant when it has no more.
+func (s *Scheduler) remove(name string, t *tenant, it *item) {
+	for i := range t.items {
+		if t.items[i] == it {
+			t.items = append(t.items[:i], t.items[i+1:]...)
+			s.size--
+			if len(t.items) == 0 {
+				delete(s.tenants, name)
+			}
+
+			return
+		}
+	}
+}
+
+// before tells whether the first item should be popped before the second one.
+func before(prioA uint8, tenantA *tenant, a *item, prioB uint8, tenantB *tenant, b *item) bool {
+	if prioA != prioB {
+		return prioA > prioB
+	}
+	if tenantA.served != tenantB.served {
+		return tenantA.served < tenantB.served
+	}
+
+	return a.seq < b.seq
+}
+
+func (s *Scheduler) drop(a analysisrequest.AnalysisRequest) {
+	if s.onDrop != nil {
+		s.onDrop(a)
+	}
+}
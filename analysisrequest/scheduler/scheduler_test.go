@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a manually advanced clock.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func newRequest(id string, class Class, repository string) analysisrequest.AnalysisRequest {
+	a := analysisrequest.NewNOP(id, 0, false)
+	class.Apply(a)
+	if repository != "" {
+		a.SetMetadata(analysisrequest.Metadata{Origin: &analysisrequest.Origin{Repository: repository}})
+	}
+
+	return a
+}
+
+func withDeadline(a analysisrequest.AnalysisRequest, deadline time.Time) analysisrequest.AnalysisRequest {
+	m := a.Metadata()
+	m.Deadline = &deadline
+	a.SetMetadata(m)
+
+	return a
+}
+
+func popAll(s *Scheduler) []string {
+	ret := []string{}
+	for {
+		a, ok := s.Pop()
+		if !ok {
+			return ret
+		}
+		ret = append(ret, a.ID())
+	}
+}
+
+func must(a analysisrequest.AnalysisRequest, ok bool) analysisrequest.AnalysisRequest {
+	if !ok {
+		panic("empty scheduler")
+	}
+
+	return a
+}
+
+func TestSchedulerPriorityClasses(t *testing.T) {
+	s := New(&Policy{}, nil)
+
+	require.Nil(t, s.Push(newRequest("backfill", Backfill, "")))
+	require.Nil(t, s.Push(newRequest("rescan", Rescan, "")))
+	require.Nil(t, s.Push(newRequest("interactive-1", Interactive, "")))
+	require.Nil(t, s.Push(newRequest("interactive-2", Interactive, "")))
+	assert.Equal(t, 4, s.Len())
+
+	assert.Equal(t, []string{"interactive-1", "interactive-2", "rescan", "backfill"}, popAll(s))
+	assert.Equal(t, 0, s.Len())
+
+	_, ok := s.Pop()
+	assert.False(t, ok)
+}
+
+func TestSchedulerAging(t *testing.T) {
+	c := newClock()
+	s := New(&Policy{Aging: time.Minute, Now: c.Now}, nil)
+
+	require.Nil(t, s.Push(newRequest("backfill", Backfill, "")))
+	c.Advance(10 * time.Minute)
+	require.Nil(t, s.Push(newRequest("interactive", Interactive, "")))
+	require.Nil(t, s.Push(newRequest("rescan", Rescan, "")))
+
+	// The backfill request waited long enough to reach the maximum priority
+	assert.Equal(t, []string{"backfill", "interactive", "rescan"}, popAll(s))
+}
+
+func TestSchedulerTenantFairness(t *testing.T) {
+	s := New(&Policy{}, nil)
+
+	// A tenant flooding the queue doesn't starve the other ones
+	for _, id := range []string{"a1", "a2", "a3", "a4"} {
+		require.Nil(t, s.Push(newRequest(id, Backfill, "alice/repo")))
+	}
+	require.Nil(t, s.Push(newRequest("b1", Backfill, "bob/repo")))
+	require.Nil(t, s.Push(newRequest("b2", Backfill, "bob/other")))
+	require.Nil(t, s.Push(newRequest("c1", Backfill, "carol/repo")))
+
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2", "b2", "a3", "a4"}, popAll(s))
+
+	// Draining its queue doesn't let a tenant skip the turn of the waiting ones
+	require.Nil(t, s.Push(newRequest("a5", Backfill, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("b3", Backfill, "bob/repo")))
+	assert.Equal(t, "a5", must(s.Pop()).ID())
+	require.Nil(t, s.Push(newRequest("a6", Backfill, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("c2", Backfill, "carol/repo")))
+	assert.Equal(t, []string{"b3", "a6", "c2"}, popAll(s))
+}
+
+func TestSchedulerForgetsIdleTenants(t *testing.T) {
+	s := New(&Policy{}, nil)
+
+	require.Nil(t, s.Push(newRequest("a1", Backfill, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("a2", Backfill, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("b1", Backfill, "bob/repo")))
+	assert.Equal(t, "a1", must(s.Pop()).ID())
+	assert.Equal(t, "b1", must(s.Pop()).ID())
+	assert.Len(t, s.tenants, 1)
+
+	// Bob comes back after Alice, who waits since before Bob got served
+	require.Nil(t, s.Push(newRequest("b2", Backfill, "bob/repo")))
+	assert.Equal(t, []string{"a2", "b2"}, popAll(s))
+	assert.Empty(t, s.tenants)
+
+	// The scheduler only remembers the tenants with queued analysis requests
+	require.Nil(t, s.Push(newRequest("first", Backfill, "first/repo")))
+	for i := 0; i < 1000; i++ {
+		require.Nil(t, s.Push(newRequest(strconv.Itoa(i), Backfill, "tenant"+strconv.Itoa(i)+"/repo")))
+		_, ok := s.Pop()
+		require.True(t, ok)
+		assert.Len(t, s.tenants, 1)
+	}
+
+	// Expiring the queued analysis requests forgets their tenants too
+	c := newClock()
+	s = New(&Policy{Now: c.Now}, nil)
+	require.Nil(t, s.Push(withDeadline(newRequest("a1", Backfill, "alice/repo"), c.Now().Add(time.Minute))))
+	c.Advance(time.Hour)
+	_, ok := s.Pop()
+	assert.False(t, ok)
+	assert.Empty(t, s.tenants)
+}
+
+func TestSchedulerFairnessWithinPriority(t *testing.T) {
+	s := New(&Policy{}, nil)
+
+	require.Nil(t, s.Push(newRequest("a1", Interactive, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("a2", Interactive, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("b1", Backfill, "bob/repo")))
+
+	// Fairness never beats priority
+	assert.Equal(t, []string{"a1", "a2", "b1"}, popAll(s))
+}
+
+func TestSchedulerCustomTenant(t *testing.T) {
+	s := New(&Policy{
+		Tenant: func(a analysisrequest.AnalysisRequest) string {
+			return a.Metadata().Origin.Repository
+		},
+	}, nil)
+
+	require.Nil(t, s.Push(newRequest("a1", Rescan, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("a2", Rescan, "alice/repo")))
+	require.Nil(t, s.Push(newRequest("a3", Rescan, "alice/other")))
+
+	assert.Equal(t, []string{"a1", "a3", "a2"}, popAll(s))
+}
+
+func TestSchedulerDeadlines(t *testing.T) {
+	c := newClock()
+	dropped := []string{}
+	s := New(&Policy{Now: c.Now}, func(a analysisrequest.AnalysisRequest) {
+		dropped = append(dropped, a.ID())
+	})
+
+	err := s.Push(withDeadline(newRequest("expired", Interactive, ""), c.Now()))
+	assert.ErrorIs(t, err, ErrDeadlineExceeded)
+	assert.Equal(t, 0, s.Len())
+
+	require.Nil(t, s.Push(withDeadline(newRequest("soon", Interactive, ""), c.Now().Add(time.Minute))))
+	require.Nil(t, s.Push(withDeadline(newRequest("later", Backfill, ""), c.Now().Add(time.Hour))))
+	require.Nil(t, s.Push(newRequest("never", Backfill, "")))
+	assert.Equal(t, 3, s.Len())
+
+	c.Advance(2 * time.Minute)
+	assert.Equal(t, []string{"later", "never"}, popAll(s))
+	assert.Equal(t, []string{"expired", "soon"}, dropped)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/policy"
 	"github.com/listendev/pkg/type/int64string"
 	"github.com/oapi-codegen/runtime"
 )
@@ -234,6 +235,9 @@ type Settings struct {
 	// Policies The policies associated with the settings
 	Policies map[string]struct {
 		Observe *bool `json:"observe,omitempty"`
+
+		// Suppressions The rules suppressing the known-benign verdicts
+		Suppressions *[]SuppressionRule `json:"suppressions,omitempty"`
 	} `bson:"policies" json:"policies"`
 
 	// ProjectId The id of the project that the settings belongs to
@@ -245,6 +249,9 @@ type Settings struct {
 	} `bson:"tokens" json:"tokens,omitempty"`
 }
 
+// SuppressionRule A rule suppressing the verdicts it matches until it expires
+type SuppressionRule = policy.Rule
+
 // GetNetPolicyParams defines parameters for GetNetPolicy.
 type GetNetPolicyParams struct {
 	// GithubRepository The owner and repository name. For example, octocat/Hello-World.
@@ -1577,50 +1584,53 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xb63PjthH/VzBsPrRTSfT7oZnO5HK+3DmT3Hjia9L26ioguRRxJgEeANpWbvS/d/Dg",
-	"AyRlWbJ8yQd+skVgH9j97WKxJL54IctyRoFK4U2/eCJMIMP63wvIgUZAw8WbO6BSPco5y4FLAnrCnMik",
-	"CGYhoxIe9Pg3HGJv6v3Fr5n6lqP/lsh3RdBi+trSLkdeysLbWUxSmOVYJopbBCLkJJeEUW/qXUZAJYkJ",
-	"CCQTQGomUjMRi/UDRW+eFgIiJBniIDmBO9DDUSUYyYQIBEo8IgLhgBXSG3lykYM39YTkhM6VQnfAIxLK",
-	"riYfEkB2sBSuudVMWPAJQvX7YTxnY/swYxGkYvKLZbtcjjwOnwvCIfKmHytxo7Zdb9pslyPvAiSESp0V",
-	"romwxN2nAYsW6u9jWhIqgcc4hC9LJSgBHG1IUlDyuYAZibqW+6ceQtfvXh0cnyBSepR7Iy/HUgJXk/73",
-	"cW98jsfxzZeTo+U3Xc+07FaLs9qOzDr7zLYdYpumbgDWcP+yRj09OjIOeZJr33DOuOILDzjLUy0iAyHw",
-	"HLQFcSETxsnvEKnZroMbFu0qNqrZrFO6nNin36Nx3IEcDo3rG8uxz5SVw9sVsWemVJmgpvUTloHPC0qB",
-	"+/eM3/ozy87nkLMxu1cDFGcwZvHYDI3ViH+3/4ggNUMQyfjCFfc4zxUMW+7zWChZiOXK2TZUaoL9g8Oj",
-	"45PTDkEn7E6OzMjkUv1/XbHV6WimNHYZK4PFKbufRUTkWIZJn06fWOBSBQVJo76ZOnZcc8XCV0Eo/Gwx",
-	"Czim/SI4xD3a7R8c+hnwOawiyTlTsQiuuSQvoCIIGEsB05JiRYyOvFUOt77y30GasvGvjK9aeUm+ynln",
-	"59u7r8FdY+/JcGoT7h5ZvKAzlamzXLY4P49nR9GT47Ozg/2j88Pn8aVFFrQN+DyWyqyYh6289K+zk16H",
-	"mPkRBMXcIYhxKvpRayiYcPn/SGjx0CdBAL8DPit46hIkUuZi6vtmx5mELOulTrBLFsc4PD48hZMwCOLz",
-	"w6Ng7+z06Bgfnp6dHx6cHhxE8cHZKT4+7GMmOZnPQf2abZYFy7TkElyzDGRi3LOSZNbJQWUEJzqC71UE",
-	"+xNjBb+kUulpXP6YLLL026dmLkUkchzCum0pW+gtYqzSnPNjbUFjt8xyK2lsEk5iN4naJOFGRm1nykYe",
-	"dLJeO4f1ZJ3+fOLmgCp6nXBzA6UJagewBn89wGlAouXqpgceq076arahOBmKk6E4GYqToTgZipOhOBmK",
-	"kz+oOBlqkqEmGWqSoSYZapKhJhlqkqEm+TPUJFckh5RQGGqToTYZapOhNhlqk6E2GWqToTb5Y2uTSxoz",
-	"nmFlQZwOXzr9yb50UqZhOCfjkEUwBzqGB8nxWOK5dkFSZFgtUiaASNOR9tM7i+I7nJIIS/2BHKYRVvgc",
-	"ETFzSGYmVBTJP0IswFs++zOrH0jASfqa0ZjMu/gJMY8IxWlj1WVaX7PsBc5Sb1ozUIqGCcvHqrwW2/Or",
-	"WSiOEYbMVOJbcbPky7K61N+OSchEb3llH2DO8eKJAgxXzf9BAhX22LBbGRXnpf58dD5O4Q7MzkmLTKFC",
-	"gUihQu/YI+8ec2Uh0F/Y3Yw2w7OVWstRUikbJ4BTc7DZyhU1B8NPknjxDGaaXHHK02JOdm90y1ZL4Dod",
-	"7l6E5WtksJikzueMm1mkYqC4CRkB5xtnMsvKUltG6qy7PSNF3clhNbQqCZXOThJp4q5ho1GddaoMUUGq",
-	"GYkVOmonlomgL1W+B6n26SuWknDRkytJxGcZi6C7C/7EIkAx4yjBNEoJnaPXlxc/I8lxHJNwoqTaSKWM",
-	"qvwdLHIs1AJxClzq8ZjxUA8xmWwbtLWKOh2rX3m1GlflC4hxkUpkxrXya3TGqSltIqCLZyloVTKwECy9",
-	"gyebNWIZJhRpskLN+nq2dVRt6r6BhZ+i/i7M3FJNa1uktpB0lPyRCP1xPzXYL5XVs5VyVcbrxkKXmQYQ",
-	"x3RuvKYqIsUI/ZXECOd5SkIcpPC3ibc9eEYsI7qIN6syBu2xvTH0S2hhRLb0WIWBq9r3pR4v5PLK1a1k",
-	"a5/35bst9iyDIiWjHMBRREzxeuUgpLNDtu6W4LnQZgEsFihl7LbIEaEoClCABUSIqTApD3LVGdj/xIJJ",
-	"7/2T9bqPu8ap86WbLVuJqRPrfdZ0Wsurz28bHLu2Odv0NrgbR5vmoQyPf381/s/e+Hx28/f1p7KNTx7X",
-	"ICWh857soa1Y9tpX4MelYIE+gfeUR8tlj+juPaZSJMJCsJBgCRG6JzLRYSlKTTcEViA0/2o5tpJTpL2H",
-	"ZaUIicq7VHYmkgmWjhoogJSpv5JtmqNKjWoltH3YLdANrH0Li/67M08wtJH1Ema2q+gEse4TNFY8qh3S",
-	"haVuPoYFJ3JxrQLHLPiHXz9oQYA58O/1edyb6qcjc1NQI06P1sonUuZGG338UrsioxKbm3SQYaIPb3RO",
-	"KOg20bdzzCnICSaqWtUvErTF3tRT0AfAWdeuKRES6CSCO6TjWqBXV5feyEtJCFToqLD8lFc5AYl1s0z3",
-	"V6umas1FJwQizXutXKlzB9ycXb39yd5kT01gOVA1OPUOJ3uTQ9PQSbS5fJwT/27fD6uuwhz0qhWIdCvj",
-	"MvKm3luQtu+gE2jOqDDmPtjbK81lc6XdlxWp/0mYQ7RJbOvSntPf0N5wbVdmoeqWZIREEYYgRFyk6UKt",
-	"9Ghvf2fqmFttPXq0L7Qd79AGK4Veqg2F4hSZ/iUCO7EOAm/60cL/oxtDHHA0rSL2Znkz8kSRZQpY2rPI",
-	"OL/gWDJCkWSIUJ3OCEWftE+0nBIq1bVUAsKvejE5E733TolAQKOcESqRLo5EVUKFHLSJVBbFiMJ988ar",
-	"ZqzqAxeIV0zIVzn5Zf+iocUbe5VVpRIQ8jvbSd2JQ9pXils5S/IClp2YOOhaQlObJQ+4XYnbe04kTGsY",
-	"aBQId1NoAfi1NukKALWAa5vFu4etZfwE1JYqvCxmnavWA2S/BmStyTdHrIMdB7DOC4UdY7bn/cYjuO15",
-	"rfUy0O0RNMD35eHroGFTCPdAyYExBVk3VWx5uQF2bbWH0/K01WpwVRgvOFcet1pP0L9ZgUJMEcuNaukC",
-	"iRxCEi8QRuaMjcpuBOIQAwcaAiqEKt8Vx9/s0bj5Bvg39LkAvkA55jgDCXzyX9qJm7cg34O8KjsQ1Vyh",
-	"Ld89aemX2wjTqNEoQeocMEHfM47se/4R6vk6SHf2FB+tVn0csaq33rg3g2jUANqGHyAtR32rsCZtLOHy",
-	"4snqlaXqeg1Xf+C0VCB9seOJ+06hJ/Detxqvj5xSvkIa+A5H6GeTodEY/USEhjXjiFD9Ahs1UDnkwy1P",
-	"Tk9PRzq6e/KJkylz2/Lb8V5fsl27zbuNz5fZ4V0Zw+b+8pt76f5N93UXNg5QRaMhvIMdvWqXrtrL+3ZY",
-	"jdjruhH5Yom/kjH0pL5uZl0HC6OTEW5KK7dNGjIOk2av9Gb5/wAAAP//v554gT1OAAA=",
+	"H4sIAAAAAAAC/+xc63PjthH/VzBsPrRTUfT7fJrpTC7nS+JMkvHEadL26ioguaRwJgEeAMpWbvS/d/Dg",
+	"G7IsW77kAz/5RAC7i93fPrAk7pMXsbxgFKgU3uyTJ6IF5Fj/8wIKoDHQaPVuCVSqRwVnBXBJQE9IiVyU",
+	"4TxiVMK9Hv+CQ+LNvL8EDdHAUgy+IfLbMuwRfWvXridexqLbeUIymBdYLhS1GETESSEJo97Mu4yBSpIQ",
+	"EEguAKmZSM1ELNEP1HrztBQQI8kQB8kJLEEPxzVjJBdEIFDsEREIh6yU3sSTqwK8mSckJzRVAi2BxySS",
+	"Q0l+XgCygxVzTa0hwsIPEKnf937KfPswZzFkYvqLJbteTzwOH0vCIfZm72t2k75eb/pk1xPvAiRESpwN",
+	"pomxxMOnIYtX6u9DUhIqgSc4gk9rxWgBON5xSUnJxxLmJB5q7p96CF1/++bo9AyRyqLcm3gFlhK4mvS/",
+	"9wf+a+wnN5/OTtZfDC3T01vDzko7Mft0qe1piG2rugVYQ/3TFvH06MQY5FGmfcc544ou3OO8yDSLHITA",
+	"KWgN4lIuGCe/Q6xmdw3c0uhQsElDZpvQ1USXfA/68QByODKmb23HPlNajm43+J6ZUkeCZm2wYDkEvKQU",
+	"eHDH+G0wt+QCDgXz2Z0aoDgHnyW+GfLVSLA8fICRmiGIZHzVZfcwzQ0Ee+bzWCRZhOXG2dZVmgWHR8cn",
+	"p2evBgsGbnd2Ykaml+rf1zVZHY7mSuIuYaWwJGN385iIAsto4ZLpAwu7q8KSZLFrpvadrroSESgnFEG+",
+	"moccUzcLDolDusOj4yAHnsKmJQVnyhehqy7JS6gXhIxlgGm1YoOPTrxNBre2Cr6FLGP+r4xv2nm1fJPx",
+	"zl8/3Xwt6hp7j4ZTf+H+kcVLOsdSQl7IHuXn0RwIenZ6fn50ePL6+Hl0aZmHfQU+j6RSK+ZRLy796/zM",
+	"aRAzP4awTDsLEpwJN2rNCia69L8ntLx3cRDAl8DnJc+6CxZSFmIWBCbjTCOWO1cvcHdZkuDo9PgVnEVh",
+	"mLw+PgkPzl+dnOLjV+evj49eHR3FydH5K3x67CImOUlTUL/mu0XBKix1F1yzHOTCmGfjkvkgBlUevNAe",
+	"fKc8OJgaLQTVKhWe/OrHdJVnXz42cqlFosARbEtL+UqnCF+Fuc6PrQWNTZlVKmkliU5gN4HaBOFWRO1H",
+	"ylYc7ES9fgxzRB13POnGgNp7O+7WdZQ2qDuANfhzAKcFiZ6p2xZ4qDpx1WxjcTIWJ2NxMhYnY3EyFidj",
+	"cTIWJ39QcTLWJGNNMtYkY00y1iRjTTLWJGNN8meoSa5IARmhMNYmY20y1iZjbTLWJmNtMtYmY23yx9Ym",
+	"lzRhPMdKgzgbv3T6k33ppFTDcEH8iMWQAvXhXnLsS5xqEyzKHKtNqs/jSNuQ9tM7i+IlzkiMpaKbYxpj",
+	"hc8JEfPOkrlxFbXkHxEW4K2f/ZnVdyTkJHvLaELSIX4izGNCcdbadRXWt2x7hfPMmzUElKDRghW+Kq/F",
+	"0+k1JBTFGEPO6JOp2eXrqrpUQ0RCLpzllX2AOcerRzIwVDX9ewlU2GPDfnnUlNf689HUz2AJJnPSMleo",
+	"UCBSqNAZe+LdYa40BJwz3gLF4/BsuTZ8FFfK/AXgzBxsnmSKhoKhJ0myegYxvVxRKrIyJftXuiWrOXAd",
+	"DvfPwtI1PJj6pJc/WSM1AUVNyBg43zmSWVJ2tSXESvkMQmr1IIY10Ko51DJ3gkgbdy0dTZqoU0eIGlJt",
+	"T6zR0RixCgSuUPkjSJWnr1hGopUjVpKYz3MWwzAL/sBiQAnjaIFpnBGaoreXFz8hyXGSkGjqTWpPpYyC",
+	"ymerAgu1QZwBl3o8YTzSQ0wunuq0jYg6HKtfRb2brsgXkOAyk8iMa+G3yIwzU9rEQFfPEtCKZGAhWLaE",
+	"R6s1ZjkmFOllpZr1+XTbEbUt+w4afoz4+1BzTzQtbZmBGAr5PRH6435qsF8Jq2cr4eqIN/SFITENII5p",
+	"aqymKiJFCP2VJAgXRUYiHGbwt6n3dPBMWE50EW92ZRTq0L1R9EtIYVj25NiEgavG9pUcL2Ty2tS9YGuf",
+	"u+LdE3KWQZHiUQ3gOCameL3qIGSQIXt3S3AqtFoAixXKGLstC0QoikMUYnWvhVHUHOTqM3DwgYVT5/2T",
+	"7bL7Q+U08bIbLXuBaeDrLm12Wsubz287HLuecrZxNrhbR5v2oQz7v7/x/3Pgv57f/H37qWznk8c1SElo",
+	"6ogeWosEHsRPdwUL9QncUR6pKqUsCg5CpXzhvsWkYYvqeTTV3nhL2R31Q6AkpdVFJ9GOeQ+p+7ph+lOZ",
+	"wcCf1muHSoaSVapAWAgWESwhRndELrSAotLgjoAPhaZfq9lWmGqp8xCvBCFxdcfLzkRygWVHDBRCxtRf",
+	"yXaNnZVEjRBaP+wW6A4ouIWV+07PIxRteL2Emu0uBsFF9y9aO540BnG6Sw9QAyu9MWmsj+IKuIhIlKvX",
+	"IyBQSSXJ1AO4LwjXlXT/3C8hte8RBpE6ssXYYAAiJlZCQu4eNbzmWDqHE0JT4LoOd46T2Pm4fpcwGClw",
+	"dOu+5aXsgAWjziEBS+BEure+BF6HkYfDoeVQCXjz8J1Ikzem2rCtEZ/kBePSBuaFN/OazneQESGBxrAM",
+	"its0MLcqg3aaFxCVaifXKiQZu37368/qTwiYA/9aN5a8mX46MVdedejUow3aVdfdUNR9BAUPRiU2V0Ih",
+	"x0R3IWhKKOh+55cp5hTkFBNv4pk3YtrF3jVT0M+A86Ejmi1NY1ginaAEenN16U28jERAhTalpafCACcg",
+	"se766hcF9duBhoriIIk0L2gL4tUGVC9jpgfTAzWBFUDV4Mw7nh5Mj01ncqHVFeCCBMvDIKrbYynoXStf",
+	"0T25y9ibed+AtA00XQkUjAqj7qODg0pdNunbAlMtDT5YCJqUsS2hdBp16/VAd1U6ra/7xkiUUQRCJGWW",
+	"rdROTw4O9yaOuZ7pkKN/M/P04ODlmV5SCVz1VE0jHoGd2DiBN3tv4f++G3Q54HhWh/ib9Y0qGPJcAUtb",
+	"FhnjlxxLRqi6T02ozn+Eog/aJppPBZX6fjUBEdRNxYIJ5wVqIhDQuGCESqSrfFGfBSIOWkUq7WJE4a59",
+	"dVsTVoVuF4hXTMg3Bfnl8KIlxTt7J1tFJxDyK/tKYC8G6d+N7yU5yUtYD3ziaKgJvdpsecTtRtzecSJh",
+	"1sBAo0B0q4gegN9qlW4AUA+49q3H/mFrCT8CtZUIL4vZzv8ZMEL2c0DWqnx3xHaw0wFs583YnjHreFH3",
+	"AG4d72dfBroORiN8Xx6+HTTsCmEHlDowpiCb7qAtL3fArq32cFYdz3ud2hrjJefK4lbqKfo3K1GEKWKF",
+	"ES1bIVFARJIVwsg0i1DVVkMcEuBAI0BlfbD8zfZ42p8y/IY+lsBXqMAc5yCBT/9LB37zDcgfQV5VrbR6",
+	"rtCaHx7N9QkKYRq3On5InQOm6GvGkf1gZYIcn7npFrWio8VqjiNW9N6nI20nmrSAtuOXdOuJaxdWpa0t",
+	"XF48WryqVN0u4eYv9dbrm0Fg2J/DdV+OORzvx94bhAdOKZ8hDHyFY/STidDIRz8Q0y9hHBGqv8RALVSO",
+	"8fCJJ6fHhyPt3Y540omUhe1d7znXV2S3pvluB/9lMnyXx5jcXz65V+bfNa93YdMBqmi92dhDRq/Ibczl",
+	"rgyrEXvddK5fLPDXPMae1OeNrNtgUTWB+bIqrbpt0ohxmLZ7pTfr/w8A6J7i9gZRAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
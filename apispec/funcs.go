@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/listendev/pkg/models/policy"
 )
 
 type SettingsOptioner func(*SettingsOptions)
@@ -78,3 +80,21 @@ func (s *Settings) TokensSlice(options ...SettingsOptioner) []string {
 
 	return res
 }
+
+// SuppressionRules returns the suppression rules of all the policies, sorted by policy name.
+func (s *Settings) SuppressionRules() []policy.Rule {
+	names := make([]string, 0, len(s.Policies))
+	for name := range s.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := []policy.Rule{}
+	for _, name := range names {
+		if rules := s.Policies[name].Suppressions; rules != nil {
+			res = append(res, *rules...)
+		}
+	}
+
+	return res
+}
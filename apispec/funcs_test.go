@@ -1,6 +1,7 @@
 package apispec
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -183,3 +184,30 @@ func TestTokensMapWithPrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestSuppressionRules(t *testing.T) {
+	settings := Settings{}
+	if rules := settings.SuppressionRules(); len(rules) != 0 {
+		t.Errorf("Expected no rules, got %d", len(rules))
+	}
+
+	network := []SuppressionRule{{ID: "b", Reason: "vetted", Owner: "security"}}
+	install := []SuppressionRule{{ID: "a1", Reason: "vetted", Owner: "security"}, {ID: "a2", Reason: "vetted", Owner: "security"}}
+	settings.Policies = map[string]struct {
+		Observe      *bool              `json:"observe,omitempty"`
+		Suppressions *[]SuppressionRule `json:"suppressions,omitempty"`
+	}{
+		"network": {Suppressions: &network},
+		"install": {Suppressions: &install},
+		"empty":   {},
+	}
+
+	rules := settings.SuppressionRules()
+	ids := []string{}
+	for _, r := range rules {
+		ids = append(ids, r.ID)
+	}
+	if strings.Join(ids, ",") != "a1,a2,b" {
+		t.Errorf("Expected rules a1,a2,b, got %v", ids)
+	}
+}
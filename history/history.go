@@ -0,0 +1,271 @@
+// Package history finds the anomalies in the histories of the releases of the packages,
+// like the sudden version jumps, the republished versions, the releases after a long dormancy,
+// and the releases coming with new maintainers.
+package history
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Kind is the kind of an anomaly.
+type Kind string
+
+const (
+	// KindVersionJump is a release whose version is far higher than the previous ones (eg., 1.2.0 after 1.1.0 but 9.0.0)
+	KindVersionJump Kind = "version-jump"
+	// KindRepublished is a release republishing a removed version
+	KindRepublished Kind = "republished"
+	// KindDormancy is a release after a long time without releases
+	KindDormancy Kind = "dormancy"
+	// KindMaintainerChange is a release whose maintainers are not the ones of the previous release
+	KindMaintainerChange Kind = "maintainer-change"
+)
+
+// The default thresholds of the analyzer.
+const (
+	DefaultMajorJump       = 2
+	DefaultMinorJump       = 10
+	DefaultPatchJump       = 50
+	DefaultDormancy        = 365 * 24 * time.Hour
+	DefaultRepublishWindow = 7 * 24 * time.Hour
+)
+
+// Finding is an anomaly of a release.
+type Finding struct {
+	Kind Kind
+	// Version is the release the anomaly is about
+	Version string
+	// Previous is the release the anomaly compares with:
+	// the highest version before the jump, the removed version, the release before the dormancy, or the one with the previous maintainers
+	Previous string
+	// Time is when the release was published
+	Time time.Time
+	// Gap is the time since the previous release, for the dormancy and the republished versions
+	Gap time.Duration
+	// Added and Removed are the maintainers the release added and removed, sorted
+	Added   []string
+	Removed []string
+}
+
+type Option func(*Analyzer)
+
+// WithMajorJump is an option to set how many major versions above the highest previous one a release has to be to be a jump.
+func WithMajorJump(n uint64) Option {
+	return func(a *Analyzer) {
+		a.majorJump = n
+	}
+}
+
+// WithMinorJump is an option to set how many minor versions above the highest previous one, in the same major version, a release has to be to be a jump.
+func WithMinorJump(n uint64) Option {
+	return func(a *Analyzer) {
+		a.minorJump = n
+	}
+}
+
+// WithPatchJump is an option to set how many patch versions above the highest previous one, in the same minor version, a release has to be to be a jump.
+func WithPatchJump(n uint64) Option {
+	return func(a *Analyzer) {
+		a.patchJump = n
+	}
+}
+
+// WithDormancy is an option to set how long without releases a package is dormant.
+func WithDormancy(d time.Duration) Option {
+	return func(a *Analyzer) {
+		a.dormancy = d
+	}
+}
+
+// WithRepublishWindow is an option to set how soon after a removed version its republishing has to happen to be detected.
+func WithRepublishWindow(d time.Duration) Option {
+	return func(a *Analyzer) {
+		a.republishWindow = d
+	}
+}
+
+// Analyzer finds the anomalies in the histories of the releases.
+//
+// The zero thresholds disable their checks.
+type Analyzer struct {
+	majorJump       uint64
+	minorJump       uint64
+	patchJump       uint64
+	dormancy        time.Duration
+	republishWindow time.Duration
+}
+
+func New(options ...Option) *Analyzer {
+	a := &Analyzer{
+		majorJump:       DefaultMajorJump,
+		minorJump:       DefaultMinorJump,
+		patchJump:       DefaultPatchJump,
+		dormancy:        DefaultDormancy,
+		republishWindow: DefaultRepublishWindow,
+	}
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+type release struct {
+	Release
+	version *semver.Version
+}
+
+// Analyze returns the anomalies of the history of the releases (see FromNPM, and FromPyPI), in the order of the releases.
+//
+// It compares the versions by their semantic version precedence, skipping the invalid ones.
+func (a *Analyzer) Analyze(releases []Release) []Finding {
+	sorted := []release{}
+	for _, r := range releases {
+		v, err := ParseVersion(r.Version)
+		if err != nil || r.Time.IsZero() {
+			continue
+		}
+		sorted = append(sorted, release{Release: r, version: v})
+	}
+	slices.SortStableFunc(sorted, func(x, y release) int {
+		if c := x.Time.Compare(y.Time); c != 0 {
+			return c
+		}
+
+		return x.version.Compare(y.version)
+	})
+
+	res := []Finding{}
+	var (
+		highest     *release
+		last        *release
+		maintainers *release
+	)
+	for i := range sorted {
+		r := &sorted[i]
+		if f, ok := a.jump(r, highest); ok {
+			res = append(res, f)
+		}
+		res = append(res, a.republished(r, sorted[:i])...)
+		if f, ok := a.dormant(r, last); ok {
+			res = append(res, f)
+		}
+		if f, ok := changedMaintainers(r, maintainers); ok {
+			res = append(res, f)
+		}
+
+		if highest == nil || r.version.GreaterThan(highest.version) {
+			highest = r
+		}
+		last = r
+		if !r.Removed && r.Maintainers != nil {
+			maintainers = r
+		}
+	}
+
+	return res
+}
+
+func (a *Analyzer) jump(r, highest *release) (Finding, bool) {
+	if highest == nil || !r.version.GreaterThan(highest.version) {
+		return Finding{}, false
+	}
+	v, h := r.version, highest.version
+	jumped := false
+	switch {
+	case v.Major() > h.Major():
+		jumped = a.majorJump > 0 && v.Major()-h.Major() >= a.majorJump
+	case v.Minor() > h.Minor():
+		jumped = a.minorJump > 0 && v.Minor()-h.Minor() >= a.minorJump
+	case v.Patch() > h.Patch():
+		jumped = a.patchJump > 0 && v.Patch()-h.Patch() >= a.patchJump
+	}
+	if !jumped {
+		return Finding{}, false
+	}
+
+	return Finding{Kind: KindVersionJump, Version: r.Version, Previous: highest.Version, Time: r.Time}, true
+}
+
+// republished finds the removed versions the release republishes, that is its version or the previous patch one,
+// published a short time before.
+//
+// It also reports the versions whose files were yanked and published again.
+func (a *Analyzer) republished(r *release, before []release) []Finding {
+	if a.republishWindow <= 0 {
+		return nil
+	}
+	res := []Finding{}
+	if !r.RepublishedAt.IsZero() && r.RepublishedAt.Sub(r.Time) <= a.republishWindow {
+		res = append(res, Finding{Kind: KindRepublished, Version: r.Version, Previous: r.Version, Time: r.RepublishedAt, Gap: r.RepublishedAt.Sub(r.Time)})
+	}
+	if r.Removed {
+		return res
+	}
+	for _, p := range slices.Backward(before) {
+		gap := r.Time.Sub(p.Time)
+		if gap > a.republishWindow {
+			break
+		}
+		if !p.Removed {
+			continue
+		}
+		v, pv := r.version, p.version
+		if v.Major() == pv.Major() && v.Minor() == pv.Minor() && !v.LessThan(pv) && v.Patch()-pv.Patch() <= 1 {
+			res = append(res, Finding{Kind: KindRepublished, Version: r.Version, Previous: p.Version, Time: r.Time, Gap: gap})
+		}
+	}
+
+	return res
+}
+
+func (a *Analyzer) dormant(r, last *release) (Finding, bool) {
+	if a.dormancy <= 0 || last == nil {
+		return Finding{}, false
+	}
+	gap := r.Time.Sub(last.Time)
+	if gap < a.dormancy {
+		return Finding{}, false
+	}
+
+	return Finding{Kind: KindDormancy, Version: r.Version, Previous: last.Version, Time: r.Time, Gap: gap}, true
+}
+
+// changedMaintainers finds the releases adding maintainers, compared to the previous release with known maintainers.
+//
+// The releases only removing maintainers are not anomalies.
+func changedMaintainers(r, previous *release) (Finding, bool) {
+	if previous == nil || r.Removed || r.Maintainers == nil {
+		return Finding{}, false
+	}
+	before := map[string]bool{}
+	for _, m := range previous.Maintainers {
+		before[m] = true
+	}
+	after := map[string]bool{}
+	for _, m := range r.Maintainers {
+		after[m] = true
+	}
+	f := Finding{Kind: KindMaintainerChange, Version: r.Version, Previous: previous.Version, Time: r.Time, Added: []string{}, Removed: []string{}}
+	for m := range after {
+		if !before[m] {
+			f.Added = append(f.Added, m)
+		}
+	}
+	for m := range before {
+		if !after[m] {
+			f.Removed = append(f.Removed, m)
+		}
+	}
+	if len(f.Added) == 0 {
+		return Finding{}, false
+	}
+	slices.SortFunc(f.Added, strings.Compare)
+	slices.SortFunc(f.Removed, strings.Compare)
+
+	return f, true
+}
@@ -0,0 +1,235 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/pypi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func day(n int) time.Time {
+	return epoch.Add(time.Duration(n) * 24 * time.Hour)
+}
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v1.2.3-beta.1", "1.2.3-beta.1"},
+		{"1.2", "1.2.0"},
+		{"2.0rc1", "2.0.0-rc.1"},
+		{"2.0.0a", "2.0.0-alpha.0"},
+		{"1.0b2.dev1", "1.0.0-beta.2.0dev.1"},
+		{"1.0.dev3", "1.0.0-0dev.3"},
+		{"1.0.post1", "1.0.0+post.1"},
+		{"1.2.3.4", "1.2.3+4"},
+		{"1.0r2", "1.0.0+post.2"},
+	}
+	for _, tc := range cases {
+		got, err := ParseVersion(tc.input)
+		require.Nil(t, err, tc.input)
+		assert.Equal(t, tc.want, got.String(), tc.input)
+	}
+
+	_, err := ParseVersion("latest")
+	assert.Error(t, err)
+
+	// The PyPI development releases, and pre-releases, come before their releases in the PEP 440 order
+	ordered := []string{"2.0.dev1", "2.0.dev2", "2.0a1", "2.0a2", "2.0b1", "2.0rc1", "2.0rc2", "2.0", "2.0.post1", "2.1.dev1"}
+	for i := 1; i < len(ordered); i++ {
+		prev, err := ParseVersion(ordered[i-1])
+		require.Nil(t, err)
+		next, err := ParseVersion(ordered[i])
+		require.Nil(t, err)
+		assert.False(t, next.LessThan(prev), "%s < %s", ordered[i], ordered[i-1])
+		if ordered[i] != "2.0.post1" {
+			assert.True(t, prev.LessThan(next), "%s < %s", ordered[i-1], ordered[i])
+		}
+	}
+}
+
+func TestAnalyzeDevelopmentReleases(t *testing.T) {
+	// The development releases do not raise the highest version above the pre-releases
+	got := New(WithMajorJump(1)).Analyze([]Release{
+		{Version: "1.0a1", Time: day(0)},
+		{Version: "1.0.dev1", Time: day(1)},
+		{Version: "1.0b1", Time: day(2)},
+		{Version: "2.0.dev1", Time: day(3)},
+	})
+	assert.Equal(t, []Finding{
+		{Kind: KindVersionJump, Version: "2.0.dev1", Previous: "1.0b1", Time: day(3)},
+	}, got)
+}
+
+func TestFromNPM(t *testing.T) {
+	got := FromNPM(&npm.PackageList{
+		Versions: map[string]npm.PackageVersion{
+			"1.0.0": {Maintainers: npm.PackageMaintainers{{Name: "alice", Mail: "Alice@example.com"}}},
+			"1.1.0": {Maintainers: npm.PackageMaintainers{{Name: "bob"}}},
+		},
+		Time: map[string]time.Time{
+			"created":  day(0),
+			"modified": day(9),
+			"1.0.0":    day(0),
+			"1.0.1":    day(1),
+			"1.1.0":    day(2),
+		},
+	})
+	assert.Equal(t, []Release{
+		{Version: "1.0.0", Time: day(0), Maintainers: []string{"alice@example.com"}},
+		{Version: "1.0.1", Time: day(1), Removed: true},
+		{Version: "1.1.0", Time: day(2), Maintainers: []string{"bob"}},
+	}, got)
+}
+
+func TestFromPyPI(t *testing.T) {
+	got := FromPyPI(&pypi.PackageList{
+		Versions: map[string]pypi.PackageVersions{
+			"1.0": {
+				{UploadTime: day(1)},
+				{UploadTime: day(0)},
+			},
+			"1.1": {
+				{UploadTime: day(2), Yanked: true},
+				{UploadTime: day(3)},
+			},
+			"1.2": {
+				{UploadTime: day(4), Yanked: true},
+			},
+			"2.0": {},
+		},
+	})
+	assert.Equal(t, []Release{
+		{Version: "1.0", Time: day(0)},
+		{Version: "1.1", Time: day(2), RepublishedAt: day(3)},
+		{Version: "1.2", Time: day(4), Removed: true},
+	}, got)
+}
+
+func TestAnalyzeVersionJump(t *testing.T) {
+	releases := []Release{
+		{Version: "1.0.0", Time: day(0)},
+		{Version: "1.1.0", Time: day(1)},
+		{Version: "1.0.1", Time: day(2)},
+		{Version: "1.1.60", Time: day(3)},
+		{Version: "1.20.0", Time: day(4)},
+		{Version: "2.0.0", Time: day(5)},
+		{Version: "9.0.0", Time: day(6)},
+		{Version: "not-a-version", Time: day(7)},
+	}
+	want := []Finding{
+		{Kind: KindVersionJump, Version: "1.1.60", Previous: "1.1.0", Time: day(3)},
+		{Kind: KindVersionJump, Version: "1.20.0", Previous: "1.1.60", Time: day(4)},
+		{Kind: KindVersionJump, Version: "9.0.0", Previous: "2.0.0", Time: day(6)},
+	}
+	assert.Equal(t, want, New().Analyze(releases))
+
+	// The thresholds are configurable, and the zero ones disable their checks
+	got := New(WithMajorJump(8), WithMinorJump(0), WithPatchJump(100)).Analyze(releases)
+	assert.Empty(t, got)
+	got = New(WithMajorJump(1)).Analyze(releases)
+	require.Len(t, got, 4)
+	assert.Equal(t, "2.0.0", got[2].Version)
+}
+
+func TestAnalyzeRepublished(t *testing.T) {
+	// npm: the removed version comes back as the next patch one
+	got := New().Analyze([]Release{
+		{Version: "1.0.0", Time: day(0)},
+		{Version: "1.0.1", Time: day(10), Removed: true},
+		{Version: "1.0.2", Time: day(11)},
+		{Version: "1.1.0", Time: day(12)},
+	})
+	assert.Equal(t, []Finding{
+		{Kind: KindRepublished, Version: "1.0.2", Previous: "1.0.1", Time: day(11), Gap: 24 * time.Hour},
+	}, got)
+
+	// Too late
+	got = New(WithRepublishWindow(12 * time.Hour)).Analyze([]Release{
+		{Version: "1.0.1", Time: day(10), Removed: true},
+		{Version: "1.0.2", Time: day(11)},
+	})
+	assert.Empty(t, got)
+
+	// PyPI: the files of the yanked version got uploaded again
+	got = New().Analyze(FromPyPI(&pypi.PackageList{
+		Versions: map[string]pypi.PackageVersions{
+			"1.0": {{UploadTime: day(0)}},
+			"1.1": {{UploadTime: day(2), Yanked: true}, {UploadTime: day(3)}},
+		},
+	}))
+	assert.Equal(t, []Finding{
+		{Kind: KindRepublished, Version: "1.1", Previous: "1.1", Time: day(3), Gap: 24 * time.Hour},
+	}, got)
+}
+
+func TestAnalyzeDormancy(t *testing.T) {
+	releases := []Release{
+		{Version: "1.0.0", Time: day(0)},
+		{Version: "1.0.1", Time: day(30)},
+		{Version: "1.0.2", Time: day(430)},
+	}
+	assert.Equal(t, []Finding{
+		{Kind: KindDormancy, Version: "1.0.2", Previous: "1.0.1", Time: day(430), Gap: 400 * 24 * time.Hour},
+	}, New().Analyze(releases))
+
+	got := New(WithDormancy(30 * 24 * time.Hour)).Analyze(releases)
+	require.Len(t, got, 2)
+	assert.Equal(t, "1.0.1", got[0].Version)
+
+	assert.Empty(t, New(WithDormancy(0)).Analyze(releases))
+}
+
+func TestAnalyzeMaintainerChange(t *testing.T) {
+	got := New(WithRepublishWindow(0)).Analyze([]Release{
+		{Version: "1.0.0", Time: day(0), Maintainers: []string{"alice", "bob"}},
+		{Version: "1.0.1", Time: day(1), Maintainers: []string{"bob"}},
+		{Version: "1.0.2", Time: day(2), Removed: true},
+		{Version: "1.0.3", Time: day(3), Maintainers: []string{"mallory", "eve"}},
+		{Version: "1.0.4", Time: day(4)},
+		{Version: "1.0.5", Time: day(5), Maintainers: []string{"eve", "mallory"}},
+	})
+	// Removing maintainers is fine, and the releases with unknown maintainers do not count
+	assert.Equal(t, []Finding{
+		{
+			Kind:     KindMaintainerChange,
+			Version:  "1.0.3",
+			Previous: "1.0.1",
+			Time:     day(3),
+			Added:    []string{"eve", "mallory"},
+			Removed:  []string{"bob"},
+		},
+	}, got)
+}
+
+func TestAnalyzeNPM(t *testing.T) {
+	// A takeover: a dormant package gets a new maintainer, who publishes a new major version
+	got := New().Analyze(FromNPM(&npm.PackageList{
+		Versions: map[string]npm.PackageVersion{
+			"1.0.0": {Maintainers: npm.PackageMaintainers{{Name: "alice", Mail: "alice@example.com"}}},
+			"1.0.1": {Maintainers: npm.PackageMaintainers{{Name: "alice", Mail: "alice@example.com"}}},
+			"3.0.0": {Maintainers: npm.PackageMaintainers{{Name: "mallory", Mail: "mallory@example.com"}}},
+		},
+		Time: map[string]time.Time{
+			"created":  day(0),
+			"modified": day(1000),
+			"1.0.0":    day(0),
+			"1.0.1":    day(10),
+			"3.0.0":    day(1000),
+		},
+	}))
+	kinds := []Kind{}
+	for _, f := range got {
+		assert.Equal(t, "3.0.0", f.Version)
+		assert.Equal(t, "1.0.1", f.Previous)
+		kinds = append(kinds, f.Kind)
+	}
+	assert.Equal(t, []Kind{KindVersionJump, KindDormancy, KindMaintainerChange}, kinds)
+}
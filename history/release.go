@@ -0,0 +1,171 @@
+package history
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/pypi"
+)
+
+// Release is a version of a package in the history of its releases.
+type Release struct {
+	Version string
+	// Time is when the version was published
+	Time time.Time
+	// Removed tells whether the version was unpublished (npm) or yanked (PyPI)
+	Removed bool
+	// RepublishedAt is when the files of the version got published again after some of them were yanked, zero otherwise
+	RepublishedAt time.Time
+	// Maintainers are the email addresses (or the names, when missing) of the maintainers of the version, nil when unknown
+	Maintainers []string
+}
+
+// npmTimeKeys are the keys of the time of the npm packages that are not versions.
+var npmTimeKeys = map[string]bool{"created": true, "modified": true}
+
+// FromNPM returns the releases of an npm package, from the oldest one.
+//
+// The registry keeps the time of the unpublished versions, which are the removed ones.
+func FromNPM(list *npm.PackageList) []Release {
+	res := []Release{}
+	for v, t := range list.Time {
+		if npmTimeKeys[v] {
+			continue
+		}
+		r := Release{Version: v, Time: t}
+		pv, ok := list.Versions[v]
+		if !ok {
+			r.Removed = true
+		} else {
+			r.Maintainers = []string{}
+			for _, m := range pv.Maintainers {
+				r.Maintainers = append(r.Maintainers, maintainerKey(m.Name, m.Mail))
+			}
+		}
+		res = append(res, r)
+	}
+	sortReleases(res)
+
+	return res
+}
+
+// FromPyPI returns the releases of a PyPI package, from the oldest one.
+//
+// The time of a release is the upload time of its first file, and a release is removed when all its files are yanked.
+// The maintainers of the releases are unknown, since PyPI only has the current ones.
+func FromPyPI(list *pypi.PackageList) []Release {
+	res := []Release{}
+	for v, files := range list.Versions {
+		if len(files) == 0 {
+			continue
+		}
+		files = slices.Clone(files)
+		slices.SortFunc(files, func(a, b pypi.PackageVersion) int {
+			return a.UploadTime.Compare(b.UploadTime)
+		})
+		r := Release{Version: v, Time: files[0].UploadTime, Removed: true}
+		yanked := false
+		for _, f := range files {
+			if !f.Yanked {
+				r.Removed = false
+				if yanked && r.RepublishedAt.IsZero() {
+					r.RepublishedAt = f.UploadTime
+				}
+
+				continue
+			}
+			yanked = true
+		}
+		res = append(res, r)
+	}
+	sortReleases(res)
+
+	return res
+}
+
+func maintainerKey(name, email string) string {
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		return email
+	}
+
+	return strings.TrimSpace(name)
+}
+
+func sortReleases(releases []Release) {
+	slices.SortStableFunc(releases, func(a, b Release) int {
+		if c := a.Time.Compare(b.Time); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Version, b.Version)
+	})
+}
+
+// pep440 matches the PyPI versions, capturing their release segment, and the keywords and numbers of their
+// pre-release, post-release, and development parts.
+var pep440 = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d*))?(?:[-_.]?(post|rev|r)[-_.]?(\d*))?(?:[-_.]?(dev)[-_.]?(\d*))?$`)
+
+var preReleases = map[string]string{
+	"a": "alpha", "alpha": "alpha",
+	"b": "beta", "beta": "beta",
+	"c": "rc", "rc": "rc", "pre": "rc", "preview": "rc",
+}
+
+// ParseVersion parses a version as a semantic version, converting the PEP 440 versions of PyPI (eg., 2.0rc1 becomes 2.0.0-rc.1).
+//
+// The development releases become pre-releases starting with 0dev (eg., 1.0.dev3 becomes 1.0.0-0dev.3),
+// which come before the alpha, beta, and release candidate ones like in PEP 440.
+// Semantic versions rank the longer pre-releases higher, though, thus the development releases
+// of the pre-releases (eg., 1.0a1.dev1) come after their pre-release.
+//
+// The post-releases and the release segments after the third one become build metadata,
+// thus they have the same precedence as their release.
+func ParseVersion(v string) (*semver.Version, error) {
+	if res, err := semver.NewVersion(v); err == nil {
+		return res, nil
+	}
+
+	m := pep440.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return nil, fmt.Errorf("invalid version %q", v)
+	}
+	segments := strings.Split(m[1], ".")
+	for len(segments) < 3 {
+		segments = append(segments, "0")
+	}
+	s := strings.Join(segments[:3], ".")
+
+	pre := []string{}
+	if m[2] != "" {
+		pre = append(pre, preReleases[m[2]], zero(m[3]))
+	}
+	if m[6] != "" {
+		pre = append(pre, "0dev", zero(m[7]))
+	}
+	if len(pre) > 0 {
+		s += "-" + strings.Join(pre, ".")
+	}
+
+	build := segments[3:]
+	if m[4] != "" {
+		build = append(build, "post", zero(m[5]))
+	}
+	if len(build) > 0 {
+		s += "+" + strings.Join(build, ".")
+	}
+
+	return semver.NewVersion(s)
+}
+
+func zero(n string) string {
+	if n == "" {
+		return "0"
+	}
+
+	return n
+}
@@ -0,0 +1,227 @@
+// Package maintainer checks the email domains of the maintainers of the packages,
+// for the verdicts about the accounts at risk of takeover (MDN04, MDN09, MDP04, MDP09).
+package maintainer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/pypi"
+	stringutil "github.com/listendev/pkg/string/util"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Maintainer is a maintainer of a package, with one email address.
+type Maintainer struct {
+	Name  string
+	Email string
+}
+
+// FromNPM returns the maintainers of an npm package, one per email address.
+func FromNPM(maintainers npm.PackageMaintainers) []Maintainer {
+	res := []Maintainer{}
+	for _, m := range maintainers {
+		for _, e := range stringutil.MatchEmails(m.Mail) {
+			res = append(res, Maintainer{Name: m.Name, Email: e})
+		}
+	}
+
+	return res
+}
+
+// FromPyPI returns the authors and the maintainers of a PyPI package, one per email address.
+//
+// The PyPI metadata can list many addresses in the same field.
+func FromPyPI(maintainers pypi.PackageMaintainers) []Maintainer {
+	res := []Maintainer{}
+	for _, m := range maintainers {
+		for _, e := range stringutil.MatchEmails(m.Mail) {
+			res = append(res, Maintainer{Name: m.Name, Email: e})
+		}
+	}
+
+	return res
+}
+
+// Status is the status of the registration of an email domain.
+type Status string
+
+const (
+	// StatusUnchecked is the status of the domains not looked up (ie., the free email providers)
+	StatusUnchecked Status = "unchecked"
+	// StatusUnknown is the status of the domains whose lookup failed
+	StatusUnknown Status = "unknown"
+	// StatusActive is the status of the domains registered before the package was published, and not expired
+	StatusActive Status = "active"
+	// StatusExpired is the status of the domains whose registration expired, or that are about to be released
+	StatusExpired Status = "expired"
+	// StatusReregistered is the status of the domains registered after the package was published
+	StatusReregistered Status = "reregistered"
+	// StatusUnregistered is the status of the domains anyone can register
+	StatusUnregistered Status = "unregistered"
+)
+
+// Result is the check of the email domain of a maintainer.
+type Result struct {
+	Maintainer   Maintainer
+	Domain       string
+	Kind         Kind
+	Status       Status
+	Registration Registration
+	// Err is the reason of the unknown status
+	Err error
+}
+
+// Code returns the code of the verdict about the email domain, if any.
+//
+// The expired domains count as available, since their registrations are about to be released.
+// The disposable domains count as available too, whatever their status, since anyone can read their inboxes
+// and thus reset the password of the maintainer account.
+func (r Result) Code(eco ecosystem.Ecosystem) (verdictcode.Code, bool) {
+	var available, reregistered verdictcode.Code
+	switch eco {
+	case ecosystem.Npm:
+		available, reregistered = verdictcode.MDN09, verdictcode.MDN04
+	case ecosystem.Pypi:
+		available, reregistered = verdictcode.MDP09, verdictcode.MDP04
+	default:
+		return verdictcode.UNK, false
+	}
+
+	if r.Kind == KindDisposable {
+		return available, true
+	}
+	switch r.Status {
+	case StatusUnregistered, StatusExpired:
+		return available, true
+	case StatusReregistered:
+		return reregistered, true
+	}
+
+	return verdictcode.UNK, false
+}
+
+// Metadata returns the metadata of the verdict about the email domain.
+func (r Result) Metadata() metadata.Maintainer {
+	return metadata.Maintainer{
+		Name:   r.Maintainer.Name,
+		Email:  r.Maintainer.Email,
+		Domain: r.Domain,
+	}
+}
+
+type Option func(*Analyzer)
+
+// WithClock is an option to set the function returning the current time, to tell the expired domains.
+func WithClock(now func() time.Time) Option {
+	return func(a *Analyzer) {
+		a.now = now
+	}
+}
+
+// Analyzer checks the email domains of the maintainers.
+//
+// It looks up every domain once, thus the same analyzer should check the packages sharing maintainers.
+type Analyzer struct {
+	resolver Resolver
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]Registration
+}
+
+func NewAnalyzer(resolver Resolver, options ...Option) *Analyzer {
+	a := &Analyzer{
+		resolver: resolver,
+		now:      time.Now,
+		cache:    map[string]Registration{},
+	}
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Analyze checks the email domains of the maintainers of a package.
+//
+// The since time is when the maintainers started publishing the package (eg., the time of its first version):
+// the domains registered after it are re-registered ones. When zero, the re-registrations go unnoticed.
+//
+// The lookups failing do not stop the analysis, and their results have the unknown status.
+// Only the cancellation of the context does.
+func (a *Analyzer) Analyze(ctx context.Context, maintainers []Maintainer, since time.Time) ([]Result, error) {
+	res := make([]Result, 0, len(maintainers))
+	for _, m := range maintainers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		r := Result{Maintainer: m}
+		r.Domain, r.Err = Domain(m.Email)
+		if r.Err != nil {
+			r.Status = StatusUnknown
+			res = append(res, r)
+
+			continue
+		}
+		r.Kind = Classify(r.Domain)
+		if r.Kind == KindFree {
+			r.Status = StatusUnchecked
+			res = append(res, r)
+
+			continue
+		}
+		r.Registration, r.Err = a.lookup(ctx, r.Domain)
+		if r.Err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			r.Status = StatusUnknown
+			res = append(res, r)
+
+			continue
+		}
+		r.Status = a.status(r.Registration, since)
+		res = append(res, r)
+	}
+
+	return res, nil
+}
+
+func (a *Analyzer) status(reg Registration, since time.Time) Status {
+	switch {
+	case !reg.Registered:
+		return StatusUnregistered
+	case reg.PendingDelete, !reg.ExpiresAt.IsZero() && reg.ExpiresAt.Before(a.now()):
+		return StatusExpired
+	case !since.IsZero() && reg.CreatedAt.After(since):
+		return StatusReregistered
+	}
+
+	return StatusActive
+}
+
+func (a *Analyzer) lookup(ctx context.Context, domain string) (Registration, error) {
+	domain = strings.ToLower(domain)
+	a.mu.Lock()
+	reg, ok := a.cache[domain]
+	a.mu.Unlock()
+	if ok {
+		return reg, nil
+	}
+
+	reg, err := a.resolver.Lookup(ctx, domain)
+	if err != nil {
+		return Registration{}, err
+	}
+	a.mu.Lock()
+	a.cache[domain] = reg
+	a.mu.Unlock()
+
+	return reg, nil
+}
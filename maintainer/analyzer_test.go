@@ -0,0 +1,189 @@
+package maintainer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/pypi"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	now       = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	published = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newTestAnalyzer() (*Analyzer, *MockResolver) {
+	r := NewMockResolver(map[string]Registration{
+		"example.com": {
+			Registered: true,
+			CreatedAt:  time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+			ExpiresAt:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"expired.com": {
+			Registered: true,
+			CreatedAt:  time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+			ExpiresAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"deleting.com": {
+			Registered:    true,
+			CreatedAt:     time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+			PendingDelete: true,
+		},
+		"taken.com": {
+			Registered: true,
+			CreatedAt:  time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+			ExpiresAt:  time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"mailinator.com": {
+			Registered: true,
+			CreatedAt:  time.Date(2003, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	r.Fail("flaky.com", errors.New("timeout"))
+
+	return NewAnalyzer(r, WithClock(func() time.Time { return now })), r
+}
+
+func TestAnalyze(t *testing.T) {
+	a, r := newTestAnalyzer()
+	maintainers := []Maintainer{
+		{Name: "active", Email: "active@mail.example.com"},
+		{Name: "expired", Email: "expired@expired.com"},
+		{Name: "deleting", Email: "deleting@deleting.com"},
+		{Name: "taken", Email: "taken@taken.com"},
+		{Name: "available", Email: "available@available.com"},
+		{Name: "free", Email: "free@gmail.com"},
+		{Name: "disposable", Email: "disposable@mailinator.com"},
+		{Name: "flaky", Email: "flaky@flaky.com"},
+		{Name: "invalid", Email: "invalid"},
+	}
+
+	got, err := a.Analyze(context.Background(), maintainers, published)
+	require.Nil(t, err)
+	require.Len(t, got, len(maintainers))
+
+	want := []struct {
+		domain string
+		kind   Kind
+		status Status
+		npm    verdictcode.Code
+		pypi   verdictcode.Code
+	}{
+		{"example.com", KindCustom, StatusActive, verdictcode.UNK, verdictcode.UNK},
+		{"expired.com", KindCustom, StatusExpired, verdictcode.MDN09, verdictcode.MDP09},
+		{"deleting.com", KindCustom, StatusExpired, verdictcode.MDN09, verdictcode.MDP09},
+		{"taken.com", KindCustom, StatusReregistered, verdictcode.MDN04, verdictcode.MDP04},
+		{"available.com", KindCustom, StatusUnregistered, verdictcode.MDN09, verdictcode.MDP09},
+		{"gmail.com", KindFree, StatusUnchecked, verdictcode.UNK, verdictcode.UNK},
+		{"mailinator.com", KindDisposable, StatusActive, verdictcode.MDN09, verdictcode.MDP09},
+		{"flaky.com", KindCustom, StatusUnknown, verdictcode.UNK, verdictcode.UNK},
+		{"", "", StatusUnknown, verdictcode.UNK, verdictcode.UNK},
+	}
+	for i, w := range want {
+		res := got[i]
+		assert.Equal(t, maintainers[i], res.Maintainer)
+		assert.Equal(t, w.domain, res.Domain, maintainers[i].Name)
+		assert.Equal(t, w.kind, res.Kind, maintainers[i].Name)
+		assert.Equal(t, w.status, res.Status, maintainers[i].Name)
+		assert.Equal(t, w.status == StatusUnknown, res.Err != nil, maintainers[i].Name)
+
+		for eco, code := range map[ecosystem.Ecosystem]verdictcode.Code{ecosystem.Npm: w.npm, ecosystem.Pypi: w.pypi} {
+			c, ok := res.Code(eco)
+			assert.Equal(t, code, c, maintainers[i].Name)
+			assert.Equal(t, code != verdictcode.UNK, ok, maintainers[i].Name)
+			if ok {
+				m, err := metadata.Encode(c, res.Metadata())
+				require.Nil(t, err)
+				assert.Equal(t, map[string]interface{}{
+					"name":   maintainers[i].Name,
+					"email":  maintainers[i].Email,
+					"domain": w.domain,
+				}, m)
+			}
+		}
+	}
+	assert.Zero(t, r.Lookups("gmail.com"))
+
+	_, ok := got[1].Code(ecosystem.None)
+	assert.False(t, ok)
+
+	// Anyone can read the inboxes of the disposable domains, even when their lookup fails
+	c, ok := Result{Kind: KindDisposable, Status: StatusUnknown}.Code(ecosystem.Npm)
+	assert.True(t, ok)
+	assert.Equal(t, verdictcode.MDN09, c)
+}
+
+func TestAnalyzeWithoutSince(t *testing.T) {
+	a, _ := newTestAnalyzer()
+	got, err := a.Analyze(context.Background(), []Maintainer{{Email: "taken@taken.com"}}, time.Time{})
+	require.Nil(t, err)
+	assert.Equal(t, StatusActive, got[0].Status)
+}
+
+func TestAnalyzeCache(t *testing.T) {
+	a, r := newTestAnalyzer()
+	for range 3 {
+		_, err := a.Analyze(context.Background(), []Maintainer{
+			{Email: "one@example.com"},
+			{Email: "two@EXAMPLE.com"},
+			{Email: "flaky@flaky.com"},
+		}, published)
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 1, r.Lookups("example.com"))
+	// The failures are not cached
+	assert.Equal(t, 3, r.Lookups("flaky.com"))
+}
+
+func TestAnalyzeCanceled(t *testing.T) {
+	a, _ := newTestAnalyzer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := a.Analyze(ctx, []Maintainer{{Email: "one@example.com"}}, published)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFromRegistries(t *testing.T) {
+	assert.Equal(t, []Maintainer{
+		{Name: "john", Email: "john@example.com"},
+	}, FromNPM(npm.PackageMaintainers{
+		{Name: "john", Mail: "john@example.com"},
+		{Name: "nobody", Mail: ""},
+	}))
+
+	assert.Equal(t, []Maintainer{
+		{Name: "John, Jane", Email: "john@example.com"},
+		{Name: "John, Jane", Email: "jane@example.org"},
+		{Name: "Team", Email: "team@example.net"},
+	}, FromPyPI(pypi.PackageMaintainers{
+		{Name: "John, Jane", Mail: "john@example.com, Jane <jane@example.org>", Type: pypi.PackageAuthorType},
+		{Name: "Team", Mail: "team@example.net", Type: pypi.PackageMaintainerType},
+	}))
+}
+
+func TestIndex(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("a", Maintainer{Email: "john@example.com"}, Maintainer{Email: "jane@example.org"})
+	idx.Add("b", Maintainer{Email: "John@Example.com"})
+	idx.Add("c", Maintainer{Email: "john@example.com"}, Maintainer{Email: "jane@example.org"}, Maintainer{Email: ""})
+	idx.Add("c", Maintainer{Email: "john@example.com"})
+	idx.Add("d", Maintainer{Email: "solo@example.net"})
+
+	assert.Equal(t, []string{"a", "b", "c"}, idx.Packages("JOHN@example.com"))
+	assert.Empty(t, idx.Packages("nobody@example.com"))
+
+	assert.Equal(t, []Shared{
+		{Email: "john@example.com", Domain: "example.com", Packages: []string{"a", "b", "c"}},
+		{Email: "jane@example.org", Domain: "example.org", Packages: []string{"a", "c"}},
+	}, idx.Shared(2))
+	assert.Len(t, idx.Shared(0), 3)
+	assert.Empty(t, idx.Shared(4))
+}
@@ -0,0 +1,89 @@
+package maintainer
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+//go:embed domains/*.txt
+var lists embed.FS
+
+// Kind is the kind of the provider of an email domain.
+type Kind string
+
+const (
+	// KindCustom is a domain someone registered for themselves (eg., their company)
+	KindCustom Kind = "custom"
+	// KindFree is the domain of a free email provider (eg., gmail.com)
+	KindFree Kind = "free"
+	// KindDisposable is the domain of a disposable email service, whose inboxes anyone can read
+	KindDisposable Kind = "disposable"
+)
+
+var ErrInvalidEmail = errors.New("invalid email")
+
+var (
+	disposable = load("domains/disposable.txt")
+	free       = load("domains/freemail.txt")
+)
+
+func load(name string) map[string]bool {
+	f, err := lists.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	res := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		res[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		panic(err)
+	}
+
+	return res
+}
+
+// Domain returns the registrable domain of an email address (eg., example.co.uk for john@mail.example.co.uk).
+//
+// It is the domain someone can register, thus the one whose expiration puts the address at risk.
+func Domain(email string) (string, error) {
+	_, host, ok := strings.Cut(strings.TrimSpace(email), "@")
+	if !ok || host == "" || strings.Contains(host, "@") {
+		return "", fmt.Errorf("%w %q", ErrInvalidEmail, email)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrInvalidEmail, email, err)
+	}
+
+	return domain, nil
+}
+
+// Classify returns the kind of the provider of the email domain.
+func Classify(domain string) Kind {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for d := domain; d != ""; {
+		switch {
+		case disposable[d]:
+			return KindDisposable
+		case free[d]:
+			return KindFree
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+
+	return KindCustom
+}
@@ -0,0 +1,44 @@
+package maintainer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomain(t *testing.T) {
+	cases := map[string]string{
+		"john@example.com":             "example.com",
+		"John@Mail.Example.COM":        "example.com",
+		"jane@dept.example.co.uk":      "example.co.uk",
+		" someone@example.org. ":       "example.org",
+		"a+b@users.noreply.github.com": "github.com",
+	}
+	for email, want := range cases {
+		got, err := Domain(email)
+		assert.Nil(t, err, email)
+		assert.Equal(t, want, got, email)
+	}
+
+	for _, email := range []string{"", "john", "john@", "john@com", "a@b@example.com"} {
+		_, err := Domain(email)
+		assert.ErrorIs(t, err, ErrInvalidEmail, email)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := map[string]Kind{
+		"gmail.com":         KindFree,
+		"GMail.com":         KindFree,
+		"yahoo.co.jp":       KindFree,
+		"mailinator.com":    KindDisposable,
+		"yopmail.fr":        KindDisposable,
+		"eu.mailinator.com": KindDisposable,
+		"example.com":       KindCustom,
+		"gmail.com.evil":    KindCustom,
+		"notgmail.com":      KindCustom,
+	}
+	for domain, want := range cases {
+		assert.Equal(t, want, Classify(domain), domain)
+	}
+}
@@ -0,0 +1,71 @@
+package maintainer
+
+import (
+	"slices"
+	"strings"
+)
+
+// Shared is a maintainer of many packages.
+//
+// The takeover of their account puts all of the packages at risk.
+type Shared struct {
+	Email    string
+	Domain   string
+	Packages []string
+}
+
+// Index maps the maintainers to the packages they maintain, by email address.
+type Index struct {
+	packages map[string]map[string]bool
+}
+
+func NewIndex() *Index {
+	return &Index{packages: map[string]map[string]bool{}}
+}
+
+// Add records the maintainers of the package.
+func (i *Index) Add(pkg string, maintainers ...Maintainer) {
+	for _, m := range maintainers {
+		email := strings.ToLower(strings.TrimSpace(m.Email))
+		if email == "" {
+			continue
+		}
+		if i.packages[email] == nil {
+			i.packages[email] = map[string]bool{}
+		}
+		i.packages[email][pkg] = true
+	}
+}
+
+// Packages returns the packages the email address maintains, sorted.
+func (i *Index) Packages(email string) []string {
+	res := []string{}
+	for p := range i.packages[strings.ToLower(strings.TrimSpace(email))] {
+		res = append(res, p)
+	}
+	slices.Sort(res)
+
+	return res
+}
+
+// Shared returns the maintainers of at least the given number of packages, the ones maintaining more first.
+func (i *Index) Shared(minPackages int) []Shared {
+	res := []Shared{}
+	for email, packages := range i.packages {
+		if len(packages) < max(minPackages, 1) {
+			continue
+		}
+		s := Shared{Email: email, Packages: i.Packages(email)}
+		s.Domain, _ = Domain(email)
+		res = append(res, s)
+	}
+	slices.SortFunc(res, func(a, b Shared) int {
+		if len(a.Packages) != len(b.Packages) {
+			return len(b.Packages) - len(a.Packages)
+		}
+
+		return strings.Compare(a.Email, b.Email)
+	})
+
+	return res
+}
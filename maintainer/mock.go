@@ -0,0 +1,61 @@
+package maintainer
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+var _ Resolver = (*MockResolver)(nil)
+
+// MockResolver looks up the registration of the domains from a map, the missing ones not being registered.
+type MockResolver struct {
+	domains map[string]Registration
+	errors  map[string]error
+
+	mu      sync.Mutex
+	lookups map[string]int
+}
+
+func NewMockResolver(domains map[string]Registration) *MockResolver {
+	res := &MockResolver{
+		domains: map[string]Registration{},
+		errors:  map[string]error{},
+		lookups: map[string]int{},
+	}
+	for d, r := range domains {
+		res.domains[strings.ToLower(d)] = r
+	}
+
+	return res
+}
+
+// Fail makes the lookups of the domain fail with the given error.
+func (r *MockResolver) Fail(domain string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors[strings.ToLower(domain)] = err
+}
+
+// Lookups returns how many times the domain was looked up.
+func (r *MockResolver) Lookups(domain string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lookups[strings.ToLower(domain)]
+}
+
+func (r *MockResolver) Lookup(ctx context.Context, domain string) (Registration, error) {
+	if err := ctx.Err(); err != nil {
+		return Registration{}, err
+	}
+	domain = strings.ToLower(domain)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups[domain]++
+	if err, ok := r.errors[domain]; ok {
+		return Registration{}, err
+	}
+
+	return r.domains[domain], nil
+}
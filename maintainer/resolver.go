@@ -0,0 +1,154 @@
+package maintainer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/listendev/pkg/observability/tracer"
+)
+
+var _ Resolver = (*RDAPResolver)(nil)
+
+const (
+	defaultRDAPBaseURL = "https://rdap.org"
+	defaultUserAgent   = "listendev/pkg/maintainer"
+)
+
+var (
+	ErrCouldNotDecodeResponse = errors.New("could not decode RDAP response")
+	ErrCouldNotDoRequest      = errors.New("could not start request to RDAP")
+	ErrCouldNotCreateRequest  = errors.New("could not create request to RDAP")
+)
+
+type ServiceError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+// Registration is the registration of a domain.
+type Registration struct {
+	Registered bool
+	// CreatedAt is when the domain was registered, zero when unknown
+	CreatedAt time.Time
+	// ExpiresAt is when the registration expires, zero when unknown
+	ExpiresAt time.Time
+	// PendingDelete tells whether the registry is about to release the domain
+	PendingDelete bool
+}
+
+// Resolver looks up the registration of the domains.
+type Resolver interface {
+	Lookup(ctx context.Context, domain string) (Registration, error)
+}
+
+// RDAPResolver looks up the registration of the domains with the Registration Data Access Protocol (RFC 9083).
+type RDAPResolver struct {
+	client    *http.Client
+	baseURL   *url.URL
+	userAgent string
+}
+
+type RDAPResolverConfig struct {
+	Timeout time.Duration
+	// BaseURL is the RDAP service, by default a bootstrap one redirecting to the registry of the domain
+	BaseURL   string
+	UserAgent string
+}
+
+func NewRDAPResolver(config RDAPResolverConfig) (*RDAPResolver, error) {
+	timeout := time.Second * 10
+	if config.Timeout != 0 {
+		timeout = config.Timeout
+	}
+	ua := defaultUserAgent
+	if len(config.UserAgent) > 0 {
+		ua = config.UserAgent
+	}
+	rdapURL := defaultRDAPBaseURL
+	if config.BaseURL != "" {
+		rdapURL = config.BaseURL
+	}
+	u, err := url.Parse(rdapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RDAPResolver{
+		client:    &http.Client{Timeout: timeout},
+		baseURL:   u,
+		userAgent: ua,
+	}, nil
+}
+
+type rdapDomain struct {
+	Status []string `json:"status"`
+	Events []struct {
+		Action string    `json:"eventAction"`
+		Date   time.Time `json:"eventDate"`
+	} `json:"events"`
+}
+
+// Lookup returns the registration of the domain.
+//
+// The domains the RDAP service does not find are not registered.
+func (r *RDAPResolver) Lookup(parent context.Context, domain string) (Registration, error) {
+	ctx, span := tracer.FromContext(parent).Start(parent, "RDAPResolver.Lookup")
+	defer span.End()
+	endpoint := r.baseURL.ResolveReference(&url.URL{Path: path.Join(r.baseURL.Path, "domain", domain)})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return Registration{}, errors.Join(ErrCouldNotCreateRequest, err)
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("Accept", "application/rdap+json")
+
+	response, err := r.client.Do(req)
+	if err != nil {
+		return Registration{}, errors.Join(ErrCouldNotDoRequest, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		if response.StatusCode == http.StatusNotFound {
+			return Registration{Registered: false}, nil
+		}
+
+		return Registration{}, &ServiceError{
+			StatusCode: response.StatusCode,
+			Message:    response.Status,
+		}
+	}
+
+	var d rdapDomain
+	if err := json.NewDecoder(response.Body).Decode(&d); err != nil {
+		return Registration{}, ErrCouldNotDecodeResponse
+	}
+	res := Registration{
+		Registered: true,
+		PendingDelete: slices.ContainsFunc(d.Status, func(s string) bool {
+			return strings.EqualFold(s, "pending delete") || strings.EqualFold(s, "redemption period")
+		}),
+	}
+	for _, e := range d.Events {
+		switch e.Action {
+		case "registration":
+			res.CreatedAt = e.Date
+		case "expiration":
+			res.ExpiresAt = e.Date
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,96 @@
+package maintainer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRDAPResolver_Lookup(t *testing.T) {
+	tests := []struct {
+		descr    string
+		domain   string
+		status   int
+		testFile string
+		want     Registration
+		wantErr  bool
+	}{
+		{
+			descr:    "registered domain",
+			domain:   "example.com",
+			status:   http.StatusOK,
+			testFile: "rdap_domain.json",
+			want: Registration{
+				Registered: true,
+				CreatedAt:  time.Date(1995, 8, 14, 4, 0, 0, 0, time.UTC),
+				ExpiresAt:  time.Date(2026, 8, 13, 4, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			descr:    "domain pending delete",
+			domain:   "expiring.com",
+			status:   http.StatusOK,
+			testFile: "rdap_domain_pending_delete.json",
+			want: Registration{
+				Registered:    true,
+				CreatedAt:     time.Date(2015, 3, 1, 10, 0, 0, 0, time.UTC),
+				ExpiresAt:     time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+				PendingDelete: true,
+			},
+		},
+		{
+			descr:  "unregistered domain",
+			domain: "available.com",
+			status: http.StatusNotFound,
+			want:   Registration{Registered: false},
+		},
+		{
+			descr:   "service error",
+			domain:  "example.com",
+			status:  http.StatusTooManyRequests,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.descr, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/rdap/domain/"+tt.domain, r.URL.Path)
+				assert.Equal(t, "test", r.Header.Get("User-Agent"))
+				w.Header().Set("Content-Type", "application/rdap+json")
+				w.WriteHeader(tt.status)
+				if tt.testFile == "" {
+					return
+				}
+				data, err := os.ReadFile(path.Join("testdata", tt.testFile))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := w.Write(data); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer ts.Close()
+
+			r, err := NewRDAPResolver(RDAPResolverConfig{BaseURL: ts.URL + "/rdap", UserAgent: "test"})
+			require.Nil(t, err)
+			got, err := r.Lookup(observability.NewNopContext(), tt.domain)
+			if tt.wantErr {
+				var serviceErr *ServiceError
+				if assert.ErrorAs(t, err, &serviceErr) {
+					assert.Equal(t, tt.status, serviceErr.StatusCode)
+				}
+
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
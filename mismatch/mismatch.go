@@ -0,0 +1,227 @@
+// Package mismatch compares the metadata of the npm packages in the registry with the package.json of their tarballs,
+// for the metadata mismatch verdicts (MDN05, MDN06, MDN07, MDN08).
+//
+// The registry serves the manifest the publisher sent, which nobody checks against the tarball:
+// a malicious publisher can hide install scripts or dependencies from the tools reading the registry.
+package mismatch
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Field is a field of the package.json the analyzer compares.
+type Field string
+
+const (
+	FieldName            Field = "name"
+	FieldVersion         Field = "version"
+	FieldScripts         Field = "scripts"
+	FieldDependencies    Field = "dependencies"
+	FieldDevDependencies Field = "devDependencies"
+	FieldBin             Field = "bin"
+	FieldEngines         Field = "engines"
+)
+
+// codes maps the fields to the codes of their verdicts.
+//
+// The mismatches of the other fields have no verdicts yet.
+var codes = map[Field]verdictcode.Code{
+	FieldName:            verdictcode.MDN05,
+	FieldScripts:         verdictcode.MDN06,
+	FieldDependencies:    verdictcode.MDN07,
+	FieldDevDependencies: verdictcode.MDN08,
+}
+
+// MaxPackageJSONSize is the maximum size in bytes of the package.json read from the tarballs.
+const MaxPackageJSONSize = 10 << 20
+
+var ErrNoPackageJSON = errors.New("couldn't find the package.json in the tarball")
+
+// Mismatch is a difference between the registry and the tarball of a package.
+type Mismatch struct {
+	Field Field
+	// Registry and Tarball are the values of the string fields (ie., name, and version)
+	Registry string
+	Tarball  string
+	// RegistryEntries and TarballEntries are the entries of the map fields that differ, keyed by name
+	RegistryEntries map[string]string
+	TarballEntries  map[string]string
+	// Added are the names of the entries only in the tarball, sorted
+	Added []string
+	// Removed are the names of the entries only in the registry, sorted
+	Removed []string
+	// Changed are the names of the entries with different values, sorted
+	Changed []string
+}
+
+// Code returns the code of the verdict about the mismatch, if any.
+func (m Mismatch) Code() (verdictcode.Code, bool) {
+	c, ok := codes[m.Field]
+
+	return c, ok
+}
+
+// Metadata returns the metadata of the verdict about the mismatch.
+//
+// It is a metadata.Mismatch[string] for the string fields, and a metadata.Mismatch[map[string]string] for the other ones.
+func (m Mismatch) Metadata() any {
+	if m.RegistryEntries == nil && m.TarballEntries == nil {
+		return metadata.Mismatch[string]{Registry: m.Registry, Tarball: m.Tarball}
+	}
+
+	return metadata.Mismatch[map[string]string]{Registry: m.RegistryEntries, Tarball: m.TarballEntries}
+}
+
+// Compare returns the differences between the metadata of a package version in the registry and the package.json of its tarball,
+// in the order of the fields.
+//
+// It ignores the differences npm itself introduces while publishing:
+// the cleaning of the version, the optional dependencies listed also as dependencies,
+// the paths and the names of the commands, and the install script of the native addons.
+func Compare(registry *npm.PackageVersion, tarball *npm.PackageJSON) []Mismatch {
+	res := []Mismatch{}
+	if registry.Name != tarball.Name {
+		res = append(res, Mismatch{Field: FieldName, Registry: registry.Name, Tarball: tarball.Name})
+	}
+	if !sameVersion(registry.Version, tarball.Version) {
+		res = append(res, Mismatch{Field: FieldVersion, Registry: registry.Version, Tarball: tarball.Version})
+	}
+
+	registryScripts := maps.Clone(registry.Scripts)
+	if registryScripts["install"] == gypInstall && tarball.Scripts["install"] == "" && tarball.Scripts["preinstall"] == "" {
+		delete(registryScripts, "install")
+	}
+	// The registry also lists the optional dependencies among the dependencies
+	tarballDependencies := maps.Clone(tarball.Dependencies)
+	if tarballDependencies == nil {
+		tarballDependencies = map[string]string{}
+	}
+	maps.Copy(tarballDependencies, tarball.OptionalDependencies)
+
+	for _, f := range []struct {
+		field             Field
+		registry, tarball map[string]string
+	}{
+		{FieldScripts, registryScripts, tarball.Scripts},
+		{FieldDependencies, registry.Dependencies, tarballDependencies},
+		{FieldDevDependencies, registry.DevDependencies, tarball.DevDependencies},
+		{FieldBin, registry.Bin.Commands(registry.Name), tarball.Bin.Commands(tarball.Name)},
+		{FieldEngines, registry.Engines, tarball.Engines},
+	} {
+		if m, ok := compareMaps(f.field, f.registry, f.tarball); ok {
+			res = append(res, m)
+		}
+	}
+
+	return res
+}
+
+// gypInstall is the install script npm adds to the packages with a binding.gyp file and without install scripts.
+const gypInstall = "node-gyp rebuild"
+
+func sameVersion(registry, tarball string) bool {
+	if registry == tarball {
+		return true
+	}
+	r, errR := semver.NewVersion(registry)
+	t, errT := semver.NewVersion(tarball)
+	if errR != nil || errT != nil {
+		return false
+	}
+
+	return r.Equal(t) && r.Metadata() == t.Metadata()
+}
+
+func compareMaps(field Field, registry, tarball map[string]string) (Mismatch, bool) {
+	m := Mismatch{
+		Field:           field,
+		RegistryEntries: map[string]string{},
+		TarballEntries:  map[string]string{},
+		Added:           []string{},
+		Removed:         []string{},
+		Changed:         []string{},
+	}
+	for k, rv := range registry {
+		tv, ok := tarball[k]
+		switch {
+		case !ok:
+			m.Removed = append(m.Removed, k)
+			m.RegistryEntries[k] = rv
+		case rv != tv:
+			m.Changed = append(m.Changed, k)
+			m.RegistryEntries[k] = rv
+			m.TarballEntries[k] = tv
+		}
+	}
+	for k, tv := range tarball {
+		if _, ok := registry[k]; !ok {
+			m.Added = append(m.Added, k)
+			m.TarballEntries[k] = tv
+		}
+	}
+	if len(m.Added)+len(m.Removed)+len(m.Changed) == 0 {
+		return Mismatch{}, false
+	}
+	slices.Sort(m.Added)
+	slices.Sort(m.Removed)
+	slices.Sort(m.Changed)
+
+	return m, true
+}
+
+// CompareTarball compares the metadata of a package version in the registry with the package.json in its tarball (see Compare).
+func CompareTarball(registry *npm.PackageVersion, tarball io.Reader) ([]Mismatch, error) {
+	p, err := PackageJSONFromTarball(tarball)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(registry, p), nil
+}
+
+// PackageJSONFromTarball reads the package.json at the root of the package in a gzipped tarball.
+//
+// The npm tarballs have the files of the package in a top directory, usually named package.
+func PackageJSONFromTarball(r io.Reader) (*npm.PackageJSON, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the tarball: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoPackageJSON
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the tarball: %w", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		dir, file := path.Split(path.Clean(strings.TrimPrefix(h.Name, "./")))
+		if file != "package.json" || dir == "" || strings.Contains(strings.TrimSuffix(dir, "/"), "/") {
+			continue
+		}
+		if h.Size > MaxPackageJSONSize {
+			return nil, fmt.Errorf("the package.json in the tarball is larger than %d bytes", MaxPackageJSONSize)
+		}
+
+		return npm.NewPackageJSONFromReader(io.LimitReader(tr, MaxPackageJSONSize))
+	}
+}
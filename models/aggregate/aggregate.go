@@ -0,0 +1,163 @@
+package aggregate
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// DefaultTop is the default number of verdicts a summary lists.
+const DefaultTop = 5
+
+// DefaultSeverityWeights are the weights the verdicts contribute to the risk score with,
+// unless their code has its own weight.
+var DefaultSeverityWeights = map[severity.Severity]float64{
+	severity.Critical: 25,
+	severity.High:     10,
+	severity.Medium:   4,
+	severity.Low:      1,
+}
+
+type Option func(*Aggregator)
+
+// WithWeights is an option to set the weights of the verdict codes.
+//
+// They take precedence over the severity weights.
+func WithWeights(weights map[verdictcode.Code]float64) Option {
+	return func(a *Aggregator) {
+		a.weights = weights
+	}
+}
+
+// WithSeverityWeights is an option to set the weights of the verdict severities.
+func WithSeverityWeights(weights map[severity.Severity]float64) Option {
+	return func(a *Aggregator) {
+		a.severityWeights = weights
+	}
+}
+
+// WithTop is an option to set how many verdicts a summary lists.
+func WithTop(n int) Option {
+	return func(a *Aggregator) {
+		a.top = n
+	}
+}
+
+// Aggregator summarizes the verdicts of every package version.
+type Aggregator struct {
+	weights         map[verdictcode.Code]float64
+	severityWeights map[severity.Severity]float64
+	top             int
+}
+
+func New(options ...Option) *Aggregator {
+	a := &Aggregator{
+		severityWeights: DefaultSeverityWeights,
+		top:             DefaultTop,
+	}
+	for _, opt := range options {
+		opt(a)
+	}
+
+	return a
+}
+
+// Weight returns the contribution of the given verdict to the risk score.
+func (a *Aggregator) Weight(v models.Verdict) float64 {
+	if w, ok := a.weights[v.Code]; ok {
+		return w
+	}
+
+	return a.severityWeights[v.Severity]
+}
+
+// Aggregate groups the verdicts by package version and summarizes them.
+//
+// The summaries are sorted by decreasing risk score, then by package.
+func (a *Aggregator) Aggregate(verdicts models.Verdicts) []Summary {
+	groups := map[string]*Summary{}
+	keys := []string{}
+	for _, v := range verdicts {
+		k := key(v.Ecosystem, v.Org, v.Pkg, v.Version)
+		s, ok := groups[k]
+		if !ok {
+			s = &Summary{
+				Ecosystem:  v.Ecosystem,
+				Org:        v.Org,
+				Pkg:        v.Pkg,
+				Version:    v.Version,
+				Severities: map[severity.Severity]int{},
+				Categories: map[string]int{},
+				Top:        models.Verdicts{},
+			}
+			groups[k] = s
+			keys = append(keys, k)
+		}
+		a.add(s, v)
+	}
+
+	ret := make([]Summary, 0, len(keys))
+	for _, k := range keys {
+		s := groups[k]
+		a.rank(s.Top)
+		if len(s.Top) > a.top {
+			s.Top = s.Top[:max(a.top, 0)]
+		}
+		ret = append(ret, *s)
+	}
+	slices.SortStableFunc(ret, func(x, y Summary) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(x.Key(), y.Key())
+	})
+
+	return ret
+}
+
+func (a *Aggregator) add(s *Summary, v models.Verdict) {
+	s.Total++
+	if v.Severity != severity.Empty {
+		s.Severities[v.Severity]++
+	}
+	for _, c := range v.Categories {
+		s.Categories[string(c.Case())]++
+	}
+	s.Score += a.Weight(v)
+	s.Top = append(s.Top, v)
+}
+
+// rank sorts the verdicts by decreasing weight, then by decreasing severity, then by code, file, and fingerprint.
+func (a *Aggregator) rank(verdicts models.Verdicts) {
+	slices.SortStableFunc(verdicts, func(x, y models.Verdict) int {
+		if c := cmp.Compare(a.Weight(y), a.Weight(x)); c != 0 {
+			return c
+		}
+		if c := y.Severity.Compare(x.Severity); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(x.Code, y.Code); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(x.File, y.File); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(x.Fingerprint, y.Fingerprint)
+	})
+}
+
+func key(eco ecosystem.Ecosystem, org, pkg, version string) string {
+	name := pkg
+	if org != "" {
+		name = org + "/" + pkg
+	}
+
+	return fmt.Sprintf("%s/%s@%s", eco.Case(), name, version)
+}
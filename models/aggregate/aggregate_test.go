@@ -0,0 +1,153 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/apispec"
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerdict(t *testing.T, org, pkg, version, file string, code verdictcode.Code, fingerprint string, sev severity.Severity, cats ...category.Category) models.Verdict {
+	t.Helper()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := models.Verdict{
+		Ecosystem:   ecosystem.Npm,
+		Org:         org,
+		Pkg:         pkg,
+		Version:     version,
+		Digest:      "d957f370038b75ac572471e83be4c5ca9f8e8c45",
+		File:        file,
+		CreatedAt:   &created,
+		Code:        code,
+		Fingerprint: fingerprint,
+		Message:     pkg + " " + code.String(),
+		Severity:    sev,
+		Categories:  cats,
+		Metadata:    map[string]interface{}{},
+	}
+	if sev == severity.Empty {
+		// Empty verdict
+		v.Message = ""
+	}
+	require.Nil(t, v.Validate())
+
+	return v
+}
+
+func fixtures(t *testing.T) (models.Verdicts, map[string]models.Verdict) {
+	t.Helper()
+
+	all := map[string]models.Verdict{
+		"typosquat":   newVerdict(t, "", "chalk", "5.1.2", "typosquat.json", verdictcode.TSN01, "", severity.Medium, category.Cybersquatting),
+		"install":     newVerdict(t, "", "chalk", "5.1.2", "dynamic!install!.json", verdictcode.FNI001, "a", severity.High, category.Process),
+		"network":     newVerdict(t, "", "chalk", "5.1.2", "dynamic!install!.json", verdictcode.FNI002, "b", severity.High, category.Network, category.Process),
+		"low":         newVerdict(t, "", "chalk", "5.1.2", "dynamic!install!.json", verdictcode.FNI003, "c", severity.Low, category.Filesystem),
+		"old":         newVerdict(t, "", "chalk", "5.1.1", "typosquat.json", verdictcode.TSN01, "", severity.Medium, category.Cybersquatting),
+		"devtools":    newVerdict(t, "@vue", "devtools", "6.5.0", "dynamic!install!.json", verdictcode.FNI001, "a", severity.High, category.Process),
+		"no severity": newVerdict(t, "@vue", "devtools", "6.5.0", "typosquat.json", verdictcode.UNK, "", severity.Empty),
+	}
+
+	return models.Verdicts{
+		all["typosquat"], all["install"], all["old"], all["devtools"], all["network"], all["low"], all["no severity"],
+	}, all
+}
+
+func TestAggregate(t *testing.T) {
+	verdicts, all := fixtures(t)
+
+	got := New().Aggregate(verdicts)
+	require.Len(t, got, 3)
+
+	chalk := got[0]
+	assert.Equal(t, "npm/chalk@5.1.2", chalk.Key())
+	assert.Equal(t, 4, chalk.Total)
+	assert.Equal(t, map[severity.Severity]int{severity.High: 2, severity.Medium: 1, severity.Low: 1}, chalk.Severities)
+	assert.Equal(t, map[string]int{"cybersquatting": 1, "process": 2, "network": 1, "filesystem": 1}, chalk.Categories)
+	assert.Equal(t, float64(25), chalk.Score)
+	assert.Equal(t, models.Verdicts{all["install"], all["network"], all["typosquat"], all["low"]}, chalk.Top)
+
+	devtools := got[1]
+	assert.Equal(t, "npm/@vue/devtools@6.5.0", devtools.Key())
+	assert.Equal(t, 2, devtools.Total)
+	assert.Equal(t, map[severity.Severity]int{severity.High: 1}, devtools.Severities)
+	assert.Equal(t, map[string]int{"process": 1}, devtools.Categories)
+	assert.Equal(t, float64(10), devtools.Score)
+	assert.Equal(t, models.Verdicts{all["devtools"], all["no severity"]}, devtools.Top)
+
+	old := got[2]
+	assert.Equal(t, "npm/chalk@5.1.1", old.Key())
+	assert.Equal(t, float64(4), old.Score)
+
+	assert.Empty(t, New().Aggregate(nil))
+}
+
+func TestAggregateOptions(t *testing.T) {
+	verdicts, all := fixtures(t)
+
+	a := New(
+		WithWeights(map[verdictcode.Code]float64{verdictcode.TSN01: 50, verdictcode.FNI003: 0}),
+		WithSeverityWeights(map[severity.Severity]float64{severity.High: 2}),
+		WithTop(2),
+	)
+	assert.Equal(t, float64(50), a.Weight(all["typosquat"]))
+	assert.Equal(t, float64(2), a.Weight(all["install"]))
+	assert.Equal(t, float64(0), a.Weight(all["low"]))
+
+	got := a.Aggregate(verdicts)
+	require.Len(t, got, 3)
+	assert.Equal(t, "npm/chalk@5.1.2", got[0].Key())
+	assert.Equal(t, float64(54), got[0].Score)
+	assert.Equal(t, 4, got[0].Total)
+	assert.Equal(t, models.Verdicts{all["typosquat"], all["install"]}, got[0].Top)
+	// Same score, sorted by key
+	assert.Equal(t, "npm/chalk@5.1.1", got[1].Key())
+	assert.Equal(t, float64(50), got[1].Score)
+	assert.Equal(t, "npm/@vue/devtools@6.5.0", got[2].Key())
+
+	none := New(WithTop(0)).Aggregate(verdicts)
+	assert.Empty(t, none[0].Top)
+}
+
+func TestSummaryInformationalEvent(t *testing.T) {
+	verdicts, _ := fixtures(t)
+	summary := New(WithTop(1)).Aggregate(verdicts)[0]
+
+	ghCtx := apispec.GitHubEventContext{Repository: "listendev/pkg", RunId: 42, RunAttempt: 1}
+	evt, err := summary.InformationalEvent(ghCtx)
+	require.Nil(t, err)
+	assert.Equal(t, "summary", evt.Type)
+	assert.Regexp(t, "^[0-9a-f]{64}$", evt.Data.UniqueId)
+	assert.Equal(t, SummaryHead{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2"}, evt.Data.Head)
+
+	data, err := json.Marshal(evt.Data)
+	require.Nil(t, err)
+	var decoded struct {
+		Head map[string]interface{} `json:"head"`
+		Body map[string]interface{} `json:"body"`
+	}
+	require.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]interface{}{"ecosystem": "npm", "pkg": "chalk", "version": "5.1.2"}, decoded.Head)
+	assert.Equal(t, float64(4), decoded.Body["total"])
+	assert.Equal(t, float64(25), decoded.Body["score"])
+	assert.Equal(t, map[string]interface{}{"high": float64(2), "medium": float64(1), "low": float64(1)}, decoded.Body["severities"])
+	assert.Len(t, decoded.Body["top"], 1)
+
+	// Same content and run, same unique ID
+	again, err := summary.InformationalEvent(ghCtx)
+	require.Nil(t, err)
+	assert.Equal(t, evt.Data.UniqueId, again.Data.UniqueId)
+
+	ghCtx.RunAttempt = 2
+	retry, err := summary.InformationalEvent(ghCtx)
+	require.Nil(t, err)
+	assert.NotEqual(t, evt.Data.UniqueId, retry.Data.UniqueId)
+}
@@ -0,0 +1,105 @@
+package aggregate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/listendev/pkg/apispec"
+	"github.com/listendev/pkg/ecosystem"
+	informationaltype "github.com/listendev/pkg/informational/type"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/type/int64string"
+	"github.com/listendev/pkg/validate"
+)
+
+// Summary summarizes the verdicts of a package version.
+type Summary struct {
+	Ecosystem ecosystem.Ecosystem `json:"ecosystem"`
+	Org       string              `json:"org,omitempty"`
+	Pkg       string              `json:"pkg"`
+	Version   string              `json:"version"`
+	// Total is the number of verdicts
+	Total int `json:"total"`
+	// Severities counts the verdicts by severity
+	Severities map[severity.Severity]int `json:"severities"`
+	// Categories counts the verdicts by category (snake case)
+	Categories map[string]int `json:"categories"`
+	// Score is the risk score, the sum of the weights of the verdicts
+	Score float64 `json:"score"`
+	// Top are the verdicts weighting the most
+	Top models.Verdicts `json:"top"`
+}
+
+// Key returns the package version the summary is about (eg., npm/@vue/devtools@6.5.0).
+func (s Summary) Key() string {
+	return key(s.Ecosystem, s.Org, s.Pkg, s.Version)
+}
+
+// SummaryHead is the head of the summary informational events.
+type SummaryHead struct {
+	Ecosystem ecosystem.Ecosystem `json:"ecosystem"`
+	Org       string              `json:"org,omitempty"`
+	Pkg       string              `json:"pkg"`
+	Version   string              `json:"version"`
+}
+
+// SummaryBody is the body of the summary informational events.
+type SummaryBody struct {
+	Total      int                       `json:"total"`
+	Severities map[severity.Severity]int `json:"severities"`
+	Categories map[string]int            `json:"categories"`
+	Score      float64                   `json:"score"`
+	Top        models.Verdicts           `json:"top"`
+}
+
+// InformationalEvent returns the summary informational event of the summary, in the given GitHub context.
+//
+// Its unique ID is the SHA-256 of its content and of the repository and of the workflow run it comes from.
+func (s Summary) InformationalEvent(ghCtx apispec.GitHubEventContext) (*apispec.InformationalEvent, error) {
+	evt := &apispec.InformationalEvent{
+		Type:          informationaltype.Summary.Case(),
+		GithubContext: ghCtx,
+	}
+	head := SummaryHead{
+		Ecosystem: s.Ecosystem,
+		Org:       s.Org,
+		Pkg:       s.Pkg,
+		Version:   s.Version,
+	}
+	body := SummaryBody{
+		Total:      s.Total,
+		Severities: s.Severities,
+		Categories: s.Categories,
+		Score:      s.Score,
+		Top:        s.Top,
+	}
+	if body.Top == nil {
+		body.Top = models.Verdicts{}
+	}
+
+	data, err := json.Marshal(struct {
+		Type       string                  `json:"type"`
+		Head       SummaryHead             `json:"head"`
+		Body       SummaryBody             `json:"body"`
+		Repository string                  `json:"repository"`
+		RunID      int64string.Int64String `json:"run_id"`
+		RunAttempt int64string.Int64String `json:"run_attempt"`
+	}{evt.Type, head, body, ghCtx.Repository, ghCtx.RunId, ghCtx.RunAttempt})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+
+	evt.Data.UniqueId = hex.EncodeToString(sum[:])
+	evt.Data.Head = head
+	evt.Data.Body = body
+
+	if errs := validate.Validate(evt); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return evt, nil
+}
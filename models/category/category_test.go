@@ -66,3 +66,43 @@ func TestUnmarshal(t *testing.T) {
 		assert.Equal(t, c, v2)
 	}
 }
+
+func TestScanValue(t *testing.T) {
+	for _, c := range all {
+		v, err := c.Value()
+		assert.Nil(t, err)
+		assert.Equal(t, string(c.Case()), v)
+
+		var fromString Category
+		assert.Nil(t, fromString.Scan(v))
+		assert.Equal(t, c, fromString)
+
+		var fromUint64 Category
+		assert.Nil(t, fromUint64.Scan(uint64(c)))
+		assert.Equal(t, c, fromUint64)
+	}
+
+	_, err := Category(0).Value()
+	assert.Error(t, err)
+
+	var c Category
+	assert.Error(t, c.Scan(3.14))
+	assert.Error(t, c.Scan("nope"))
+}
+
+func TestHierarchy(t *testing.T) {
+	p, ok := AdjacentNetwork.Parent()
+	assert.True(t, ok)
+	assert.Equal(t, Network, p)
+	_, ok = Network.Parent()
+	assert.False(t, ok)
+
+	assert.Equal(t, []Category{AdjacentNetwork}, Network.Children())
+	assert.Empty(t, AdjacentNetwork.Children())
+
+	assert.True(t, AdjacentNetwork.Is(Network))
+	assert.True(t, AdjacentNetwork.Is(AdjacentNetwork))
+	assert.True(t, Network.Is(Network))
+	assert.False(t, Network.Is(AdjacentNetwork))
+	assert.False(t, Process.Is(Network))
+}
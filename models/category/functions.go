@@ -1,6 +1,7 @@
 package category
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -61,11 +62,57 @@ func (c *Category) Scan(source any) error {
 			return err
 		}
 		*c = cat
+
+		return nil
 	}
 
 	return fmt.Errorf("cannot scan %T into Category", source)
 }
 
+// Value implements the driver.Valuer interface.
+func (c Category) Value() (driver.Value, error) {
+	if _, err := FromUint64(uint64(c)); err != nil {
+		return nil, err
+	}
+
+	return string(c.Case()), nil
+}
+
+// parents maps the categories to the broader categories including them.
+var parents = map[Category]Category{
+	AdjacentNetwork: Network,
+}
+
+// Parent returns the broader category including the receiving one, if any.
+func (c Category) Parent() (Category, bool) {
+	p, ok := parents[c]
+
+	return p, ok
+}
+
+// Children returns the categories the receiving one directly includes.
+func (c Category) Children() []Category {
+	res := []Category{}
+	for _, x := range all {
+		if p, ok := parents[x]; ok && p == c {
+			res = append(res, x)
+		}
+	}
+
+	return res
+}
+
+// Is tells whether the receiving category is the other one or it is included in it (eg., adjacent network is network).
+func (c Category) Is(other Category) bool {
+	for x, ok := c, true; ok; x, ok = x.Parent() {
+		if x == other {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *Category) UnmarshalJSON(data []byte) error {
 	var str string
 	if err := json.Unmarshal(data, &str); err != nil {
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// CompiledFilter is a boolean expression parsed once and evaluated directly on every verdict.
+//
+// The expression refers to the verdict fields by their names (eg., severity, file, metadata.npm_package_name).
+// It can use the operators and the functions of the JSONPath filters (but the $ and @ prefixes),
+// plus the following functions about the current verdict:
+//
+//	severity_gte("medium"): its severity is at least the given one.
+//	has_category("network"): it has the given category, or one it includes (eg., adjacent network).
+//	code_in("FNI001", "MDN05"): its code is one of the given ones.
+//	age("24h"): it was created in the given duration (eg., 90m, 24h, 7d).
+//
+// The ecosystem, code, severity, status (open when empty), and categories fields evaluate to their string forms,
+// while the created_at and expires_at fields evaluate to their RFC3339 string forms in UTC, or nil.
+type CompiledFilter struct {
+	expression string
+	eval       gval.Evaluable
+}
+
+// CompileFilter parses the expression into a CompiledFilter.
+func CompileFilter(c context.Context, expression string) (*CompiledFilter, error) {
+	eval, err := filterLang.NewEvaluableWithContext(c, expression)
+	if err != nil {
+		return nil, &FilterParsingError{message: err.Error()}
+	}
+
+	return &CompiledFilter{expression: expression, eval: eval}, nil
+}
+
+// String returns the expression of the filter.
+func (f *CompiledFilter) String() string {
+	return f.expression
+}
+
+// Match tells whether the verdict satisfies the filter.
+func (f *CompiledFilter) Match(c context.Context, v *Verdict) (bool, error) {
+	s := &verdictScope{verdict: v, now: time.Now()}
+
+	return f.eval.EvalBool(context.WithValue(c, verdictScopeKey{}, s), s)
+}
+
+// Filter returns the verdicts satisfying the filter, preserving their order.
+func (f *CompiledFilter) Filter(c context.Context, verdicts Verdicts) (Verdicts, error) {
+	// Reusing the same scope for all the verdicts avoids allocating it (and its context) for each of them
+	s := &verdictScope{now: time.Now()}
+	c = context.WithValue(c, verdictScopeKey{}, s)
+	res := Verdicts{}
+	for i := range verdicts {
+		s.verdict = &verdicts[i]
+		ok, err := f.eval.EvalBool(c, s)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't filter verdict #%d: %w", i, err)
+		}
+		if ok {
+			res = append(res, verdicts[i])
+		}
+	}
+
+	return res, nil
+}
+
+type verdictScopeKey struct{}
+
+// verdictScope is the verdict a CompiledFilter is evaluating.
+type verdictScope struct {
+	verdict *Verdict
+	now     time.Time
+}
+
+// verdictFields maps the names (ch tags) of the verdict fields to their indices.
+var verdictFields = func() map[string]int {
+	t := reflect.TypeOf(Verdict{})
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("ch"); name != "" {
+			fields[name] = i
+		}
+	}
+
+	return fields
+}()
+
+// SelectGVal implements the gval.Selector interface.
+func (s *verdictScope) SelectGVal(_ context.Context, key string) (interface{}, error) {
+	i, ok := verdictFields[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown verdict field %q", key)
+	}
+
+	switch x := reflect.ValueOf(s.verdict).Elem().Field(i).Interface().(type) {
+	case ecosystem.Ecosystem:
+		return x.Case(), nil
+	case verdictcode.Code:
+		return x.String(), nil
+	case severity.Severity:
+		return x.String(), nil
+	case status.Status:
+		return x.Current().String(), nil
+	case []category.Category:
+		res := make([]interface{}, len(x))
+		for j, c := range x {
+			res[j] = string(c.Case())
+		}
+
+		return res, nil
+	case *time.Time:
+		if x == nil {
+			return nil, nil
+		}
+
+		return x.UTC().Format(time.RFC3339Nano), nil
+	default:
+		return x, nil
+	}
+}
+
+func scopeFrom(c context.Context) (*verdictScope, error) {
+	s, ok := c.Value(verdictScopeKey{}).(*verdictScope)
+	if !ok {
+		return nil, fmt.Errorf("no verdict to evaluate")
+	}
+
+	return s, nil
+}
+
+func stringArgument(fn string, args []interface{}) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("%s() expects exactly one string argument", fn)
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("%s() expects exactly one string argument", fn)
+	}
+
+	return s, nil
+}
+
+func severityGte(c context.Context, args ...interface{}) (interface{}, error) {
+	s, err := scopeFrom(c)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := stringArgument("severity_gte", args)
+	if err != nil {
+		return nil, err
+	}
+	min, err := severity.New(arg)
+	if err != nil {
+		return nil, fmt.Errorf("severity_gte(): %w", err)
+	}
+
+	return s.verdict.Severity.AtLeast(min), nil
+}
+
+func hasCategory(c context.Context, args ...interface{}) (interface{}, error) {
+	s, err := scopeFrom(c)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := stringArgument("has_category", args)
+	if err != nil {
+		return nil, err
+	}
+	want, err := category.FromString(arg)
+	if err != nil {
+		return nil, fmt.Errorf("has_category(): %w", err)
+	}
+	for _, cat := range s.verdict.Categories {
+		if cat.Is(want) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func codeIn(c context.Context, args ...interface{}) (interface{}, error) {
+	s, err := scopeFrom(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("code_in() expects at least one string argument")
+	}
+	code := s.verdict.Code.String()
+	for _, arg := range args {
+		str, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("code_in() expects string arguments")
+		}
+		if str == code {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func age(c context.Context, args ...interface{}) (interface{}, error) {
+	s, err := scopeFrom(c)
+	if err != nil {
+		return nil, err
+	}
+	arg, err := stringArgument("age", args)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDuration(arg)
+	if err != nil {
+		return nil, fmt.Errorf("age(): %w", err)
+	}
+	if s.verdict.CreatedAt == nil {
+		return false, nil
+	}
+
+	return s.now.Sub(*s.verdict.CreatedAt) <= d, nil
+}
+
+// parseDuration parses the durations as time.ParseDuration does, plus the ones in days (eg., 7d).
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(s)
+}
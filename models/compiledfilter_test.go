@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compiledFilterVerdicts() Verdicts {
+	ago := func(d time.Duration) *time.Time {
+		t := time.Now().Add(-d)
+
+		return &t
+	}
+
+	return Verdicts{
+		{
+			CreatedAt:  ago(time.Hour),
+			Ecosystem:  ecosystem.Npm,
+			Pkg:        "darcyclarke-manifest-pkg",
+			Version:    "2.1.15",
+			Digest:     "429eced1773fbc9ceea5cebda8338c0aaa21eeec",
+			File:       "metadata(mismatches).json",
+			Message:    "This package has inconsistent name in the tarball's package.json",
+			Severity:   severity.High,
+			Code:       verdictcode.MDN05,
+			Categories: []category.Category{category.Metadata},
+		},
+		{
+			CreatedAt:   ago(48 * time.Hour),
+			Ecosystem:   ecosystem.Npm,
+			Org:         "@vue",
+			Pkg:         "devtools",
+			Version:     "6.5.0",
+			Digest:      "0123456789012345678901234567890123456789",
+			File:        "dynamic!install!.json",
+			Message:     "connection to 1.1.1.1",
+			Fingerprint: "something",
+			Metadata: map[string]interface{}{
+				NPMPackageNameMetadataKey: "electron",
+			},
+			Severity:   severity.Medium,
+			Code:       verdictcode.FNI002,
+			Categories: []category.Category{category.AdjacentNetwork, category.CIS},
+		},
+		{
+			CreatedAt:   ago(10 * 24 * time.Hour),
+			ExpiresAt:   ago(-time.Hour),
+			Ecosystem:   ecosystem.Npm,
+			Pkg:         "in",
+			Version:     "4.0.0",
+			Digest:      "42b09b0e3ca7b757802f9a0b9ded8c2035ce7874",
+			File:        "dynamic!install!.json",
+			Message:     "spawn",
+			Fingerprint: "something",
+			Metadata: map[string]interface{}{
+				NPMPackageNameMetadataKey: "electronx",
+			},
+			Severity:   severity.Low,
+			Code:       verdictcode.FNI003,
+			Categories: []category.Category{category.Network, category.Process},
+		},
+	}
+}
+
+func TestCompiledFilter(t *testing.T) {
+	verdicts := compiledFilterVerdicts()
+
+	cases := []struct {
+		filter string
+		want   []int
+	}{
+		{`true`, []int{0, 1, 2}},
+		{`severity_gte("medium")`, []int{0, 1}},
+		{`severity_gte("low")`, []int{0, 1, 2}},
+		{`severity_gte("HIGH")`, []int{0}},
+		{`severity_gte("critical")`, []int{}},
+		{`severity_gte("info")`, []int{0, 1, 2}},
+		{`has_category("network")`, []int{1, 2}},
+		{`has_category("adjacent network")`, []int{1}},
+		{`has_category("adjacent network") || has_category("metadata")`, []int{0, 1}},
+		{`code_in("FNI001", "FNI002", "MDN05")`, []int{0, 1}},
+		{`!code_in("FNI002")`, []int{0, 2}},
+		{`age("24h")`, []int{0}},
+		{`age("7d")`, []int{0, 1}},
+		{`severity != "low" && file =~ "^dynamic"`, []int{1}},
+		{`ecosystem == "npm" && org == "@vue"`, []int{1}},
+		{`code == "FNI003"`, []int{2}},
+		{`"cis" in categories`, []int{1}},
+		{`metadata.npm_package_name == "electronx"`, []int{2}},
+		{`(expires_at ?? "") == ""`, []int{0, 1}},
+		{`expires_at ? expires_at > created_at : false`, []int{2}},
+	}
+	for _, tc := range cases {
+		f, err := CompileFilter(context.TODO(), tc.filter)
+		require.Nil(t, err, tc.filter)
+		assert.Equal(t, tc.filter, f.String())
+
+		got, err := f.Filter(context.TODO(), verdicts)
+		require.Nil(t, err, tc.filter)
+		want := Verdicts{}
+		for _, i := range tc.want {
+			want = append(want, verdicts[i])
+		}
+		assert.Equal(t, want, got, tc.filter)
+
+		for i := range verdicts {
+			ok, err := f.Match(context.TODO(), &verdicts[i])
+			require.Nil(t, err, tc.filter)
+			assert.Equal(t, contains(tc.want, i), ok, tc.filter)
+		}
+	}
+}
+
+func contains(s []int, x int) bool {
+	for _, y := range s {
+		if y == x {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCompiledFilterErrors(t *testing.T) {
+	_, err := CompileFilter(context.TODO(), `severity ==`)
+	target := &FilterParsingError{}
+	assert.ErrorAs(t, err, &target)
+
+	verdicts := compiledFilterVerdicts()
+	for _, filter := range []string{
+		`unknown == "x"`,
+		`severity_gte("urgent")`,
+		`severity_gte()`,
+		`has_category("nope")`,
+		`code_in()`,
+		`code_in(1)`,
+		`age("yesterday")`,
+		`age("xd")`,
+	} {
+		f, err := CompileFilter(context.TODO(), filter)
+		require.Nil(t, err, filter)
+		_, err = f.Filter(context.TODO(), verdicts)
+		assert.Error(t, err, filter)
+	}
+}
+
+func BenchmarkCompiledFilter(b *testing.B) {
+	base := compiledFilterVerdicts()
+	verdicts := make(Verdicts, 0, 100000)
+	for len(verdicts) < cap(verdicts) {
+		verdicts = append(verdicts, base...)
+	}
+	verdicts = verdicts[:cap(verdicts)]
+	f, err := CompileFilter(context.TODO(), `severity_gte("medium") && has_category("network") || code_in("MDN05")`)
+	require.Nil(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Filter(context.TODO(), verdicts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
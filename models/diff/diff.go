@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/listendev/pkg/models"
+)
+
+// Escalation is a verdict persisting across the versions whose severity increased.
+type Escalation struct {
+	From models.Verdict `json:"from"`
+	To   models.Verdict `json:"to"`
+}
+
+// Diff is the difference between the verdicts of two versions of a package.
+type Diff struct {
+	// New are the verdicts of the newer version not matching any verdict of the older one
+	New models.Verdicts `json:"new"`
+	// Resolved are the verdicts of the older version not matching any verdict of the newer one
+	Resolved models.Verdicts `json:"resolved"`
+	// Unchanged are the verdicts of the newer version matching a verdict of the older one with the same or a lower severity
+	Unchanged models.Verdicts `json:"unchanged"`
+	// Escalations are the verdicts matching across the versions whose severity increased
+	Escalations []Escalation `json:"escalations"`
+}
+
+// Empty tells whether the verdicts did not change.
+func (d *Diff) Empty() bool {
+	return len(d.New) == 0 && len(d.Resolved) == 0 && len(d.Escalations) == 0
+}
+
+// Compute returns the difference between the verdicts of an older version (from) and those of a newer version (to).
+//
+// It matches the verdicts by code and by content fingerprint (see models.Verdict.ComputeContentFingerprint),
+// which does not depend on the package version.
+// It ignores the Fingerprint of the verdicts since it is their canonical one (see models.Verdict.ComputeFingerprint),
+// which covers the version and the digest of the package, so it never matches across versions.
+// The content fingerprint only covers the metadata distinguishing the findings (see models.FingerprintFields),
+// so the verdicts whose incidental metadata (eg., the downloads of the typosquatted package) changed still match.
+// The verdicts matching more than once pair in order.
+// The verdicts keep the order they have in their input.
+func Compute(from, to models.Verdicts) (*Diff, error) {
+	older := map[string][]int{}
+	for i, v := range from {
+		k, err := matchKey(v)
+		if err != nil {
+			return nil, err
+		}
+		older[k] = append(older[k], i)
+	}
+
+	ret := &Diff{
+		New:         models.Verdicts{},
+		Resolved:    models.Verdicts{},
+		Unchanged:   models.Verdicts{},
+		Escalations: []Escalation{},
+	}
+	matched := make([]bool, len(from))
+	for _, v := range to {
+		k, err := matchKey(v)
+		if err != nil {
+			return nil, err
+		}
+		candidates := older[k]
+		if len(candidates) == 0 {
+			ret.New = append(ret.New, v)
+
+			continue
+		}
+		i := candidates[0]
+		older[k] = candidates[1:]
+		matched[i] = true
+
+		if v.Severity.Higher(from[i].Severity) {
+			ret.Escalations = append(ret.Escalations, Escalation{From: from[i], To: v})
+
+			continue
+		}
+		ret.Unchanged = append(ret.Unchanged, v)
+	}
+	for i, v := range from {
+		if !matched[i] {
+			ret.Resolved = append(ret.Resolved, v)
+		}
+	}
+
+	return ret, nil
+}
+
+func matchKey(v models.Verdict) (string, error) {
+	f, err := v.ComputeContentFingerprint()
+	if err != nil {
+		return "", fmt.Errorf("couldn't match verdict %s: %w", v.Code, err)
+	}
+
+	return fmt.Sprintf("%s#%s", v.Code, f), nil
+}
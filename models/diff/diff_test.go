@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerdict(t *testing.T, version, digest, file string, code verdictcode.Code, sev severity.Severity, metadata map[string]interface{}) models.Verdict {
+	t.Helper()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := models.Verdict{
+		Ecosystem:  ecosystem.Npm,
+		Pkg:        "chalk",
+		Version:    version,
+		Digest:     digest,
+		File:       file,
+		CreatedAt:  &created,
+		Code:       code,
+		Message:    "chalk " + code.String(),
+		Severity:   sev,
+		Categories: []category.Category{category.Process},
+		Metadata:   metadata,
+	}
+	require.Nil(t, v.SetFingerprint())
+	require.Nil(t, v.Validate())
+
+	return v
+}
+
+const (
+	oldDigest = "d957f370038b75ac572471e83be4c5ca9f8e8c45"
+	newDigest = "a4d61b9f0a1f1c7bbb4bea4d1e0ea9d3c5d3e3b1"
+)
+
+func process(path string) map[string]interface{} {
+	return map[string]interface{}{
+		"executable_path": path,
+		"commandline":     path + " -c install",
+		"parent_name":     "node",
+	}
+}
+
+func TestCompute(t *testing.T) {
+	from := models.Verdicts{
+		newVerdict(t, "5.1.2", oldDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, nil),
+		newVerdict(t, "5.1.2", oldDigest, "dynamic!install!.json", verdictcode.FNI001, severity.Low, process("/bin/sh")),
+		newVerdict(t, "5.1.2", oldDigest, "dynamic!install!.json", verdictcode.FNI001, severity.High, process("/bin/bash")),
+		newVerdict(t, "5.1.2", oldDigest, "dynamic!install!.json", verdictcode.FNI001, severity.High, process("/usr/bin/curl")),
+	}
+	to := models.Verdicts{
+		newVerdict(t, "5.2.0", newDigest, "dynamic!install!.json", verdictcode.FNI001, severity.High, process("/bin/bash")),
+		newVerdict(t, "5.2.0", newDigest, "dynamic!install!.json", verdictcode.FNI001, severity.High, process("/bin/sh")),
+		newVerdict(t, "5.2.0", newDigest, "dynamic!install!.json", verdictcode.FNI002, severity.High, map[string]interface{}{"executable_path": "/bin/sh"}),
+		newVerdict(t, "5.2.0", newDigest, "typosquat.json", verdictcode.TSN01, severity.Low, nil),
+	}
+
+	got, err := Compute(from, to)
+	require.Nil(t, err)
+	assert.False(t, got.Empty())
+	assert.Equal(t, models.Verdicts{to[2]}, got.New)
+	assert.Equal(t, models.Verdicts{from[3]}, got.Resolved)
+	// The typosquat verdict got less severe
+	assert.Equal(t, models.Verdicts{to[0], to[3]}, got.Unchanged)
+	assert.Equal(t, []Escalation{{From: from[1], To: to[1]}}, got.Escalations)
+
+	same, err := Compute(from, from)
+	require.Nil(t, err)
+	assert.True(t, same.Empty())
+	assert.Equal(t, from, same.Unchanged)
+
+	none, err := Compute(nil, nil)
+	require.Nil(t, err)
+	assert.True(t, none.Empty())
+	assert.Empty(t, none.Unchanged)
+}
+
+func TestComputeIncidentalMetadata(t *testing.T) {
+	from := models.Verdicts{
+		newVerdict(t, "5.1.2", oldDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, map[string]interface{}{
+			"target": "chalk-js", "distance": 1, "downloads": 1000,
+		}),
+		newVerdict(t, "5.1.2", oldDigest, "metadata(mismatches).json", verdictcode.MDN06, severity.High, map[string]interface{}{
+			"registry": map[string]interface{}{}, "tarball": map[string]interface{}{"preinstall": "node setup.js"},
+		}),
+	}
+	to := models.Verdicts{
+		newVerdict(t, "5.2.0", newDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, map[string]interface{}{
+			"target": "chalk-js", "distance": 1, "downloads": 2500,
+		}),
+		newVerdict(t, "5.2.0", newDigest, "metadata(mismatches).json", verdictcode.MDN06, severity.High, map[string]interface{}{
+			"tarball": map[string]interface{}{"preinstall": "node setup.js"}, "registry": map[string]interface{}{}, "checked": true,
+		}),
+		newVerdict(t, "5.2.0", newDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, map[string]interface{}{
+			"target": "chalks", "distance": 1, "downloads": 2500,
+		}),
+	}
+
+	// Only the popularity of the typosquatted package, and metadata not distinguishing the mismatch, changed
+	got, err := Compute(from, to)
+	require.Nil(t, err)
+	assert.Equal(t, models.Verdicts{to[0], to[1]}, got.Unchanged)
+	assert.Equal(t, models.Verdicts{to[2]}, got.New)
+	assert.Empty(t, got.Resolved)
+	assert.Empty(t, got.Escalations)
+}
+
+func TestComputeDuplicates(t *testing.T) {
+	twice := newVerdict(t, "5.1.2", oldDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, nil)
+	once := newVerdict(t, "5.2.0", newDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, nil)
+
+	got, err := Compute(models.Verdicts{twice, twice}, models.Verdicts{once})
+	require.Nil(t, err)
+	assert.Equal(t, models.Verdicts{once}, got.Unchanged)
+	assert.Equal(t, models.Verdicts{twice}, got.Resolved)
+}
+
+func TestComputeError(t *testing.T) {
+	v := newVerdict(t, "5.1.2", oldDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, nil)
+	v.Metadata = map[string]interface{}{"target": make(chan int)}
+
+	_, err := Compute(models.Verdicts{v}, nil)
+	assert.Error(t, err)
+	_, err = Compute(nil, models.Verdicts{v})
+	assert.Error(t, err)
+}
+
+func TestMarkdown(t *testing.T) {
+	from := models.Verdicts{
+		newVerdict(t, "5.1.2", oldDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, nil),
+		newVerdict(t, "5.1.2", oldDigest, "dynamic!install!.json", verdictcode.FNI001, severity.Low, process("/bin/sh")),
+		newVerdict(t, "5.1.2", oldDigest, "dynamic!install!.json", verdictcode.FNI001, severity.High, process("/usr/bin/curl")),
+	}
+	to := models.Verdicts{
+		newVerdict(t, "5.2.0", newDigest, "typosquat.json", verdictcode.TSN01, severity.Medium, nil),
+		newVerdict(t, "5.2.0", newDigest, "dynamic!install!.json", verdictcode.FNI001, severity.High, process("/bin/sh")),
+		newVerdict(t, "5.2.0", newDigest, "dynamic!install!.json", verdictcode.FNI002, severity.High, map[string]interface{}{"executable_path": "/bin/sh"}),
+	}
+	to[2].Message = "connects to | somewhere\nelse"
+
+	got, err := Compute(from, to)
+	require.Nil(t, err)
+
+	want := `| Status | Code | Severity | Message |
+| --- | --- | --- | --- |
+| new | FNI002 | high | connects to \| somewhere else |
+| escalated | FNI001 | low → high | chalk FNI001 |
+| resolved | FNI001 | high | chalk FNI001 |
+| unchanged | TSN01 | medium | chalk TSN01 |
+`
+	assert.Equal(t, want, got.Markdown())
+}
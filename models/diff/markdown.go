@@ -0,0 +1,71 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Those are the statuses of the verdicts in the markdown table.
+const (
+	StatusNew       = "new"
+	StatusEscalated = "escalated"
+	StatusResolved  = "resolved"
+	StatusUnchanged = "unchanged"
+)
+
+var markdownReplacer = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ", "\r", " ")
+
+// Markdown returns the diff as a markdown table suitable for pull request comments.
+//
+// It lists the new verdicts, the escalated ones, the resolved ones, and then the unchanged ones.
+func (d *Diff) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("| Status | Code | Severity | Message |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	row := func(status string, v models.Verdict, sev string) {
+		b.WriteString("| ")
+		b.WriteString(status)
+		b.WriteString(" | ")
+		b.WriteString(codeOf(v))
+		b.WriteString(" | ")
+		b.WriteString(sev)
+		b.WriteString(" | ")
+		b.WriteString(markdownReplacer.Replace(v.Message))
+		b.WriteString(" |\n")
+	}
+	for _, v := range d.New {
+		row(StatusNew, v, severityOf(v.Severity))
+	}
+	for _, e := range d.Escalations {
+		row(StatusEscalated, e.To, severityOf(e.From.Severity)+" → "+severityOf(e.To.Severity))
+	}
+	for _, v := range d.Resolved {
+		row(StatusResolved, v, severityOf(v.Severity))
+	}
+	for _, v := range d.Unchanged {
+		row(StatusUnchanged, v, severityOf(v.Severity))
+	}
+
+	return b.String()
+}
+
+func severityOf(s severity.Severity) string {
+	if s == severity.Empty {
+		return "-"
+	}
+
+	return s.String()
+}
+
+// codeOf returns the code of the verdict, or a dash for the empty verdicts.
+func codeOf(v models.Verdict) string {
+	if v.Code == verdictcode.UNK {
+		return "-"
+	}
+
+	return v.Code.String()
+}
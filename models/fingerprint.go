@@ -0,0 +1,330 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/listendev/pkg/verdictcode"
+	"golang.org/x/exp/maps"
+)
+
+// Those are the metadata fields the fingerprints of the verdicts are made of.
+//
+// The dotted ones refer to nested fields.
+const (
+	FingerprintFieldFile           = "file"
+	FingerprintFieldLine           = "start.line"
+	FingerprintFieldDomain         = "domain"
+	FingerprintFieldIP             = "ip"
+	FingerprintFieldURL            = "url"
+	FingerprintFieldDependency     = "dependency"
+	FingerprintFieldExecutablePath = "executable_path"
+	FingerprintFieldCommandline    = "commandline"
+	FingerprintFieldParentName     = "parent_name"
+	FingerprintFieldServerIP       = "server_ip"
+	FingerprintFieldServerPort     = "server_port"
+	FingerprintFieldEmail          = "email"
+	FingerprintFieldID             = "id"
+	FingerprintFieldTarget         = "target"
+	FingerprintFieldRegistry       = "registry"
+	FingerprintFieldTarball        = "tarball"
+)
+
+var (
+	staticFingerprintFields     = []string{FingerprintFieldFile, FingerprintFieldLine}
+	linkFingerprintFields       = []string{FingerprintFieldFile, FingerprintFieldDomain}
+	ipFingerprintFields         = []string{FingerprintFieldFile, FingerprintFieldIP}
+	dependencyFingerprintFields = []string{FingerprintFieldDependency, FingerprintFieldURL}
+	processFingerprintFields    = []string{FingerprintFieldExecutablePath, FingerprintFieldCommandline, FingerprintFieldParentName}
+	networkFingerprintFields    = []string{FingerprintFieldExecutablePath, FingerprintFieldServerIP, FingerprintFieldServerPort}
+	fileFingerprintFields       = []string{FingerprintFieldExecutablePath, FingerprintFieldFile}
+	emailFingerprintFields      = []string{FingerprintFieldEmail}
+	// The popularity of the typosquatted package changes over time
+	typosquatFingerprintFields = []string{FingerprintFieldTarget}
+	mismatchFingerprintFields  = []string{FingerprintFieldRegistry, FingerprintFieldTarball}
+)
+
+// fingerprintFields maps the codes to the metadata fields distinguishing their verdicts.
+//
+// The verdicts of the codes not listed here use all their metadata.
+var fingerprintFields = map[verdictcode.Code][]string{
+	verdictcode.FNI001: processFingerprintFields,
+	verdictcode.FNI002: networkFingerprintFields,
+	verdictcode.FNI003: networkFingerprintFields,
+	verdictcode.FNI004: fileFingerprintFields,
+	verdictcode.FNI005: fileFingerprintFields,
+	verdictcode.FNI006: fileFingerprintFields,
+	verdictcode.FNI007: fileFingerprintFields,
+	verdictcode.FNI008: fileFingerprintFields,
+	verdictcode.RUN001: {FingerprintFieldExecutablePath, FingerprintFieldDomain},
+	verdictcode.DDN01:  {FingerprintFieldID},
+	verdictcode.TSN01:  typosquatFingerprintFields,
+	verdictcode.TSP01:  typosquatFingerprintFields,
+	verdictcode.MDN04:  emailFingerprintFields,
+	verdictcode.MDN05:  mismatchFingerprintFields,
+	verdictcode.MDN06:  mismatchFingerprintFields,
+	verdictcode.MDN07:  mismatchFingerprintFields,
+	verdictcode.MDN08:  mismatchFingerprintFields,
+	verdictcode.MDN09:  emailFingerprintFields,
+	verdictcode.MDP04:  emailFingerprintFields,
+	verdictcode.MDP09:  emailFingerprintFields,
+	verdictcode.STN001: staticFingerprintFields,
+	verdictcode.STN002: staticFingerprintFields,
+	verdictcode.STN003: linkFingerprintFields,
+	verdictcode.STN004: staticFingerprintFields,
+	verdictcode.STN005: staticFingerprintFields,
+	verdictcode.STN006: dependencyFingerprintFields,
+	verdictcode.STN007: dependencyFingerprintFields,
+	verdictcode.STN008: dependencyFingerprintFields,
+	verdictcode.STN009: dependencyFingerprintFields,
+	verdictcode.STN010: ipFingerprintFields,
+	verdictcode.STP001: staticFingerprintFields,
+	verdictcode.STP002: staticFingerprintFields,
+	verdictcode.STP003: linkFingerprintFields,
+	verdictcode.STP004: staticFingerprintFields,
+	verdictcode.STP005: staticFingerprintFields,
+	verdictcode.STP006: dependencyFingerprintFields,
+	verdictcode.STP007: dependencyFingerprintFields,
+	verdictcode.STP008: dependencyFingerprintFields,
+	verdictcode.STP009: dependencyFingerprintFields,
+	verdictcode.STP010: ipFingerprintFields,
+}
+
+// FingerprintFields returns the metadata fields the fingerprints of the verdicts with the given code are made of.
+//
+// It returns nil when the fingerprints are made of all the metadata.
+func FingerprintFields(code verdictcode.Code) []string {
+	return slices.Clone(fingerprintFields[code])
+}
+
+// ComputeFingerprint returns the canonical fingerprint of the verdict.
+//
+// It is the hex-encoded SHA-256 of the ecosystem, organization, package, version, digest, file, code,
+// and of the metadata fields chosen for its code (see FingerprintFields), normalized so that:
+//   - the order of the map keys does not matter
+//   - the numbers having the same value have the same representation (eg., 10, 10.0, and 1e1)
+//   - the file paths are cleaned (eg., ./lib//index.js is lib/index.js)
+//   - the domains, the emails, and the URL hosts are lowercase.
+//
+// The missing metadata fields do not contribute to the fingerprint.
+func (o Verdict) ComputeFingerprint() (string, error) {
+	return o.fingerprint(o.Ecosystem.Case(), o.Org, o.Pkg, o.Version, o.Digest, o.File, o.Code.String())
+}
+
+// ComputeContentFingerprint returns the fingerprint of the finding the verdict is about.
+//
+// Unlike ComputeFingerprint, it does not depend on the package version (nor on its digest),
+// so the same finding has the same content fingerprint across the versions of a package.
+// It is the hex-encoded SHA-256 of the file, code, and of the metadata fields chosen for its code,
+// normalized as ComputeFingerprint does.
+func (o Verdict) ComputeContentFingerprint() (string, error) {
+	return o.fingerprint(o.File, o.Code.String())
+}
+
+func (o Verdict) fingerprint(identity ...string) (string, error) {
+	var b strings.Builder
+	for _, s := range identity {
+		b.WriteString(strconv.Quote(s))
+		b.WriteByte('\n')
+	}
+
+	fields, ok := fingerprintFields[o.Code]
+	if !ok {
+		fields = maps.Keys(o.Metadata)
+		slices.Sort(fields)
+	}
+	for _, f := range fields {
+		v, ok := lookupMetadata(o.Metadata, f)
+		if !ok || v == nil {
+			continue
+		}
+		b.WriteString(strconv.Quote(f))
+		b.WriteByte('=')
+		if err := writeCanonical(&b, normalizeField(f, v)); err != nil {
+			return "", fmt.Errorf("couldn't fingerprint metadata field %q: %w", f, err)
+		}
+		b.WriteByte('\n')
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SetFingerprint sets the fingerprint of the verdict to its canonical one (see ComputeFingerprint).
+func (o *Verdict) SetFingerprint() error {
+	f, err := o.ComputeFingerprint()
+	if err != nil {
+		return err
+	}
+	o.Fingerprint = f
+
+	return nil
+}
+
+// lookupMetadata returns the value of the given (dotted) field.
+func lookupMetadata(m map[string]interface{}, field string) (interface{}, bool) {
+	var cur interface{} = m
+	for _, k := range strings.Split(field, ".") {
+		next, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = next[k]; !ok {
+			return nil, false
+		}
+	}
+
+	return cur, true
+}
+
+func normalizeField(field string, v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	s = strings.TrimSpace(s)
+
+	switch field {
+	case FingerprintFieldFile, FingerprintFieldExecutablePath:
+		return path.Clean(strings.ReplaceAll(s, "\\", "/"))
+	case FingerprintFieldDomain:
+		return strings.TrimSuffix(strings.ToLower(s), ".")
+	case FingerprintFieldEmail:
+		return strings.ToLower(s)
+	case FingerprintFieldURL:
+		u, err := url.Parse(s)
+		if err != nil {
+			return s
+		}
+		// The scheme is already lowercase
+		u.Host = strings.ToLower(u.Host)
+
+		return u.String()
+	}
+
+	return s
+}
+
+// writeCanonical writes the canonical representation of the given value.
+//
+// It sorts the map keys and formats the numbers with their shortest representation.
+func writeCanonical(b *strings.Builder, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case string:
+		b.WriteString(strconv.Quote(x))
+	case bool:
+		b.WriteString(strconv.FormatBool(x))
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return err
+		}
+		writeNumber(b, f)
+	case map[string]interface{}:
+		keys := maps.Keys(x)
+		slices.Sort(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Quote(k))
+			b.WriteByte(':')
+			if err := writeCanonical(b, x[k]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case []interface{}:
+		b.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeCanonical(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		return writeCanonicalReflect(b, reflect.ValueOf(v))
+	}
+
+	return nil
+}
+
+// writeCanonicalReflect writes the canonical representation of the numbers, maps, and slices of any type.
+func writeCanonicalReflect(b *strings.Builder, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeNumber(b, float64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		writeNumber(b, float64(v.Uint()))
+	case reflect.Float32:
+		// Format it as a float32 to avoid picking up its float64 conversion error
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(v.Float(), 'g', -1, 32), 64)
+		writeNumber(b, f)
+	case reflect.Float64:
+		writeNumber(b, v.Float())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+
+		return writeCanonical(b, m)
+	case reflect.Slice, reflect.Array:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = v.Index(i).Interface()
+		}
+
+		return writeCanonical(b, s)
+	case reflect.String:
+		return writeCanonical(b, v.String())
+	case reflect.Bool:
+		return writeCanonical(b, v.Bool())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return writeCanonical(b, nil)
+		}
+
+		return writeCanonicalReflect(b, v.Elem())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// maxExactInteger is the largest integer a float64 represents exactly (2^53).
+const maxExactInteger = 1 << 53
+
+// writeNumber writes the integral numbers up to 2^53 as integers, and the other ones with their shortest representation.
+//
+// The integers get here as float64 too, so that they have the same representation whatever their type,
+// and across a JSON round trip, which decodes them as float64.
+func writeNumber(b *strings.Builder, f float64) {
+	if f == math.Trunc(f) && math.Abs(f) <= maxExactInteger {
+		b.WriteString(strconv.FormatInt(int64(f), 10))
+
+		return
+	}
+	b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+}
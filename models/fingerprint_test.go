@@ -0,0 +1,329 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/analysisrequest"
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+const fingerprintsGolden = "fingerprints.golden"
+
+func newFingerprintVerdict(file string, code verdictcode.Code, metadata map[string]interface{}) Verdict {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return Verdict{
+		Ecosystem: ecosystem.Npm,
+		Org:       "@vue",
+		Pkg:       "devtools",
+		Version:   "6.5.0",
+		Digest:    "0123456789012345678901234567890123456789",
+		File:      file,
+		Code:      code,
+		CreatedAt: &now,
+		Metadata:  metadata,
+	}
+}
+
+var fingerprintCases = []struct {
+	name    string
+	verdict Verdict
+}{
+	{
+		name: "static analysis",
+		verdict: newFingerprintVerdict("static(exfiltrate_env).json", verdictcode.STN001, map[string]interface{}{
+			"file":    "lib/index.js",
+			"start":   map[string]interface{}{"line": 10, "col": 4},
+			"snippet": "process.env",
+		}),
+	},
+	{
+		name: "shady links",
+		verdict: newFingerprintVerdict("static(shady_links).json", verdictcode.STN003, map[string]interface{}{
+			"file":   "lib/index.js",
+			"domain": "example.com",
+			"url":    "https://example.com/payload",
+		}),
+	},
+	{
+		name: "dependency",
+		verdict: newFingerprintVerdict("static(non_registry_dependency).json", verdictcode.STN007, map[string]interface{}{
+			"dependency": "left-pad",
+			"url":        "http://Example.com/left-pad.tgz",
+		}),
+	},
+	{
+		name: "child process",
+		verdict: newFingerprintVerdict("dynamic!install!.json", verdictcode.FNI001, map[string]interface{}{
+			"executable_path": "/bin/sh",
+			"commandline":     "sh -c node install.js",
+			"parent_name":     "node",
+			"pid":             1234,
+		}),
+	},
+	{
+		name: "connection",
+		verdict: newFingerprintVerdict("dynamic!install!.json", verdictcode.FNI002, map[string]interface{}{
+			"npm_package_name":    "utf-8-validate",
+			"npm_package_version": "5.0.10",
+			"executable_path":     "/usr/bin/node",
+			"commandline":         "node install.js",
+			"parent_name":         "sh",
+			"file_descriptor":     "3",
+			"server_ip":           "1.1.1.1",
+			"server_port":         443,
+		}),
+	},
+	{
+		name: "maintainer email",
+		verdict: newFingerprintVerdict("metadata(email_check).json", verdictcode.MDN04, map[string]interface{}{
+			"email": "someone@example.com",
+		}),
+	},
+	{
+		name: "typosquat",
+		verdict: newFingerprintVerdict("typosquat.json", verdictcode.TSN01, map[string]interface{}{
+			"target":    "devtool",
+			"distance":  1,
+			"downloads": 123456,
+		}),
+	},
+	{
+		name: "mismatch",
+		verdict: newFingerprintVerdict("metadata(mismatches).json", verdictcode.MDN06, map[string]interface{}{
+			"registry": map[string]interface{}{"install": "node-gyp rebuild"},
+			"tarball":  map[string]interface{}{"preinstall": "node setup.js"},
+		}),
+	},
+	{
+		name: "all metadata",
+		verdict: newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, map[string]interface{}{
+			"similar_to": []interface{}{"vue", "devtool"},
+			"scores":     map[string]interface{}{"vue": 0.5, "devtool": 1},
+		}),
+	},
+	{
+		name:    "no metadata",
+		verdict: newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, nil),
+	},
+}
+
+func readGolden(t *testing.T, name string) map[string]string {
+	t.Helper()
+
+	f, err := os.Open(path.Join("testdata", name))
+	require.Nil(t, err)
+	defer f.Close()
+
+	ret := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ": ")
+		require.True(t, ok, "malformed golden line %q", scanner.Text())
+		ret[name] = value
+	}
+	require.Nil(t, scanner.Err())
+
+	return ret
+}
+
+func TestFingerprintGolden(t *testing.T) {
+	got := map[string]string{}
+	var b strings.Builder
+	for _, tc := range fingerprintCases {
+		f, err := tc.verdict.ComputeFingerprint()
+		require.Nil(t, err, tc.name)
+		got[tc.name] = f
+		fmt.Fprintf(&b, "%s: %s\n", tc.name, f)
+	}
+
+	if *updateGolden {
+		require.Nil(t, os.MkdirAll("testdata", 0o755))
+		require.Nil(t, os.WriteFile(path.Join("testdata", fingerprintsGolden), []byte(b.String()), 0o600))
+	}
+
+	// The fingerprints must never change, since they get stored
+	assert.Equal(t, readGolden(t, fingerprintsGolden), got)
+}
+
+func TestFingerprintIsStable(t *testing.T) {
+	fingerprint := func(file string, code verdictcode.Code, metadataJSON string) string {
+		t.Helper()
+
+		metadata := map[string]interface{}{}
+		require.Nil(t, json.Unmarshal([]byte(metadataJSON), &metadata))
+		f, err := newFingerprintVerdict(file, code, metadata).ComputeFingerprint()
+		require.Nil(t, err)
+
+		return f
+	}
+	static := func(metadata string) string {
+		return fingerprint("static(exfiltrate_env).json", verdictcode.STN001, metadata)
+	}
+	links := func(metadata string) string {
+		return fingerprint("static(shady_links).json", verdictcode.STN003, metadata)
+	}
+	all := func(metadata string) string {
+		return fingerprint("metadata(empty_descriptions).json", verdictcode.MDN01, metadata)
+	}
+
+	want := static(`{"file": "lib/index.js", "start": {"line": 10, "col": 4}}`)
+	// Map ordering and float formatting
+	assert.Equal(t, want, static(`{"start": {"col": 4, "line": 10.0}, "file": "lib/index.js"}`))
+	assert.Equal(t, want, static(`{"start": {"line": 1e1}, "file": "lib/index.js"}`))
+	// Metadata not chosen for the code
+	assert.Equal(t, want, static(`{"file": "lib/index.js", "start": {"line": 10, "col": 5}, "snippet": "x"}`))
+	// Path normalization
+	assert.Equal(t, want, static(`{"file": "./lib//index.js", "start": {"line": 10}}`))
+	assert.Equal(t, want, static(`{"file": "lib\\index.js", "start": {"line": 10}}`))
+	// Different values
+	assert.NotEqual(t, want, static(`{"file": "lib/index.js", "start": {"line": 11}}`))
+	assert.NotEqual(t, want, static(`{"file": "lib/other.js", "start": {"line": 10}}`))
+	assert.NotEqual(t, want, static(`{"file": "lib/index.js"}`))
+
+	// Domains
+	assert.Equal(t, links(`{"domain": "example.com"}`), links(`{"domain": " Example.COM. "}`))
+	assert.NotEqual(t, links(`{"domain": "example.com"}`), links(`{"domain": "example.org"}`))
+
+	// Connections
+	connection := func(metadata string) string {
+		return fingerprint("dynamic!install!.json", verdictcode.FNI002, metadata)
+	}
+	assert.Equal(t,
+		connection(`{"executable_path": "/usr/bin/node", "server_ip": "1.1.1.1", "server_port": 443, "file_descriptor": "3"}`),
+		connection(`{"executable_path": "/usr/bin/node", "server_ip": "1.1.1.1", "server_port": 443, "file_descriptor": "4"}`),
+	)
+	assert.NotEqual(t,
+		connection(`{"executable_path": "/usr/bin/node", "server_ip": "1.1.1.1", "server_port": 443}`),
+		connection(`{"executable_path": "/usr/bin/node", "server_ip": "8.8.8.8", "server_port": 443}`),
+	)
+	assert.NotEqual(t,
+		connection(`{"executable_path": "/usr/bin/node", "server_ip": "1.1.1.1", "server_port": 443}`),
+		connection(`{"executable_path": "/usr/bin/node", "server_ip": "1.1.1.1", "server_port": 80}`),
+	)
+
+	// Typosquats and mismatches, whatever their incidental metadata
+	for _, code := range []verdictcode.Code{verdictcode.TSN01, verdictcode.TSP01} {
+		assert.Equal(t,
+			fingerprint("typosquat.json", code, `{"target": "devtool", "distance": 1, "downloads": 100}`),
+			fingerprint("typosquat.json", code, `{"target": "devtool", "distance": 1, "downloads": 200}`),
+		)
+		assert.NotEqual(t,
+			fingerprint("typosquat.json", code, `{"target": "devtool"}`),
+			fingerprint("typosquat.json", code, `{"target": "devtools"}`),
+		)
+	}
+	for _, code := range []verdictcode.Code{verdictcode.MDN05, verdictcode.MDN06, verdictcode.MDN07, verdictcode.MDN08} {
+		assert.Equal(t,
+			fingerprint("metadata(mismatches).json", code, `{"registry": {"a": "1"}, "tarball": {"a": "2"}}`),
+			fingerprint("metadata(mismatches).json", code, `{"tarball": {"a": "2"}, "registry": {"a": "1"}, "checked_at": "today"}`),
+		)
+		assert.NotEqual(t,
+			fingerprint("metadata(mismatches).json", code, `{"registry": {"a": "1"}, "tarball": {"a": "2"}}`),
+			fingerprint("metadata(mismatches).json", code, `{"registry": {"a": "1"}, "tarball": {"a": "3"}}`),
+		)
+	}
+
+	// All the metadata
+	assert.Equal(t,
+		all(`{"a": [1, 2.50], "b": {"y": true, "x": null}}`),
+		all(`{"b": {"x": null, "y": true}, "a": [1.0, 2.5]}`),
+	)
+	assert.NotEqual(t, all(`{"a": [1, 2]}`), all(`{"a": [2, 1]}`))
+
+	// Go values
+	f, err := newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, map[string]interface{}{
+		"a": []int{1, 2},
+		"b": map[string]interface{}{"y": true, "x": nil},
+		"c": float32(0.1),
+	}).ComputeFingerprint()
+	require.Nil(t, err)
+	assert.Equal(t, all(`{"a": [1, 2], "b": {"x": null, "y": true}, "c": 0.1}`), f)
+
+	// Large numbers, whatever their type, and across a JSON round trip
+	for _, n := range []interface{}{int64(1e15), float64(1e15), uint64(1e15), json.Number("1000000000000000")} {
+		f, err := newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, map[string]interface{}{"n": n}).ComputeFingerprint()
+		require.Nil(t, err)
+		assert.Equal(t, all(`{"n": 1e15}`), f, "%T", n)
+	}
+	for _, n := range []interface{}{int64(1<<53 + 1), uint64(1 << 63), float64(1 << 63)} {
+		encoded, err := json.Marshal(map[string]interface{}{"n": n})
+		require.Nil(t, err)
+		f, err := newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, map[string]interface{}{"n": n}).ComputeFingerprint()
+		require.Nil(t, err)
+		assert.Equal(t, all(string(encoded)), f, "%T", n)
+	}
+
+	// Package identity
+	v := newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, nil)
+	base, err := v.ComputeFingerprint()
+	require.Nil(t, err)
+	v.Version = "6.5.1"
+	other, err := v.ComputeFingerprint()
+	require.Nil(t, err)
+	assert.NotEqual(t, base, other)
+
+	// Content
+	v.Version = "6.5.0"
+	content, err := v.ComputeContentFingerprint()
+	require.Nil(t, err)
+	assert.NotEqual(t, base, content)
+	v.Version = "6.5.1"
+	v.Digest = "9876543210987654321098765432109876543210"
+	again, err := v.ComputeContentFingerprint()
+	require.Nil(t, err)
+	assert.Equal(t, content, again)
+	v.Metadata = map[string]interface{}{"a": 1}
+	other, err = v.ComputeContentFingerprint()
+	require.Nil(t, err)
+	assert.NotEqual(t, content, other)
+}
+
+func TestSetFingerprint(t *testing.T) {
+	v := newFingerprintVerdict("static(exfiltrate_env).json", verdictcode.STN001, map[string]interface{}{"file": "index.js"})
+	v.Message = "env exfiltration"
+	v.Severity = "high"
+	v.Categories = nil
+	assert.Error(t, v.Validate())
+
+	require.Nil(t, v.SetFingerprint())
+	assert.Len(t, v.Fingerprint, 64)
+
+	_, err := newFingerprintVerdict("metadata(empty_descriptions).json", verdictcode.MDN01, map[string]interface{}{
+		"bad": make(chan int),
+	}).ComputeFingerprint()
+	assert.Error(t, err)
+}
+
+func TestFingerprintFields(t *testing.T) {
+	assert.Equal(t, []string{"file", "domain"}, FingerprintFields(verdictcode.STN003))
+	assert.Equal(t, []string{"target"}, FingerprintFields(verdictcode.TSP01))
+	assert.Equal(t, []string{"registry", "tarball"}, FingerprintFields(verdictcode.MDN07))
+	assert.Nil(t, FingerprintFields(verdictcode.MDN01))
+
+	// Every code not uniquely identifying its verdicts has its fields
+	for _, typ := range analysisrequest.Types() {
+		codes, err := verdictcode.GetBy(typ)
+		if err != nil {
+			continue
+		}
+		for _, c := range codes {
+			if !c.UniquelyIdentifies() {
+				assert.NotEmpty(t, FingerprintFields(c), c.String())
+			}
+		}
+	}
+}
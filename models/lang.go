@@ -31,3 +31,14 @@ import (
 //
 // The jsonpath extension language adds support for the $ and @ prefix extensions as per JSONPath grammar.
 var lang = gval.Full(jsonpath.Language())
+
+// filterLang is used to evaluate the CompiledFilter expressions.
+//
+// It is the same as lang, but the jsonpath prefix extensions, plus the functions about the current verdict.
+var filterLang = gval.NewLanguage(
+	gval.Full(),
+	gval.Function("severity_gte", severityGte),
+	gval.Function("has_category", hasCategory),
+	gval.Function("code_in", codeIn),
+	gval.Function("age", age),
+)
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/listendev/pkg/models/status"
+)
+
+// Transition is a request to change the status of a verdict.
+type Transition struct {
+	// To is the new status
+	To status.Status
+	// Actor is who changes the status (eg., an email address, a service name)
+	Actor string
+	// Reason is why the status changes, mandatory for the false-positive and accepted-risk statuses
+	Reason string
+	// At is when the status changes, it defaults to now when zero
+	At time.Time
+}
+
+// CurrentStatus returns the status of the verdict, the open one when nobody triaged it yet.
+func (o *Verdict) CurrentStatus() status.Status {
+	return o.Status.Current()
+}
+
+// Apply moves the verdict to a new status, recording the change in its history.
+//
+// It returns an error wrapping status.ErrTransition when the lifecycle does not allow the transition,
+// leaving the verdict untouched.
+func (o *Verdict) Apply(t Transition) error {
+	from := o.CurrentStatus()
+	if err := status.ValidateTransition(from, t.To); err != nil {
+		return err
+	}
+	if t.Actor == "" {
+		return errors.New("the actor of the status change is mandatory")
+	}
+	if t.To.RequiresReason() && t.Reason == "" {
+		return fmt.Errorf("a reason is mandatory to move a verdict to %s", t.To)
+	}
+	at := t.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if n := len(o.History); n > 0 && at.Before(o.History[n-1].At) {
+		return fmt.Errorf("the status change cannot happen before the last one (%s)", o.History[n-1].At.Format(time.RFC3339))
+	}
+
+	o.Status = t.To
+	o.History = append(o.History, StatusChange{
+		From:   from,
+		To:     t.To,
+		Actor:  t.Actor,
+		Reason: t.Reason,
+		At:     at,
+	})
+
+	return nil
+}
+
+// LastChange returns the latest change of the status of the verdict, if any.
+func (o *Verdict) LastChange() (StatusChange, bool) {
+	if len(o.History) == 0 {
+		return StatusChange{}, false
+	}
+
+	return o.History[len(o.History)-1], true
+}
+
+// validateHistory checks every change of the history is a transition the lifecycle allows,
+// starting from the open status and ending in the status of the verdict.
+func (o *Verdict) validateHistory() error {
+	current := status.Open
+	for i, c := range o.History {
+		if c.From.Current() != current {
+			return fmt.Errorf("the status change #%d starts from %s instead of %s", i, c.From.Current(), current)
+		}
+		if err := status.ValidateTransition(current, c.To); err != nil {
+			return fmt.Errorf("the status change #%d is not valid: %w", i, err)
+		}
+		if i > 0 && c.At.Before(o.History[i-1].At) {
+			return fmt.Errorf("the status change #%d happens before the previous one", i)
+		}
+		current = c.To
+	}
+	if o.CurrentStatus() != current {
+		return fmt.Errorf("the status %s is not the one the history leads to (%s)", o.CurrentStatus(), current)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLifecycleVerdict() Verdict {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return Verdict{
+		CreatedAt:  &created,
+		Ecosystem:  ecosystem.Npm,
+		Pkg:        "darcyclarke-manifest-pkg",
+		Version:    "2.1.15",
+		Digest:     "429eced1773fbc9ceea5cebda8338c0aaa21eeec",
+		File:       "metadata(mismatches).json",
+		Message:    "This package has inconsistent name in the tarball's package.json",
+		Severity:   severity.High,
+		Code:       verdictcode.MDN05,
+		Categories: []category.Category{category.Metadata},
+		Metadata:   map[string]interface{}{},
+	}
+}
+
+func TestApply(t *testing.T) {
+	v := newLifecycleVerdict()
+	assert.Equal(t, status.Open, v.CurrentStatus())
+	_, ok := v.LastChange()
+	assert.False(t, ok)
+
+	at := v.CreatedAt.Add(time.Hour)
+	require.Nil(t, v.Apply(Transition{To: status.Acknowledged, Actor: "alice@example.com", At: at}))
+	require.Nil(t, v.Apply(Transition{To: status.FalsePositive, Actor: "bob@example.com", Reason: "the tarball is the one we build", At: at.Add(time.Minute)}))
+	assert.Equal(t, status.FalsePositive, v.Status)
+	assert.Nil(t, v.Validate())
+
+	assert.Equal(t, []StatusChange{
+		{From: status.Open, To: status.Acknowledged, Actor: "alice@example.com", At: at},
+		{From: status.Acknowledged, To: status.FalsePositive, Actor: "bob@example.com", Reason: "the tarball is the one we build", At: at.Add(time.Minute)},
+	}, v.History)
+	last, ok := v.LastChange()
+	require.True(t, ok)
+	assert.Equal(t, "bob@example.com", last.Actor)
+
+	// Reopening it
+	require.Nil(t, v.Apply(Transition{To: status.Open, Actor: "alice@example.com"}))
+	assert.Equal(t, status.Open, v.Status)
+	assert.Len(t, v.History, 3)
+	assert.False(t, v.History[2].At.IsZero())
+}
+
+func TestApplyErrors(t *testing.T) {
+	at := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		desc    string
+		history []Transition
+		t       Transition
+		err     string
+	}{
+		{
+			desc: "same status",
+			t:    Transition{To: status.Open, Actor: "alice", At: at},
+			err:  "invalid status transition from open to open",
+		},
+		{
+			desc:    "from a resolved status",
+			history: []Transition{{To: status.Fixed, Actor: "alice", At: at}},
+			t:       Transition{To: status.Acknowledged, Actor: "alice", At: at},
+			err:     "invalid status transition from fixed to acknowledged",
+		},
+		{
+			desc: "unknown status",
+			t:    Transition{To: "closed", Actor: "alice", At: at},
+			err:  `invalid status transition: the input "closed" is not a status`,
+		},
+		{
+			desc: "missing actor",
+			t:    Transition{To: status.Acknowledged, At: at},
+			err:  "the actor of the status change is mandatory",
+		},
+		{
+			desc: "missing reason",
+			t:    Transition{To: status.AcceptedRisk, Actor: "alice", At: at},
+			err:  "a reason is mandatory to move a verdict to accepted-risk",
+		},
+		{
+			desc:    "back in time",
+			history: []Transition{{To: status.Acknowledged, Actor: "alice", At: at}},
+			t:       Transition{To: status.Fixed, Actor: "alice", At: at.Add(-time.Second)},
+			err:     "the status change cannot happen before the last one (2024-01-03T00:00:00Z)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			v := newLifecycleVerdict()
+			for _, x := range tc.history {
+				require.Nil(t, v.Apply(x))
+			}
+			before := v.Status
+			n := len(v.History)
+
+			err := v.Apply(tc.t)
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.err, err.Error())
+			}
+			// The verdict is untouched
+			assert.Equal(t, before, v.Status)
+			assert.Len(t, v.History, n)
+		})
+	}
+}
+
+func TestValidateHistory(t *testing.T) {
+	at := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	v := newLifecycleVerdict()
+	v.Status = status.Fixed
+	assert.ErrorContains(t, v.Validate(), "the status fixed is not the one the history leads to (open)")
+
+	v = newLifecycleVerdict()
+	v.Status = status.Open
+	assert.Nil(t, v.Validate())
+
+	v = newLifecycleVerdict()
+	v.Status = status.Acknowledged
+	v.History = []StatusChange{{From: status.Fixed, To: status.Acknowledged, Actor: "alice", At: at}}
+	assert.ErrorContains(t, v.Validate(), "the status change #0 starts from fixed instead of open")
+
+	v = newLifecycleVerdict()
+	v.Status = status.Acknowledged
+	v.History = []StatusChange{{From: status.Open, To: status.Acknowledged, At: at}}
+	assert.ErrorContains(t, v.Validate(), "who changed the status is mandatory")
+
+	v = newLifecycleVerdict()
+	v.Status = status.Open
+	v.History = []StatusChange{
+		{From: status.Open, To: status.Fixed, Actor: "alice", At: at},
+		{From: status.Fixed, To: status.Open, Actor: "alice", At: at.Add(-time.Hour)},
+	}
+	assert.ErrorContains(t, v.Validate(), "the status change #1 happens before the previous one")
+}
+
+func TestLifecycleJSON(t *testing.T) {
+	v := newLifecycleVerdict()
+	data, err := json.Marshal(v)
+	require.Nil(t, err)
+	assert.NotContains(t, string(data), `"status"`)
+	assert.NotContains(t, string(data), `"history"`)
+
+	at := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, v.Apply(Transition{To: status.AcceptedRisk, Actor: "alice", Reason: "internal package", At: at}))
+	data, err = json.Marshal(v)
+	require.Nil(t, err)
+	assert.Contains(t, string(data), `"status":"accepted-risk"`)
+	assert.Contains(t, string(data), `"history":[{"actor":"alice","at":"2024-01-03T00:00:00Z","from":"open","reason":"internal package","to":"accepted-risk"}]`)
+
+	var got Verdict
+	require.Nil(t, json.Unmarshal(data, &got))
+	assert.Equal(t, v, got)
+
+	// The history must lead to the status
+	tampered := bytes.Replace(data, []byte(`"status":"accepted-risk"`), []byte(`"status":"fixed"`), 1)
+	assert.Error(t, json.Unmarshal(tampered, &got))
+}
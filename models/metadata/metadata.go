@@ -0,0 +1,208 @@
+// Package metadata provides the typed metadata of the verdicts, per verdict code.
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/listendev/pkg/validate"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Family groups the verdict codes sharing the shape of their metadata.
+type Family string
+
+const (
+	FamilyDynamic    Family = "dynamic"
+	FamilyStatic     Family = "static"
+	FamilyDependency Family = "dependency"
+	FamilyTyposquat  Family = "typosquat"
+	FamilyMismatch   Family = "mismatch"
+	FamilyMaintainer Family = "maintainer"
+	FamilyAdvisory   Family = "advisory"
+	FamilyPackage    Family = "package"
+)
+
+type entry struct {
+	family Family
+	typ    reflect.Type
+}
+
+func of[T any](family Family) entry {
+	return entry{family: family, typ: reflect.TypeFor[T]()}
+}
+
+// registry maps the codes to the family and the type of their metadata.
+var registry = map[verdictcode.Code]entry{
+	verdictcode.FNI001: of[ProcessEvent](FamilyDynamic),
+	verdictcode.FNI002: of[NetworkEvent](FamilyDynamic),
+	verdictcode.FNI003: of[NetworkEvent](FamilyDynamic),
+	verdictcode.FNI004: of[FileEvent](FamilyDynamic),
+	verdictcode.FNI005: of[FileEvent](FamilyDynamic),
+	verdictcode.FNI006: of[FileEvent](FamilyDynamic),
+	verdictcode.FNI007: of[FileEvent](FamilyDynamic),
+	verdictcode.FNI008: of[FileEvent](FamilyDynamic),
+	verdictcode.RUN001: of[DNSEvent](FamilyDynamic),
+	verdictcode.DDN01:  of[Advisory](FamilyAdvisory),
+	verdictcode.TSN01:  of[Typosquat](FamilyTyposquat),
+	verdictcode.TSP01:  of[Typosquat](FamilyTyposquat),
+	verdictcode.MDN01:  of[None](FamilyPackage),
+	verdictcode.MDN02:  of[None](FamilyPackage),
+	verdictcode.MDN03:  of[None](FamilyPackage),
+	verdictcode.MDN04:  of[Maintainer](FamilyMaintainer),
+	verdictcode.MDN05:  of[Mismatch[string]](FamilyMismatch),
+	verdictcode.MDN06:  of[Mismatch[map[string]string]](FamilyMismatch),
+	verdictcode.MDN07:  of[Mismatch[map[string]string]](FamilyMismatch),
+	verdictcode.MDN08:  of[Mismatch[map[string]string]](FamilyMismatch),
+	verdictcode.MDN09:  of[Maintainer](FamilyMaintainer),
+	verdictcode.MDP04:  of[Maintainer](FamilyMaintainer),
+	verdictcode.MDP09:  of[Maintainer](FamilyMaintainer),
+	verdictcode.STN001: of[Static](FamilyStatic),
+	verdictcode.STN002: of[Static](FamilyStatic),
+	verdictcode.STN003: of[Link](FamilyStatic),
+	verdictcode.STN004: of[Static](FamilyStatic),
+	verdictcode.STN005: of[Static](FamilyStatic),
+	verdictcode.STN006: of[Dependency](FamilyDependency),
+	verdictcode.STN007: of[Dependency](FamilyDependency),
+	verdictcode.STN008: of[Dependency](FamilyDependency),
+	verdictcode.STN009: of[Dependency](FamilyDependency),
+	verdictcode.STN010: of[IP](FamilyStatic),
+	verdictcode.STP001: of[Static](FamilyStatic),
+	verdictcode.STP002: of[Static](FamilyStatic),
+	verdictcode.STP003: of[Link](FamilyStatic),
+	verdictcode.STP004: of[Static](FamilyStatic),
+	verdictcode.STP005: of[Static](FamilyStatic),
+	verdictcode.STP006: of[Dependency](FamilyDependency),
+	verdictcode.STP007: of[Dependency](FamilyDependency),
+	verdictcode.STP008: of[Dependency](FamilyDependency),
+	verdictcode.STP009: of[Dependency](FamilyDependency),
+	verdictcode.STP010: of[IP](FamilyStatic),
+}
+
+func lookup(code verdictcode.Code) (entry, error) {
+	e, ok := registry[code]
+	if !ok {
+		return entry{}, fmt.Errorf("couldn't find the metadata of the code %q", code.String())
+	}
+
+	return e, nil
+}
+
+// FamilyOf returns the family of the metadata of the verdicts with the given code.
+func FamilyOf(code verdictcode.Code) (Family, error) {
+	e, err := lookup(code)
+	if err != nil {
+		return "", err
+	}
+
+	return e.family, nil
+}
+
+// New returns a pointer to the zero value of the metadata of the verdicts with the given code (eg., *ProcessEvent for FNI001).
+func New(code verdictcode.Code) (any, error) {
+	e, err := lookup(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflect.New(e.typ).Interface(), nil
+}
+
+// Decode converts the metadata of a verdict with the given code into its typed form, validating it.
+//
+// It returns a pointer to the metadata type of the code (see New).
+// It fails when the metadata have fields the type does not know, have fields of the wrong type,
+// or miss mandatory fields.
+func Decode(code verdictcode.Code, m map[string]interface{}) (any, error) {
+	res, err := New(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := decode(m, res); err != nil {
+		return nil, fmt.Errorf("invalid %s metadata: %w", code.String(), err)
+	}
+
+	return res, nil
+}
+
+// As converts the metadata into the given type, validating it.
+func As[T any](m map[string]interface{}) (*T, error) {
+	res := new(T)
+	if err := decode(m, res); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	return res, nil
+}
+
+// Validate checks the metadata of a verdict with the given code (see Decode).
+func Validate(code verdictcode.Code, m map[string]interface{}) error {
+	_, err := Decode(code, m)
+
+	return err
+}
+
+// Encode converts the typed metadata of a verdict with the given code into the map of the verdicts, validating it.
+//
+// The metadata must be (a pointer to) the metadata type of the code (see New).
+func Encode(code verdictcode.Code, v any) (map[string]interface{}, error) {
+	e, err := lookup(code)
+	if err != nil {
+		return nil, err
+	}
+	if t := reflect.TypeOf(v); t != e.typ && t != reflect.PointerTo(e.typ) {
+		return nil, fmt.Errorf("the metadata of the code %q must be a %s, not a %T", code.String(), e.typ, v)
+	}
+	if errs := validate.Validate(v); len(errs) > 0 {
+		return nil, join(errs)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encode the metadata: %w", err)
+	}
+	res := map[string]interface{}{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("couldn't encode the metadata: %w", err)
+	}
+
+	return res, nil
+}
+
+// decode goes through JSON since the metadata of the verdicts are the ones of their JSON form.
+func decode(m map[string]interface{}, res any) error {
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(res); err != nil {
+		return err
+	}
+	if errs := validate.Validate(res); len(errs) > 0 {
+		return join(errs)
+	}
+
+	return nil
+}
+
+func join(errs []error) error {
+	ret := "validation error"
+	if len(errs) > 1 {
+		ret += "s"
+	}
+	ret += ": "
+	for i, e := range errs {
+		if i > 0 {
+			ret += "; "
+		}
+		ret += e.Error()
+	}
+
+	return fmt.Errorf("%s", ret)
+}
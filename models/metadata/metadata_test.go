@@ -0,0 +1,335 @@
+package metadata
+
+import (
+	"maps"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/invopop/yaml"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var validCases = []struct {
+	code     verdictcode.Code
+	metadata map[string]interface{}
+	want     any
+}{
+	{
+		code: verdictcode.FNI001,
+		metadata: map[string]interface{}{
+			"npm_package_name":    "electron",
+			"npm_package_version": "21.4.2",
+			"commandline":         "sh -c node install.js",
+			"parent_name":         "node",
+			"executable_path":     "/bin/sh",
+			"pid":                 float64(1234),
+			"ancestors": []interface{}{
+				map[string]interface{}{"pid": float64(1233), "name": "node"},
+				map[string]interface{}{"pid": float64(1), "name": "npm"},
+			},
+		},
+		want: &ProcessEvent{Process{
+			NPMPackageName:    "electron",
+			NPMPackageVersion: "21.4.2",
+			PID:               1234,
+			ExecutablePath:    "/bin/sh",
+			Commandline:       "sh -c node install.js",
+			ParentName:        "node",
+			Ancestors:         []Ancestor{{PID: 1233, Name: "node"}, {PID: 1, Name: "npm"}},
+		}},
+	},
+	{
+		code: verdictcode.FNI003,
+		metadata: map[string]interface{}{
+			"executable_path": "/usr/bin/node",
+			"server_ip":       "1.1.1.1",
+			"server_port":     float64(443),
+		},
+		want: &NetworkEvent{Process: Process{ExecutablePath: "/usr/bin/node", ServerIP: "1.1.1.1", ServerPort: 443}},
+	},
+	{
+		code: verdictcode.FNI006,
+		metadata: map[string]interface{}{
+			"executable_path": "/usr/bin/node",
+			"file":            "/root/.npmrc",
+		},
+		want: &FileEvent{Process: Process{ExecutablePath: "/usr/bin/node"}, File: "/root/.npmrc"},
+	},
+	{
+		code: verdictcode.RUN001,
+		metadata: map[string]interface{}{
+			"executable_path": "/usr/bin/node",
+			"domain":          "example.com",
+		},
+		want: &DNSEvent{Process: Process{ExecutablePath: "/usr/bin/node"}, Domain: "example.com"},
+	},
+	{
+		code: verdictcode.STN001,
+		metadata: map[string]interface{}{
+			"file":    "lib/index.js",
+			"start":   map[string]interface{}{"line": float64(10), "col": float64(4)},
+			"snippet": "process.env",
+		},
+		want: &Static{File: "lib/index.js", Start: &Position{Line: 10, Col: 4}, Snippet: "process.env"},
+	},
+	{
+		code: verdictcode.STP003,
+		metadata: map[string]interface{}{
+			"file":   "setup.py",
+			"domain": "example.com",
+			"url":    "https://example.com/payload",
+		},
+		want: &Link{Static: Static{File: "setup.py"}, Domain: "example.com", URL: "https://example.com/payload"},
+	},
+	{
+		code: verdictcode.STN010,
+		metadata: map[string]interface{}{
+			"file": "lib/index.js",
+			"ip":   "::1",
+		},
+		want: &IP{Static: Static{File: "lib/index.js"}, IP: "::1"},
+	},
+	{
+		code: verdictcode.STN007,
+		metadata: map[string]interface{}{
+			"dependency": "left-pad",
+			"url":        "http://example.com/left-pad.tgz",
+		},
+		want: &Dependency{Dependency: "left-pad", URL: "http://example.com/left-pad.tgz"},
+	},
+	{
+		code: verdictcode.TSN01,
+		metadata: map[string]interface{}{
+			"target":   "react",
+			"distance": float64(1),
+		},
+		want: &Typosquat{Target: "react", Distance: 1},
+	},
+	{
+		code: verdictcode.MDN05,
+		metadata: map[string]interface{}{
+			"registry": "darcyclarke-manifest-pkg",
+			"tarball":  "express",
+		},
+		want: &Mismatch[string]{Registry: "darcyclarke-manifest-pkg", Tarball: "express"},
+	},
+	{
+		code: verdictcode.MDN06,
+		metadata: map[string]interface{}{
+			"registry": map[string]interface{}{},
+			"tarball":  map[string]interface{}{"postinstall": "node evil.js"},
+		},
+		want: &Mismatch[map[string]string]{Registry: map[string]string{}, Tarball: map[string]string{"postinstall": "node evil.js"}},
+	},
+	{
+		code: verdictcode.MDP04,
+		metadata: map[string]interface{}{
+			"email": "someone@example.com",
+		},
+		want: &Maintainer{Email: "someone@example.com"},
+	},
+	{
+		code: verdictcode.DDN01,
+		metadata: map[string]interface{}{
+			"id":   "GHSA-xxxx-xxxx-xxxx",
+			"cvss": 9.8,
+		},
+		want: &Advisory{ID: "GHSA-xxxx-xxxx-xxxx", CVSS: 9.8},
+	},
+	{
+		code:     verdictcode.MDN01,
+		metadata: nil,
+		want:     &None{},
+	},
+}
+
+func TestEveryCodeHasMetadata(t *testing.T) {
+	for _, code := range verdictcode.Codes() {
+		_, err := FamilyOf(code)
+		assert.Nil(t, err, code.String())
+		_, err = Schema(code)
+		assert.Nil(t, err, code.String())
+	}
+
+	_, err := New(verdictcode.UNK)
+	assert.Error(t, err)
+}
+
+func TestFamilyOf(t *testing.T) {
+	cases := map[verdictcode.Code]Family{
+		verdictcode.FNI001: FamilyDynamic,
+		verdictcode.RUN001: FamilyDynamic,
+		verdictcode.STP004: FamilyStatic,
+		verdictcode.STN008: FamilyDependency,
+		verdictcode.TSP01:  FamilyTyposquat,
+		verdictcode.MDN07:  FamilyMismatch,
+		verdictcode.MDN09:  FamilyMaintainer,
+		verdictcode.DDN01:  FamilyAdvisory,
+		verdictcode.MDN02:  FamilyPackage,
+	}
+	for code, want := range cases {
+		got, err := FamilyOf(code)
+		require.Nil(t, err)
+		assert.Equal(t, want, got, code.String())
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, tc := range validCases {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			got, err := Decode(tc.code, tc.metadata)
+			require.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+
+			// Back to the map
+			m, err := Encode(tc.code, got)
+			require.Nil(t, err)
+			again, err := Decode(tc.code, m)
+			require.Nil(t, err)
+			assert.Equal(t, tc.want, again)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	cases := []struct {
+		desc     string
+		code     verdictcode.Code
+		metadata map[string]interface{}
+		err      string
+	}{
+		{
+			desc:     "unknown field",
+			code:     verdictcode.FNI001,
+			metadata: map[string]interface{}{"executable_path": "/bin/sh", "color": "red"},
+			err:      `invalid FNI001 metadata: json: unknown field "color"`,
+		},
+		{
+			desc:     "wrong type",
+			code:     verdictcode.FNI003,
+			metadata: map[string]interface{}{"executable_path": "/bin/sh", "server_ip": "1.1.1.1", "server_port": "443"},
+			err:      "invalid FNI003 metadata: json: cannot unmarshal string into Go struct field NetworkEvent.server_port of type int",
+		},
+		{
+			desc:     "missing mandatory field",
+			code:     verdictcode.FNI004,
+			metadata: map[string]interface{}{"file": "/etc/passwd"},
+			err:      "invalid FNI004 metadata: validation error: the executable path is mandatory",
+		},
+		{
+			desc:     "invalid email",
+			code:     verdictcode.MDN04,
+			metadata: map[string]interface{}{"email": "someone"},
+			err:      "invalid MDN04 metadata: validation error: the maintainer email must be a valid email address",
+		},
+		{
+			desc:     "invalid IP",
+			code:     verdictcode.STP010,
+			metadata: map[string]interface{}{"file": "setup.py", "ip": "1.1.1"},
+			err:      "invalid STP010 metadata: validation error: the IP address must be a valid IP address",
+		},
+		{
+			desc:     "invalid nested field",
+			code:     verdictcode.STN004,
+			metadata: map[string]interface{}{"file": "index.js", "start": map[string]interface{}{"col": float64(1)}},
+			err:      "invalid STN004 metadata: validation error: the line is mandatory",
+		},
+		{
+			desc:     "metadata where none",
+			code:     verdictcode.MDN03,
+			metadata: map[string]interface{}{"version": "1.0.0-beta"},
+			err:      `invalid MDN03 metadata: json: unknown field "version"`,
+		},
+		{
+			desc: "unknown code",
+			code: verdictcode.UNK,
+			err:  `couldn't find the metadata of the code "UNK"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := Decode(tc.code, tc.metadata)
+			if assert.Error(t, err) {
+				assert.Equal(t, tc.err, err.Error())
+			}
+			assert.Error(t, Validate(tc.code, tc.metadata))
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	m, err := Encode(verdictcode.TSP01, Typosquat{Target: "requests", Distance: 2, Downloads: 1000})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"target": "requests", "distance": float64(2), "downloads": float64(1000)}, m)
+
+	_, err = Encode(verdictcode.TSP01, &Maintainer{Email: "someone@example.com"})
+	assert.EqualError(t, err, `the metadata of the code "TSP01" must be a metadata.Typosquat, not a *metadata.Maintainer`)
+
+	_, err = Encode(verdictcode.TSP01, Typosquat{Target: "requests"})
+	assert.EqualError(t, err, "validation error: the edit distance from the name of the popular package is mandatory")
+}
+
+func TestAs(t *testing.T) {
+	got, err := As[Dependency](map[string]interface{}{"dependency": "left-pad", "url": "git+https://github.com/left-pad/left-pad.git"})
+	require.Nil(t, err)
+	assert.Equal(t, "left-pad", got.Dependency)
+
+	_, err = As[Dependency](map[string]interface{}{"dependency": "left-pad"})
+	assert.EqualError(t, err, "invalid metadata: validation error: the dependency URL is mandatory")
+}
+
+func TestDynamicExample(t *testing.T) {
+	data, err := os.ReadFile(path.Join("..", "types.yml"))
+	require.Nil(t, err)
+	var spec struct {
+		Components struct {
+			Schemas struct {
+				Verdict struct {
+					Example struct {
+						Metadata map[string]interface{} `json:"metadata"`
+					} `json:"example"`
+				} `json:"Verdict"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.Nil(t, yaml.Unmarshal(data, &spec))
+	example := spec.Components.Schemas.Verdict.Example.Metadata
+	require.Contains(t, example, "server_ip")
+
+	// The metadata the dynamic instrumentation emits is valid for all its codes
+	for _, code := range []verdictcode.Code{
+		verdictcode.FNI001,
+		verdictcode.FNI002,
+		verdictcode.FNI003,
+		verdictcode.FNI004,
+		verdictcode.FNI005,
+		verdictcode.FNI006,
+		verdictcode.FNI007,
+		verdictcode.FNI008,
+	} {
+		m := maps.Clone(example)
+		if typed, _ := New(code); typed != nil {
+			if _, ok := typed.(*FileEvent); ok {
+				m["file"] = "/root/.npmrc"
+			}
+		}
+		got, err := Decode(code, m)
+		require.Nil(t, err, code.String())
+		require.Nil(t, Validate(code, m), code.String())
+
+		encoded, err := Encode(code, got)
+		require.Nil(t, err, code.String())
+		assert.Equal(t, "/bin/sh", encoded["executable_path"])
+	}
+
+	m := maps.Clone(example)
+	m["server_ip"] = "1.1.1.1"
+	m["server_port"] = float64(443)
+	got, err := Decode(verdictcode.FNI002, m)
+	require.Nil(t, err)
+	assert.Equal(t, "1.1.1.1", got.(*NetworkEvent).ServerIP)
+	assert.Equal(t, 443, got.(*NetworkEvent).ServerPort)
+}
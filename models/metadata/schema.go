@@ -0,0 +1,175 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// SchemaDialect is the JSON Schema dialect of the schemas of the metadata.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Schema returns the JSON Schema of the metadata of the verdicts with the given code.
+func Schema(code verdictcode.Code) (map[string]interface{}, error) {
+	e, err := lookup(code)
+	if err != nil {
+		return nil, err
+	}
+	res, err := schemaOf(e.typ)
+	if err != nil {
+		return nil, err
+	}
+	res["$schema"] = SchemaDialect
+	res["title"] = fmt.Sprintf("The metadata of the %s verdicts", code.String())
+	if info, err := code.Info(); err == nil {
+		res["description"] = info.Title
+	}
+
+	return res, nil
+}
+
+// WriteSchemas writes a JSON Schema document defining the schemas of the metadata of all the codes (see Schema),
+// indented, with the codes as the keys of its definitions.
+func WriteSchemas(w io.Writer) error {
+	defs := map[string]interface{}{}
+	for _, code := range verdictcode.Codes() {
+		s, err := Schema(code)
+		if err != nil {
+			return err
+		}
+		delete(s, "$schema")
+		defs[code.String()] = s
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(map[string]interface{}{
+		"$schema": SchemaDialect,
+		"title":   "The metadata of the verdicts",
+		"$defs":   defs,
+	})
+}
+
+// schemaOf maps the Go types to the JSON Schema of their JSON form.
+//
+// The struct fields are described by their `human` tags, and they are required (and not empty) when they have the `mandatory` validation.
+// The email, ip, gte, and lte validations become their JSON Schema equivalents.
+func schemaOf(t reflect.Type) (map[string]interface{}, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		if err := structSchema(t, properties, &required); err != nil {
+			return nil, err
+		}
+
+		res := map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			res["required"] = required
+		}
+
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// structSchema collects the properties of the fields of the struct, flattening the embedded structs as encoding/json does.
+func structSchema(t reflect.Type, properties map[string]interface{}, required *[]string) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			if err := structSchema(f.Type, properties, required); err != nil {
+				return err
+			}
+
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s, err := schemaOf(f.Type)
+		if err != nil {
+			return fmt.Errorf("couldn't map field %s: %w", f.Name, err)
+		}
+		if human := f.Tag.Get("human"); human != "" {
+			s["description"] = human
+		}
+		rules := strings.Split(f.Tag.Get("validate"), ",")
+		for _, rule := range rules {
+			rule, param, _ := strings.Cut(rule, "=")
+			switch rule {
+			case "email":
+				s["format"] = "email"
+			case "ip":
+				s["anyOf"] = []interface{}{
+					map[string]interface{}{"format": "ipv4"},
+					map[string]interface{}{"format": "ipv6"},
+				}
+			case "gte":
+				s["minimum"] = json.Number(param)
+			case "lte":
+				s["maximum"] = json.Number(param)
+			}
+		}
+		properties[name] = s
+		if rules[0] == "mandatory" {
+			*required = append(*required, name)
+			if s["type"] == "string" {
+				s["minLength"] = 1
+			}
+		}
+	}
+
+	return nil
+}
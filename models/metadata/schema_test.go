@@ -0,0 +1,83 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+const schemasGolden = "schemas.json"
+
+func TestWriteSchemas(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, WriteSchemas(&buf))
+
+	if *updateGolden {
+		require.Nil(t, os.MkdirAll("testdata", 0o755))
+		require.Nil(t, os.WriteFile(path.Join("testdata", schemasGolden), buf.Bytes(), 0o600))
+	}
+
+	want, err := os.ReadFile(path.Join("testdata", schemasGolden))
+	require.Nil(t, err)
+	assert.Equal(t, string(want), buf.String())
+}
+
+func TestSchema(t *testing.T) {
+	s, err := Schema(verdictcode.FNI001)
+	require.Nil(t, err)
+	assert.Equal(t, SchemaDialect, s["$schema"])
+	assert.Equal(t, "The metadata of the FNI001 verdicts", s["title"])
+	assert.Equal(t, "Process spawned during the npm install", s["description"])
+	assert.Equal(t, []string{"executable_path"}, s["required"])
+	assert.Equal(t, false, s["additionalProperties"])
+}
+
+// The schemas agree with Decode.
+func TestSchemaValidation(t *testing.T) {
+	compile := func(t *testing.T, code verdictcode.Code) *jsonschema.Schema {
+		t.Helper()
+
+		s, err := Schema(code)
+		require.Nil(t, err)
+		data, err := json.Marshal(s)
+		require.Nil(t, err)
+		c := jsonschema.NewCompiler()
+		c.AssertFormat = true
+		require.Nil(t, c.AddResource(code.String()+".json", bytes.NewReader(data)))
+
+		return c.MustCompile(code.String() + ".json")
+	}
+
+	for _, tc := range validCases {
+		t.Run(tc.code.String(), func(t *testing.T) {
+			s := compile(t, tc.code)
+			data, err := json.Marshal(tc.metadata)
+			require.Nil(t, err)
+			var doc interface{}
+			require.Nil(t, json.Unmarshal(data, &doc))
+			if doc == nil {
+				doc = map[string]interface{}{}
+			}
+			assert.Nil(t, s.Validate(doc))
+		})
+	}
+
+	invalid := map[string]interface{}{"email": "someone"}
+	assert.Error(t, compile(t, verdictcode.MDP04).Validate(invalid))
+	invalid = map[string]interface{}{"executable_path": "/bin/sh", "color": "red"}
+	assert.Error(t, compile(t, verdictcode.FNI001).Validate(invalid))
+	invalid = map[string]interface{}{"executable_path": ""}
+	assert.Error(t, compile(t, verdictcode.FNI001).Validate(invalid))
+	invalid = map[string]interface{}{"file": "x.py", "ip": "1.1.1"}
+	assert.Error(t, compile(t, verdictcode.STN010).Validate(invalid))
+}
@@ -0,0 +1,121 @@
+package metadata
+
+// Ancestor is a process of the tree an event comes from.
+type Ancestor struct {
+	PID            int    `json:"pid,omitempty" human:"the process ID"`
+	Name           string `json:"name" human:"the process name" validate:"mandatory"`
+	ExecutablePath string `json:"executable_path,omitempty" human:"the executable path"`
+	Commandline    string `json:"commandline,omitempty" human:"the command line"`
+}
+
+// Process is the process the dynamic instrumentation caught doing something, during the install of a package.
+type Process struct {
+	NPMPackageName    string `json:"npm_package_name,omitempty" human:"the name of the npm package being installed"`
+	NPMPackageVersion string `json:"npm_package_version,omitempty" human:"the version of the npm package being installed"`
+	PID               int    `json:"pid,omitempty" human:"the process ID"`
+	ExecutablePath    string `json:"executable_path" human:"the executable path" validate:"mandatory"`
+	Commandline       string `json:"commandline,omitempty" human:"the command line"`
+	ParentName        string `json:"parent_name,omitempty" human:"the parent process name"`
+	// FileDescriptor, ServerIP, and ServerPort are what the process used, when any, whatever the event
+	FileDescriptor string `json:"file_descriptor,omitempty" human:"the file descriptor"`
+	ServerIP       string `json:"server_ip,omitempty" human:"the server IP address" validate:"omitempty,ip"`
+	ServerPort     int    `json:"server_port,omitempty" human:"the server port" validate:"omitempty,gte=0,lte=65535"`
+	// Ancestors is the process tree, from the parent process to the root one
+	Ancestors []Ancestor `json:"ancestors,omitempty" human:"the process tree" validate:"dive"`
+}
+
+// ProcessEvent is the metadata of the verdicts about spawned processes.
+type ProcessEvent struct {
+	Process
+}
+
+// FileEvent is the metadata of the verdicts about files.
+type FileEvent struct {
+	Process
+	File   string `json:"file" human:"the file path" validate:"mandatory"`
+	Flags  string `json:"flags,omitempty" human:"the flags the file was opened with"`
+	Target string `json:"target,omitempty" human:"the link target"`
+}
+
+// NetworkEvent is the metadata of the verdicts about connections.
+//
+// The remote end of the connection is the server IP address and port of the process.
+type NetworkEvent struct {
+	Process
+	Protocol string `json:"protocol,omitempty" human:"the protocol"`
+}
+
+// DNSEvent is the metadata of the verdicts about name resolutions.
+type DNSEvent struct {
+	Process
+	Domain  string   `json:"domain" human:"the resolved domain" validate:"mandatory"`
+	Answers []string `json:"answers,omitempty" human:"the resolved addresses"`
+}
+
+// Position is a position in a source file.
+type Position struct {
+	Line   int `json:"line" human:"the line" validate:"mandatory,gte=1"`
+	Col    int `json:"col,omitempty" human:"the column"`
+	Offset int `json:"offset,omitempty" human:"the offset"`
+}
+
+// Static is the metadata of the verdicts of the static analysis, about a snippet of a source file.
+type Static struct {
+	File    string    `json:"file" human:"the source file" validate:"mandatory"`
+	Start   *Position `json:"start,omitempty" human:"the start position"`
+	End     *Position `json:"end,omitempty" human:"the end position"`
+	Snippet string    `json:"snippet,omitempty" human:"the code snippet"`
+}
+
+// Link is the metadata of the verdicts of the static analysis about shady links.
+type Link struct {
+	Static
+	Domain string `json:"domain" human:"the link domain" validate:"mandatory"`
+	URL    string `json:"url,omitempty" human:"the link URL"`
+}
+
+// IP is the metadata of the verdicts of the static analysis about hard-coded IP addresses.
+type IP struct {
+	Static
+	IP string `json:"ip" human:"the IP address" validate:"mandatory,ip"`
+}
+
+// Dependency is the metadata of the verdicts about the dependencies not coming from the registry.
+type Dependency struct {
+	Dependency string `json:"dependency" human:"the dependency name" validate:"mandatory"`
+	URL        string `json:"url" human:"the dependency URL" validate:"mandatory"`
+}
+
+// Typosquat is the metadata of the verdicts about packages whose names are similar to popular ones.
+type Typosquat struct {
+	Target   string `json:"target" human:"the name of the popular package" validate:"mandatory"`
+	Distance int    `json:"distance" human:"the edit distance from the name of the popular package" validate:"mandatory,gte=1"`
+	// Downloads is the popularity of the target package, when known
+	Downloads int64 `json:"downloads,omitempty" human:"the downloads of the popular package"`
+}
+
+// Mismatch is the metadata of the verdicts about the differences between the package in the registry and its tarball.
+type Mismatch[T any] struct {
+	Registry T `json:"registry" human:"the value in the registry"`
+	Tarball  T `json:"tarball" human:"the value in the tarball"`
+}
+
+// Maintainer is the metadata of the verdicts about the email domains of the maintainers.
+type Maintainer struct {
+	Name   string `json:"name,omitempty" human:"the maintainer name"`
+	Email  string `json:"email" human:"the maintainer email" validate:"mandatory,email"`
+	Domain string `json:"domain,omitempty" human:"the email domain"`
+}
+
+// Advisory is the metadata of the verdicts about security advisories.
+type Advisory struct {
+	ID                 string  `json:"id" human:"the advisory ID" validate:"mandatory"`
+	Title              string  `json:"title,omitempty" human:"the advisory title"`
+	URL                string  `json:"url,omitempty" human:"the advisory URL"`
+	Severity           string  `json:"severity,omitempty" human:"the advisory severity"`
+	CVSS               float64 `json:"cvss,omitempty" human:"the CVSS score" validate:"omitempty,gte=0,lte=10"`
+	VulnerableVersions string  `json:"vulnerable_versions,omitempty" human:"the vulnerable versions"`
+}
+
+// None is the metadata of the verdicts that have none.
+type None struct{}
@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/invopop/yaml"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Policy is a set of suppression rules.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Load reads a policy in YAML or JSON, and validates its rules.
+func Load(r io.Reader) (*Policy, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the policy: %w", err)
+	}
+	p := &Policy{}
+	// YAML is a superset of JSON
+	if err := yaml.Unmarshal(data, p, disallowUnknownFields); err != nil {
+		return nil, fmt.Errorf("couldn't parse the policy: %w", err)
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// LoadFile reads the policy (see Load) from the given YAML or JSON file.
+func LoadFile(name string) (*Policy, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+func disallowUnknownFields(d *json.Decoder) *json.Decoder {
+	d.DisallowUnknownFields()
+
+	return d
+}
+
+// Suppression is a verdict suppressed by a rule.
+type Suppression struct {
+	Verdict models.Verdict `json:"verdict"`
+	Rule    Rule           `json:"rule"`
+}
+
+// Result partitions the verdicts into the active ones and the suppressed ones.
+type Result struct {
+	Active     models.Verdicts `json:"active"`
+	Suppressed []Suppression   `json:"suppressed"`
+}
+
+type Option func(*Engine)
+
+// WithNow is an option to set the function returning the current time, which the rules expire against.
+func WithNow(now func() time.Time) Option {
+	return func(e *Engine) {
+		e.now = now
+	}
+}
+
+// Engine applies the suppression rules to the verdicts.
+type Engine struct {
+	rules []*compiledRule
+	now   func() time.Time
+}
+
+// New returns an engine applying the given rules, in order.
+func New(rules []Rule, options ...Option) (*Engine, error) {
+	e := &Engine{
+		now: time.Now,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	for _, r := range rules {
+		c, err := r.compile()
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, c)
+	}
+
+	return e, nil
+}
+
+// Apply partitions the verdicts into the active ones and the ones suppressed by the rules.
+//
+// Every suppressed verdict records the first unexpired rule matching it.
+// The empty verdicts are always active.
+func (e *Engine) Apply(verdicts models.Verdicts) Result {
+	now := e.now()
+	res := Result{
+		Active:     models.Verdicts{},
+		Suppressed: []Suppression{},
+	}
+	for i := range verdicts {
+		if r := e.match(&verdicts[i], now); r != nil {
+			res.Suppressed = append(res.Suppressed, Suppression{Verdict: verdicts[i], Rule: *r})
+
+			continue
+		}
+		res.Active = append(res.Active, verdicts[i])
+	}
+
+	return res
+}
+
+func (e *Engine) match(v *models.Verdict, now time.Time) *Rule {
+	if v.Code == verdictcode.UNK {
+		return nil
+	}
+	for _, r := range e.rules {
+		if !r.HasExpired(now) && r.matches(v) {
+			return r.Rule
+		}
+	}
+
+	return nil
+}
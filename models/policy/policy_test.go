@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerdict(org, pkg, version, file string, code verdictcode.Code, s severity.Severity, categories ...category.Category) models.Verdict {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return models.Verdict{
+		Ecosystem:   ecosystem.Npm,
+		Org:         org,
+		Pkg:         pkg,
+		Version:     version,
+		Digest:      "0123456789012345678901234567890123456789",
+		File:        file,
+		Code:        code,
+		Severity:    s,
+		Message:     code.String() + " on " + pkg,
+		Categories:  categories,
+		Fingerprint: "fp-" + pkg + "-" + version,
+		CreatedAt:   &now,
+		Metadata:    map[string]interface{}{"file": "index.js"},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	yml, err := LoadFile("testdata/policy.yml")
+	require.Nil(t, err)
+	jsn, err := LoadFile("testdata/policy.json")
+	require.Nil(t, err)
+	assert.Equal(t, yml, jsn)
+
+	require.Len(t, yml.Rules, 3)
+	r := yml.Rules[0]
+	assert.Equal(t, "vetted-vue-devtools", r.ID)
+	assert.Equal(t, ecosystem.Npm, r.Ecosystem)
+	assert.Equal(t, verdictcode.STN001, r.Code)
+	assert.Equal(t, "security@example.com", r.Owner)
+	require.NotNil(t, r.ExpiresAt)
+	assert.Equal(t, time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC), *r.ExpiresAt)
+	assert.Equal(t, category.Network, yml.Rules[1].Category)
+	assert.Equal(t, severity.Low, yml.Rules[1].Severity)
+	assert.Nil(t, yml.Rules[1].ExpiresAt)
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":     `rules: [{code: STN001, reason: r, owner: o, nope: 1}]`,
+		"unknown code":      `rules: [{code: XYZ999, reason: r, owner: o}]`,
+		"missing reason":    `rules: [{code: STN001, owner: o}]`,
+		"missing owner":     `rules: [{code: STN001, reason: r}]`,
+		"no matchers":       `rules: [{reason: r, owner: o}]`,
+		"bad glob":          `rules: [{package: "[", reason: r, owner: o}]`,
+		"bad version range": `rules: [{versions: ">>1", reason: r, owner: o}]`,
+		"bad expiry":        `rules: [{code: STN001, reason: r, owner: o, expires_at: tomorrow}]`,
+		"not a policy":      `[1, 2]`,
+	}
+	for name, input := range cases {
+		_, err := Load(strings.NewReader(input))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestApply(t *testing.T) {
+	p, err := LoadFile("testdata/policy.yml")
+	require.Nil(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fingerprinted := newVerdict("", "lodash", "4.17.21", "static(exfiltrate_env).json", verdictcode.STN001, severity.High)
+	canonical, err := fingerprinted.ComputeFingerprint()
+	require.Nil(t, err)
+	rules := append(p.Rules,
+		Rule{ID: "by-fingerprint", Fingerprint: "fp-axios-1.6.0", Reason: "r", Owner: "o"},
+		Rule{ID: "by-canonical-fingerprint", Fingerprint: canonical, Reason: "r", Owner: "o"},
+	)
+	e, err := New(rules, WithNow(func() time.Time { return now }))
+	require.Nil(t, err)
+
+	verdicts := models.Verdicts{
+		newVerdict("@vue", "devtools", "6.5.0", "static(exfiltrate_env).json", verdictcode.STN001, severity.High),
+		newVerdict("@vue", "devtools", "7.0.0", "static(exfiltrate_env).json", verdictcode.STN001, severity.High),
+		newVerdict("@nuxt", "devtools", "6.5.0", "static(exfiltrate_env).json", verdictcode.STN001, severity.High),
+		newVerdict("", "chalk", "5.1.2", "static(shady_links).json", verdictcode.STN003, severity.Low, category.Network),
+		newVerdict("", "chalk", "5.1.2", "static(shady_links).json", verdictcode.STN003, severity.Medium, category.Network),
+		newVerdict("", "axios", "1.6.0", "static(shady_links).json", verdictcode.STN003, severity.Medium),
+		fingerprinted,
+		{Ecosystem: ecosystem.Npm, Pkg: "empty", Version: "1.0.0"},
+	}
+	res := e.Apply(verdicts)
+
+	assert.Equal(t, models.Verdicts{verdicts[1], verdicts[2], verdicts[4], verdicts[7]}, res.Active)
+	require.Len(t, res.Suppressed, 4)
+	want := map[int]string{0: "vetted-vue-devtools", 3: "shady-links-docs", 5: "by-fingerprint", 6: "by-canonical-fingerprint"}
+	i := 0
+	for _, idx := range []int{0, 3, 5, 6} {
+		assert.Equal(t, verdicts[idx], res.Suppressed[i].Verdict)
+		assert.Equal(t, want[idx], res.Suppressed[i].Rule.ID)
+		i++
+	}
+
+	// Once the rule expires the verdicts it suppressed become active
+	later, err := New(p.Rules, WithNow(func() time.Time { return time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC) }))
+	require.Nil(t, err)
+	res = later.Apply(verdicts[:1])
+	assert.Equal(t, verdicts[:1], res.Active)
+	assert.Empty(t, res.Suppressed)
+}
+
+func TestApplyPyPIVersions(t *testing.T) {
+	pypi := func(version string) models.Verdict {
+		v := newVerdict("", "boto3", version, "static(exfiltrate_env).json", verdictcode.STN001, severity.High)
+		v.Ecosystem = ecosystem.Pypi
+
+		return v
+	}
+	cases := []struct {
+		versions string
+		want     map[string]bool
+	}{
+		{">=2.0rc1", map[string]bool{"1.9": false, "2.0.dev1": false, "2.0rc1": true, "2.0": true, "2.0.post1": true}},
+		{"~=1.4", map[string]bool{"1.3": false, "1.4": true, "1.9.2": true, "2.0": false}},
+		{"~=1.4.5", map[string]bool{"1.4.4": false, "1.4.5": true, "1.4.9": true, "1.5": false}},
+		{"~=1.4.5.1", map[string]bool{"1.4.5.1": true, "1.4.5.9": true, "1.4.6": false}},
+		{">=1.0, !=1.5.*", map[string]bool{"0.9": false, "1.4.9": true, "1.5": false, "1.5.3": false, "1.6": true}},
+		{"==1.0.post1", map[string]bool{"1.0.post1": true, "1.1": false}},
+		{"==1.*", map[string]bool{"1.0": true, "1.7.1": true, "2.0": false}},
+		{"<1.0a1", map[string]bool{"0.9": true, "1.0": false}},
+	}
+	for _, tc := range cases {
+		e, err := New([]Rule{{Ecosystem: ecosystem.Pypi, Versions: tc.versions, Reason: "r", Owner: "o"}})
+		require.Nil(t, err, tc.versions)
+		for version, suppressed := range tc.want {
+			res := e.Apply(models.Verdicts{pypi(version)})
+			assert.Equal(t, suppressed, len(res.Suppressed) == 1, "%s %s", tc.versions, version)
+		}
+	}
+
+	for _, versions := range []string{"^1.0.0", "===1.0", "~=1", ">=1.*", ">=1.0 <2.0", "==1.0.x"} {
+		_, err := New([]Rule{{Ecosystem: ecosystem.Pypi, Versions: versions, Reason: "r", Owner: "o"}})
+		assert.Error(t, err, versions)
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	assert.Equal(t, "id", (&Rule{ID: "id"}).String())
+	r := Rule{Ecosystem: ecosystem.Pypi, Package: "boto*", Versions: "~=1.0", Code: verdictcode.STN001, Category: category.CIS, Severity: severity.High, Fingerprint: "x"}
+	assert.Equal(t, "ecosystem=pypi,package=boto*,versions=~=1.0,code=STN001,category=cis,severity=high,fingerprint=x", r.String())
+}
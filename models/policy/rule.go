@@ -0,0 +1,262 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/history"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/validate"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Rule suppresses the verdicts it matches until it expires.
+//
+// A verdict matches the rule when it matches all of its non-empty matchers.
+type Rule struct {
+	// ID identifies the rule (eg., in the reports about the suppressed verdicts)
+	ID string `json:"id,omitempty"`
+	// Ecosystem is the ecosystem of the packages
+	Ecosystem ecosystem.Ecosystem `json:"ecosystem,omitempty"`
+	// Package is a glob (see path.Match) of the package names, including their organization (eg., @vue/*)
+	Package string `json:"package,omitempty"`
+	// Versions is a range of the package versions (eg., >=1.2.0 <2.0.0, ^1.2.0).
+	//
+	// The rules about PyPI packages use the PEP 440 version specifiers instead (eg., ~=1.4, >=2.0rc1, !=1.5.*).
+	Versions string `json:"versions,omitempty"`
+	// Code is the verdict code
+	Code verdictcode.Code `json:"code,omitempty"`
+	// Category is one of the verdict categories, or a broader one including it (eg., network includes adjacent network)
+	Category category.Category `json:"category,omitempty"`
+	// Severity is the verdict severity
+	Severity severity.Severity `json:"severity,omitempty"`
+	// Fingerprint is the verdict fingerprint, or its canonical one (see models.Verdict.ComputeFingerprint)
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Reason explains why the verdicts are suppressed
+	Reason string `json:"reason" human:"the reason" validate:"mandatory"`
+	// Owner is who is accountable for the suppression
+	Owner string `json:"owner" human:"the owner" validate:"mandatory"`
+	// ExpiresAt is when the rule stops suppressing the verdicts (RFC3339)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Validate checks the rule has a reason, an owner, and at least one valid matcher.
+func (r *Rule) Validate() error {
+	_, err := r.compile()
+
+	return err
+}
+
+// HasExpired tells whether the rule expired at the given time.
+func (r *Rule) HasExpired(at time.Time) bool {
+	return r.ExpiresAt != nil && !at.Before(*r.ExpiresAt)
+}
+
+// String returns the ID of the rule, or its matchers.
+func (r *Rule) String() string {
+	if r.ID != "" {
+		return r.ID
+	}
+	parts := []string{}
+	if r.Ecosystem != ecosystem.None {
+		parts = append(parts, "ecosystem="+r.Ecosystem.Case())
+	}
+	if r.Package != "" {
+		parts = append(parts, "package="+r.Package)
+	}
+	if r.Versions != "" {
+		parts = append(parts, "versions="+r.Versions)
+	}
+	if r.Code != verdictcode.UNK {
+		parts = append(parts, "code="+r.Code.String())
+	}
+	if r.Category != 0 {
+		parts = append(parts, "category="+string(r.Category.Case()))
+	}
+	if r.Severity != severity.Empty {
+		parts = append(parts, "severity="+r.Severity.String())
+	}
+	if r.Fingerprint != "" {
+		parts = append(parts, "fingerprint="+r.Fingerprint)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// compiledRule is a rule with its version range parsed.
+type compiledRule struct {
+	*Rule
+	versions *semver.Constraints
+}
+
+func (r *Rule) compile() (*compiledRule, error) {
+	all := append([]error{}, validate.Validate(r)...)
+	if r.Ecosystem == ecosystem.None && r.Package == "" && r.Versions == "" && r.Code == verdictcode.UNK &&
+		r.Category == 0 && r.Severity == severity.Empty && r.Fingerprint == "" {
+		all = append(all, errors.New("the rule must have at least one matcher"))
+	}
+	if r.Package != "" {
+		if _, err := path.Match(r.Package, ""); err != nil {
+			all = append(all, fmt.Errorf("the package glob %q is not valid", r.Package))
+		}
+	}
+	c := &compiledRule{Rule: r}
+	if r.Versions != "" {
+		versions, err := parseVersions(r.Ecosystem, r.Versions)
+		if err != nil {
+			all = append(all, fmt.Errorf("the version range %q is not valid: %w", r.Versions, err))
+		}
+		c.versions = versions
+	}
+
+	if len(all) > 0 {
+		ret := "validation error"
+		if len(all) > 1 {
+			ret += "s"
+		}
+		ret += " in rule " + r.String() + ": "
+		for i, e := range all {
+			if i > 0 {
+				ret += "; "
+			}
+			ret += e.Error()
+		}
+
+		return nil, fmt.Errorf("%s", ret)
+	}
+
+	return c, nil
+}
+
+// matches tells whether the verdict matches all the matchers of the rule.
+func (c *compiledRule) matches(v *models.Verdict) bool {
+	if c.Ecosystem != ecosystem.None && c.Ecosystem != v.Ecosystem {
+		return false
+	}
+	if c.Code != verdictcode.UNK && c.Code != v.Code {
+		return false
+	}
+	if c.Severity != severity.Empty && c.Severity != v.Severity {
+		return false
+	}
+	if c.Category != 0 && !hasCategory(v, c.Category) {
+		return false
+	}
+	if c.Package != "" {
+		name := v.Pkg
+		if v.Org != "" {
+			name = v.Org + "/" + v.Pkg
+		}
+		if ok, _ := path.Match(c.Package, name); !ok {
+			return false
+		}
+	}
+	if c.versions != nil {
+		version, err := parseVersion(v.Ecosystem, v.Version)
+		if err != nil || !c.versions.Check(version) {
+			return false
+		}
+	}
+	if c.Fingerprint != "" && c.Fingerprint != v.Fingerprint {
+		fingerprint, err := v.ComputeFingerprint()
+		if err != nil || c.Fingerprint != fingerprint {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasCategory(v *models.Verdict, c category.Category) bool {
+	for _, x := range v.Categories {
+		if x.Is(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseVersion parses the version of a package of the given ecosystem.
+func parseVersion(e ecosystem.Ecosystem, v string) (*semver.Version, error) {
+	if e == ecosystem.Pypi {
+		return history.ParseVersion(v)
+	}
+
+	return semver.NewVersion(v)
+}
+
+// pep440Specifier matches a PEP 440 version specifier, capturing its operator, and its version.
+var pep440Specifier = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*(\S+)$`)
+
+// pep440Release matches the release segment of a PEP 440 version.
+var pep440Release = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)`)
+
+// parseVersions parses a range of versions of the packages of the given ecosystem.
+//
+// It converts the comma-separated PEP 440 version specifiers of PyPI to semantic version constraints,
+// parsing their versions like history.ParseVersion.
+func parseVersions(e ecosystem.Ecosystem, versions string) (*semver.Constraints, error) {
+	if e != ecosystem.Pypi {
+		return semver.NewConstraint(versions)
+	}
+
+	constraints := []string{}
+	for _, specifier := range strings.Split(versions, ",") {
+		specifier = strings.TrimSpace(specifier)
+		m := pep440Specifier.FindStringSubmatch(specifier)
+		if m == nil {
+			return nil, fmt.Errorf("invalid PEP 440 version specifier %q", specifier)
+		}
+		op, version := m[1], m[2]
+		switch op {
+		case "===":
+			return nil, fmt.Errorf("the arbitrary equality of %q is not supported", specifier)
+		case "==":
+			op = "="
+		}
+		switch {
+		case strings.HasSuffix(version, ".*"):
+			// The prefix matching of == and != (eg., !=1.5.* excludes 1.5.0 and 1.5.1)
+			prefix := strings.TrimSuffix(version, ".*")
+			if (op != "=" && op != "!=") || pep440Release.FindString(prefix) != prefix {
+				return nil, fmt.Errorf("invalid PEP 440 version specifier %q", specifier)
+			}
+			constraints = append(constraints, op+prefix+".x")
+		case op == "~=":
+			// The compatible releases (eg., ~=1.4.5 means >=1.4.5, ==1.4.*)
+			lower, err := history.ParseVersion(version)
+			if err != nil {
+				return nil, err
+			}
+			var upper *semver.Version
+			switch strings.Count(pep440Release.FindString(version), ".") {
+			case 1:
+				upper = semver.New(lower.Major()+1, 0, 0, "", "")
+			case 2:
+				upper = semver.New(lower.Major(), lower.Minor()+1, 0, "", "")
+			case 3:
+				upper = semver.New(lower.Major(), lower.Minor(), lower.Patch()+1, "", "")
+			default:
+				return nil, fmt.Errorf("invalid PEP 440 version specifier %q", specifier)
+			}
+			constraints = append(constraints, ">="+lower.String(), "<"+upper.String())
+		default:
+			v, err := history.ParseVersion(version)
+			if err != nil {
+				return nil, err
+			}
+			constraints = append(constraints, op+v.String())
+		}
+	}
+
+	return semver.NewConstraint(strings.Join(constraints, ", "))
+}
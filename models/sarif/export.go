@@ -0,0 +1,243 @@
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+const (
+	// DefaultToolName is the default name of the tool the results come from.
+	DefaultToolName = "listendev"
+	// DefaultInformationURI is the default URI of the documentation of the tool.
+	DefaultInformationURI = "https://listen.dev"
+	// FingerprintKey is the key of the partial fingerprints of the results.
+	FingerprintKey = "verdictFingerprint/v1"
+)
+
+type Option func(*exporter)
+
+// WithTool is an option to set the name and the version of the tool.
+func WithTool(name, version string) Option {
+	return func(e *exporter) {
+		e.name = name
+		e.version = version
+	}
+}
+
+// WithInformationURI is an option to set the URI of the documentation of the tool.
+func WithInformationURI(uri string) Option {
+	return func(e *exporter) {
+		e.informationURI = uri
+	}
+}
+
+// WithLocator is an option to set how to locate the verdicts.
+//
+// The verdicts it does not locate are located at the default lockfile of their ecosystem (see DefaultLocator).
+func WithLocator(locator Locator) Option {
+	return func(e *exporter) {
+		e.locator = locator
+	}
+}
+
+type exporter struct {
+	name           string
+	version        string
+	informationURI string
+	locator        Locator
+}
+
+// LevelOf returns the SARIF level of the given severity.
+func LevelOf(s severity.Severity) Level {
+	switch s {
+	case severity.Critical, severity.High:
+		return LevelError
+	case severity.Medium:
+		return LevelWarning
+	case severity.Low:
+		return LevelNote
+	}
+
+	return LevelNone
+}
+
+// Export converts the verdicts into a SARIF log with a single run.
+//
+// Every verdict code becomes a rule, whose level is the one of the most severe of its verdicts
+// and whose tags are the categories of its verdicts.
+// Every verdict becomes a result, located by the locator (see WithLocator) and partially fingerprinted
+// by its canonical fingerprint (see models.Verdict.ComputeFingerprint).
+// The empty verdicts are skipped.
+func Export(verdicts models.Verdicts, options ...Option) (*Log, error) {
+	e := &exporter{
+		name:           DefaultToolName,
+		informationURI: DefaultInformationURI,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+
+	rules := []ReportingDescriptor{}
+	highest := []severity.Severity{}
+	ruleIndex := map[verdictcode.Code]int{}
+	results := []Result{}
+	for _, v := range verdicts {
+		if v.Code == verdictcode.UNK {
+			continue
+		}
+
+		idx, ok := ruleIndex[v.Code]
+		if !ok {
+			idx = len(rules)
+			ruleIndex[v.Code] = idx
+			rules = append(rules, newRule(v))
+			highest = append(highest, severity.Empty)
+		}
+		updateRule(&rules[idx], &highest[idx], v)
+
+		result, err := e.result(v, idx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return &Log{
+		Schema:  Schema,
+		Version: Version,
+		Runs: []Run{
+			{
+				Tool: Tool{
+					Driver: Driver{
+						Name:           e.name,
+						Version:        e.version,
+						InformationURI: e.informationURI,
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}, nil
+}
+
+// Write exports the verdicts (see Export) and writes the resulting SARIF log as indented JSON.
+func Write(w io.Writer, verdicts models.Verdicts, options ...Option) error {
+	log, err := Export(verdicts, options...)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(log)
+}
+
+func newRule(v models.Verdict) ReportingDescriptor {
+	r := ReportingDescriptor{
+		ID:                   v.Code.String(),
+		DefaultConfiguration: &Configuration{Level: LevelNone},
+		Properties: PropertyBag{
+			"tags": []string{"security"},
+		},
+	}
+	// Describe the rule with its catalog entry, when any
+	if info, err := v.Code.Info(); err == nil {
+		r.Name = info.Title
+		r.ShortDescription = &Message{Text: info.Title}
+		r.FullDescription = &Message{Text: info.Description}
+		r.Help = &MultiformatText{Text: info.Remediation}
+		if len(info.References) > 0 {
+			r.HelpURI = info.References[0]
+		}
+
+		return r
+	}
+
+	description := fmt.Sprintf("Verdict %s", v.Code)
+	if t, err := v.Code.Type(false); err == nil {
+		description = fmt.Sprintf("Verdict %s reported by the %s analysis.", v.Code, t)
+	}
+	short := v.Message
+	if short == "" {
+		short = description
+	}
+	r.ShortDescription = &Message{Text: short}
+	r.FullDescription = &Message{Text: description}
+
+	return r
+}
+
+// updateRule raises the level and the security severity of the rule to the ones of the verdict, when higher than the highest severity of the rule,
+// and adds its categories to the rule tags.
+func updateRule(r *ReportingDescriptor, highest *severity.Severity, v models.Verdict) {
+	if levelRank(LevelOf(v.Severity)) > levelRank(r.DefaultConfiguration.Level) {
+		r.DefaultConfiguration.Level = LevelOf(v.Severity)
+	}
+	// GitHub code scanning ranks the security alerts by their security severity (a CVSS score)
+	if score, ok := v.Severity.CVSS(); ok && v.Severity.Higher(*highest) {
+		*highest = v.Severity
+		r.Properties["security-severity"] = fmt.Sprintf("%.1f", score)
+	}
+	tags, _ := r.Properties["tags"].([]string)
+	for _, c := range v.Categories {
+		if tag := string(c.Case()); !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	r.Properties["tags"] = tags
+}
+
+func levelRank(l Level) int {
+	return slices.Index([]Level{LevelNone, LevelNote, LevelWarning, LevelError}, l)
+}
+
+func (e *exporter) result(v models.Verdict, ruleIndex int) (Result, error) {
+	fingerprint, err := v.ComputeFingerprint()
+	if err != nil {
+		return Result{}, fmt.Errorf("couldn't export verdict %s: %w", v.Code, err)
+	}
+
+	message := v.Message
+	if message == "" {
+		message = v.Code.String()
+	}
+
+	loc, ok := Location{}, false
+	if e.locator != nil {
+		loc, ok = e.locator(v)
+	}
+	if !ok {
+		loc = DefaultLocator(v)
+	}
+
+	properties := PropertyBag{
+		"ecosystem": v.Ecosystem.Case(),
+		"pkg":       v.Pkg,
+		"version":   v.Version,
+		"digest":    v.Digest,
+		"file":      v.File,
+	}
+	if v.Org != "" {
+		properties["org"] = v.Org
+	}
+	if len(v.Metadata) > 0 {
+		properties["metadata"] = v.Metadata
+	}
+
+	return Result{
+		RuleID:              v.Code.String(),
+		RuleIndex:           ruleIndex,
+		Level:               LevelOf(v.Severity),
+		Message:             Message{Text: message},
+		Locations:           []Location{loc},
+		PartialFingerprints: map[string]string{FingerprintKey: fingerprint},
+		Properties:          properties,
+	}, nil
+}
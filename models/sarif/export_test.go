@@ -0,0 +1,190 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerdict(t *testing.T, org, pkg, file string, code verdictcode.Code, sev severity.Severity, cats ...category.Category) models.Verdict {
+	t.Helper()
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := models.Verdict{
+		Ecosystem:  ecosystem.Npm,
+		Org:        org,
+		Pkg:        pkg,
+		Version:    "5.1.2",
+		Digest:     "d957f370038b75ac572471e83be4c5ca9f8e8c45",
+		File:       file,
+		CreatedAt:  &created,
+		Code:       code,
+		Message:    pkg + " " + code.String(),
+		Severity:   sev,
+		Categories: cats,
+		Metadata:   map[string]interface{}{},
+	}
+	if !code.UniquelyIdentifies() {
+		v.Metadata["executable_path"] = "/bin/" + string(sev)
+		require.Nil(t, v.SetFingerprint())
+	}
+	require.Nil(t, v.Validate())
+
+	return v
+}
+
+func fixtures(t *testing.T) models.Verdicts {
+	t.Helper()
+
+	return models.Verdicts{
+		newVerdict(t, "", "chalk", "typosquat.json", verdictcode.TSN01, severity.Medium, category.Cybersquatting),
+		newVerdict(t, "", "chalk", "dynamic!install!.json", verdictcode.FNI001, severity.Low, category.Process),
+		newVerdict(t, "", "chalk", "dynamic!install!.json", verdictcode.FNI001, severity.High, category.Process, category.Network),
+		newVerdict(t, "@vue", "devtools", "dynamic!install!.json", verdictcode.FNI002, severity.Medium, category.Network),
+		{Ecosystem: ecosystem.Npm, Pkg: "empty", Version: "1.0.0", File: "typosquat.json"},
+	}
+}
+
+func validateSchema(t *testing.T, data []byte) {
+	t.Helper()
+
+	schema, err := os.Open(path.Join("testdata", "sarif-schema-2.1.0.json"))
+	require.Nil(t, err)
+	defer schema.Close()
+
+	compiler := jsonschema.NewCompiler()
+	require.Nil(t, compiler.AddResource(Schema, schema))
+	s, err := compiler.Compile(Schema)
+	require.Nil(t, err)
+
+	var doc interface{}
+	require.Nil(t, json.Unmarshal(data, &doc))
+	assert.Nil(t, s.Validate(doc))
+}
+
+func TestExport(t *testing.T) {
+	verdicts := fixtures(t)
+
+	log, err := Export(verdicts, WithTool("lstn", "1.2.3"))
+	require.Nil(t, err)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "lstn", run.Tool.Driver.Name)
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+	assert.Equal(t, DefaultInformationURI, run.Tool.Driver.InformationURI)
+
+	rules := run.Tool.Driver.Rules
+	require.Len(t, rules, 3)
+	assert.Equal(t, "TSN01", rules[0].ID)
+	// The catalog describes the rule
+	assert.Equal(t, "Potential typosquat of a popular npm package", rules[0].Name)
+	assert.Equal(t, "Potential typosquat of a popular npm package", rules[0].ShortDescription.Text)
+	assert.Contains(t, rules[0].FullDescription.Text, "very similar to the name of a popular one")
+	assert.Equal(t, "Check the name of the package is the one you meant to install.", rules[0].Help.Text)
+	assert.Equal(t, "https://attack.mitre.org/techniques/T1195/002/", rules[0].HelpURI)
+	assert.Equal(t, LevelWarning, rules[0].DefaultConfiguration.Level)
+	assert.Equal(t, "5.5", rules[0].Properties["security-severity"])
+	assert.Equal(t, []string{"security", "cybersquatting"}, rules[0].Properties["tags"])
+	// The most severe verdict sets the level
+	assert.Equal(t, "FNI001", rules[1].ID)
+	assert.Equal(t, LevelError, rules[1].DefaultConfiguration.Level)
+	assert.Equal(t, "8.0", rules[1].Properties["security-severity"])
+	assert.Equal(t, []string{"security", "process", "network"}, rules[1].Properties["tags"])
+	assert.Equal(t, "FNI002", rules[2].ID)
+
+	// The empty verdict is skipped
+	results := run.Results
+	require.Len(t, results, 4)
+	levels := []Level{}
+	indexes := []int{}
+	for _, r := range results {
+		levels = append(levels, r.Level)
+		indexes = append(indexes, r.RuleIndex)
+	}
+	assert.Equal(t, []Level{LevelWarning, LevelNote, LevelError, LevelWarning}, levels)
+	assert.Equal(t, []int{0, 1, 1, 2}, indexes)
+
+	devtools := results[3]
+	assert.Equal(t, "FNI002", devtools.RuleID)
+	assert.Equal(t, "devtools FNI002", devtools.Message.Text)
+	assert.Equal(t, NewLocation("package-lock.json", 0), devtools.Locations[0])
+	assert.Equal(t, verdicts[3].Fingerprint, devtools.PartialFingerprints[FingerprintKey])
+	assert.Equal(t, "@vue", devtools.Properties["org"])
+	assert.Equal(t, "npm", devtools.Properties["ecosystem"])
+
+	var b bytes.Buffer
+	require.Nil(t, Write(&b, verdicts))
+	validateSchema(t, b.Bytes())
+}
+
+func TestExportEmpty(t *testing.T) {
+	var b bytes.Buffer
+	require.Nil(t, Write(&b, nil))
+	validateSchema(t, b.Bytes())
+
+	var log Log
+	require.Nil(t, json.Unmarshal(b.Bytes(), &log))
+	assert.Equal(t, Version, log.Version)
+	assert.Empty(t, log.Runs[0].Results)
+	assert.Empty(t, log.Runs[0].Tool.Driver.Rules)
+}
+
+func TestExportWithLocator(t *testing.T) {
+	content, err := os.ReadFile(path.Join("testdata", "package-lock.json"))
+	require.Nil(t, err)
+
+	var b bytes.Buffer
+	require.Nil(t, Write(&b, fixtures(t), WithLocator(FileLocator("app/package-lock.json", content))))
+	validateSchema(t, b.Bytes())
+
+	var log Log
+	require.Nil(t, json.Unmarshal(b.Bytes(), &log))
+	assert.Equal(t, NewLocation("app/package-lock.json", 23), log.Runs[0].Results[0].Locations[0])
+	assert.Equal(t, NewLocation("app/package-lock.json", 15), log.Runs[0].Results[3].Locations[0])
+}
+
+func TestExportError(t *testing.T) {
+	v := fixtures(t)[0]
+	v.Metadata = map[string]interface{}{"target": make(chan int)}
+
+	_, err := Export(models.Verdicts{v})
+	assert.Error(t, err)
+}
+
+func TestLevelOf(t *testing.T) {
+	assert.Equal(t, LevelError, LevelOf(severity.Critical))
+	assert.Equal(t, LevelError, LevelOf(severity.High))
+	assert.Equal(t, LevelWarning, LevelOf(severity.Medium))
+	assert.Equal(t, LevelNote, LevelOf(severity.Low))
+	assert.Equal(t, LevelNone, LevelOf(severity.Info))
+	assert.Equal(t, LevelNone, LevelOf(severity.Empty))
+}
+
+func TestExportSecuritySeverity(t *testing.T) {
+	verdicts := models.Verdicts{
+		newVerdict(t, "", "chalk", "dynamic!install!.json", verdictcode.FNI001, severity.High, category.Process),
+		newVerdict(t, "", "chalk", "dynamic!install!.json", verdictcode.FNI001, severity.Critical, category.Process),
+		newVerdict(t, "", "chalk", "dynamic!install!.json", verdictcode.FNI001, severity.Medium, category.Process),
+		newVerdict(t, "", "chalk", "dynamic!install!.json", verdictcode.FNI002, severity.Info, category.Process),
+	}
+	log, err := Export(verdicts)
+	require.Nil(t, err)
+	rules := log.Runs[0].Tool.Driver.Rules
+	require.Len(t, rules, 2)
+	assert.Equal(t, LevelError, rules[0].DefaultConfiguration.Level)
+	assert.Equal(t, "9.5", rules[0].Properties["security-severity"])
+	assert.Equal(t, LevelNone, rules[1].DefaultConfiguration.Level)
+	assert.Equal(t, "0.0", rules[1].Properties["security-severity"])
+}
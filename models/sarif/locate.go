@@ -0,0 +1,169 @@
+package sarif
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/lockfile"
+	"github.com/listendev/pkg/manifest"
+	"github.com/listendev/pkg/models"
+)
+
+// Locator locates a verdict, if it can.
+type Locator func(v models.Verdict) (Location, bool)
+
+// NewLocation returns the location of the given line (starting at 1) of the given file.
+//
+// The location has no region when the line is unknown (ie., not positive).
+func NewLocation(uri string, line int) Location {
+	loc := Location{
+		PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{URI: uri},
+		},
+	}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &Region{StartLine: line}
+	}
+
+	return loc
+}
+
+// DefaultLocator locates the verdicts at the default lockfile of their ecosystem,
+// or at their results file when their ecosystem has no lockfiles.
+func DefaultLocator(v models.Verdict) Location {
+	if lockfiles := lockfile.FromEcosystem(v.Ecosystem); len(lockfiles) > 0 {
+		return NewLocation(lockfiles[0].String(), 0)
+	}
+
+	return NewLocation(v.File, 0)
+}
+
+// FileLocator locates the verdicts at the given lockfile or manifest, at the line declaring their package when found.
+//
+// It does not locate the verdicts of the ecosystems other than the one of the file,
+// nor any verdict when the file is neither a supported lockfile nor a supported manifest.
+func FileLocator(uri string, content []byte) Locator {
+	var eco ecosystem.Ecosystem
+	var find func(content []byte, v models.Verdict) int
+	if l, err := lockfile.FromPath(uri); err == nil {
+		eco = lockfile.Ecosystem(l)
+		switch l {
+		case lockfile.PackageLockJSON:
+			find = findPackageLockJSON
+		case lockfile.PoetryLock:
+			find = findPoetryLock
+		}
+	} else if m, err := manifest.FromPath(uri); err == nil {
+		eco = manifest.Ecosystem(m)
+		if m == manifest.PackageJSON {
+			find = findPackageJSON
+		}
+	}
+
+	return func(v models.Verdict) (Location, bool) {
+		if eco == ecosystem.None || v.Ecosystem != eco {
+			return Location{}, false
+		}
+		line := 0
+		if find != nil {
+			line = find(content, v)
+		}
+
+		return NewLocation(uri, line), true
+	}
+}
+
+func npmName(v models.Verdict) string {
+	if v.Org != "" {
+		return v.Org + "/" + v.Pkg
+	}
+
+	return v.Pkg
+}
+
+// findPackageLockJSON returns the line of the "node_modules/<name>" key of the package,
+// preferring the one having the version of the verdict.
+func findPackageLockJSON(content []byte, v models.Verdict) int {
+	key := "node_modules/" + npmName(v) + `":`
+	version := `"version": ` + strconv.Quote(v.Version)
+
+	first, candidate := 0, 0
+	scanner := newLineScanner(content)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, `"`) && strings.Contains(line, key) &&
+			(strings.HasPrefix(line, `"`+key) || strings.Contains(line, "/"+key)):
+			candidate = n
+			if first == 0 {
+				first = n
+			}
+		case candidate > 0 && strings.HasPrefix(line, version):
+			return candidate
+		case strings.HasPrefix(line, "}"):
+			candidate = 0
+		}
+	}
+	if scanner.Err() != nil {
+		return 0
+	}
+
+	return first
+}
+
+// findPoetryLock returns the line of the name of the package, preferring the one having the version of the verdict.
+func findPoetryLock(content []byte, v models.Verdict) int {
+	normalize := strings.NewReplacer("_", "-", ".", "-")
+	name := normalize.Replace(strings.ToLower(v.Pkg))
+	version := "version = " + strconv.Quote(v.Version)
+
+	first, candidate := 0, 0
+	scanner := newLineScanner(content)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "name = "):
+			candidate = 0
+			if unquoted, err := strconv.Unquote(strings.TrimPrefix(line, "name = ")); err == nil &&
+				normalize.Replace(strings.ToLower(unquoted)) == name {
+				candidate = n
+				if first == 0 {
+					first = n
+				}
+			}
+		case candidate > 0 && line == version:
+			return candidate
+		}
+	}
+	if scanner.Err() != nil {
+		return 0
+	}
+
+	return first
+}
+
+// findPackageJSON returns the line of the first dependency on the package.
+func findPackageJSON(content []byte, v models.Verdict) int {
+	key := strconv.Quote(npmName(v)) + ":"
+
+	scanner := newLineScanner(content)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), key) {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// newLineScanner returns a scanner of the lines of the given content, however long they are (eg., minified ones).
+func newLineScanner(content []byte) *bufio.Scanner {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	// No line is longer than the content
+	scanner.Buffer(nil, max(len(content)+1, bufio.MaxScanTokenSize))
+
+	return scanner
+}
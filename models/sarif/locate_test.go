@@ -0,0 +1,78 @@
+package sarif
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLocator(t *testing.T) {
+	read := func(name string) []byte {
+		t.Helper()
+
+		content, err := os.ReadFile(path.Join("testdata", name))
+		require.Nil(t, err)
+
+		return content
+	}
+	npm := func(org, pkg, version string) models.Verdict {
+		return models.Verdict{Ecosystem: ecosystem.Npm, Org: org, Pkg: pkg, Version: version}
+	}
+	pypi := func(pkg, version string) models.Verdict {
+		return models.Verdict{Ecosystem: ecosystem.Pypi, Pkg: pkg, Version: version}
+	}
+
+	cases := []struct {
+		name    string
+		uri     string
+		file    string
+		verdict models.Verdict
+		want    Location
+		found   bool
+	}{
+		{"package-lock.json", "package-lock.json", "package-lock.json", npm("", "chalk", "5.1.2"), NewLocation("package-lock.json", 23), true},
+		{"package-lock.json nested", "package-lock.json", "package-lock.json", npm("", "chalk", "4.1.2"), NewLocation("package-lock.json", 19), true},
+		{"package-lock.json other version", "package-lock.json", "package-lock.json", npm("", "chalk", "1.0.0"), NewLocation("package-lock.json", 19), true},
+		{"package-lock.json scoped", "package-lock.json", "package-lock.json", npm("@vue", "devtools", "6.5.0"), NewLocation("package-lock.json", 15), true},
+		{"package-lock.json missing", "package-lock.json", "package-lock.json", npm("", "react", "18.0.0"), NewLocation("package-lock.json", 0), true},
+		{"package-lock.json other ecosystem", "package-lock.json", "package-lock.json", pypi("requests", "2.31.0"), Location{}, false},
+		{"poetry.lock", "poetry.lock", "poetry.lock", pypi("requests", "2.31.0"), NewLocation("poetry.lock", 2), true},
+		{"poetry.lock normalized", "poetry.lock", "poetry.lock", pypi("Typing_Extensions", "4.9.0"), NewLocation("poetry.lock", 8), true},
+		{"poetry.lock other ecosystem", "poetry.lock", "poetry.lock", npm("", "chalk", "5.1.2"), Location{}, false},
+		{"package.json", "package.json", "package.json", npm("", "chalk", "5.1.2"), NewLocation("package.json", 6), true},
+		{"package.json scoped", "package.json", "package.json", npm("@vue", "devtools", "6.5.0"), NewLocation("package.json", 5), true},
+		{"unsupported", "yarn.lock", "package-lock.json", npm("", "chalk", "5.1.2"), Location{}, false},
+	}
+	for _, tc := range cases {
+		got, found := FileLocator(tc.uri, read(tc.file))(tc.verdict)
+		assert.Equal(t, tc.found, found, tc.name)
+		assert.Equal(t, tc.want, got, tc.name)
+	}
+}
+
+func TestFileLocatorLongLines(t *testing.T) {
+	long := `"integrity": "` + strings.Repeat("x", 256*1024) + `",`
+	content := []byte("{\n" + `  "packages": {` + "\n" + long + "\n" + `    "node_modules/chalk": {` + "\n" + `      "version": "5.1.2",` + "\n" + long + "\n    }\n  }\n}\n")
+	v := models.Verdict{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2"}
+
+	got, found := FileLocator("package-lock.json", content)(v)
+	assert.True(t, found)
+	assert.Equal(t, NewLocation("package-lock.json", 4), got)
+
+	content = []byte(long + "\n" + `"chalk": "^5.1.2"` + "\n")
+	got, found = FileLocator("package.json", content)(v)
+	assert.True(t, found)
+	assert.Equal(t, NewLocation("package.json", 2), got)
+}
+
+func TestDefaultLocator(t *testing.T) {
+	assert.Equal(t, NewLocation("package-lock.json", 0), DefaultLocator(models.Verdict{Ecosystem: ecosystem.Npm}))
+	assert.Equal(t, NewLocation("poetry.lock", 0), DefaultLocator(models.Verdict{Ecosystem: ecosystem.Pypi}))
+	assert.Equal(t, NewLocation("typosquat.json", 0), DefaultLocator(models.Verdict{File: "typosquat.json"}))
+}
@@ -0,0 +1,106 @@
+package sarif
+
+// Version is the version of SARIF this package produces.
+const Version = "2.1.0"
+
+// Schema is the URI of the JSON schema of SARIF 2.1.0.
+const Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// Log is the top-level object of a SARIF file.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run describes a single run of an analysis tool and its results.
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Tool describes the analysis tool.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver describes the component of the tool containing its rules.
+type Driver struct {
+	Name           string                `json:"name"`
+	Version        string                `json:"version,omitempty"`
+	InformationURI string                `json:"informationUri,omitempty"`
+	Rules          []ReportingDescriptor `json:"rules"`
+}
+
+// ReportingDescriptor describes a rule.
+type ReportingDescriptor struct {
+	ID                   string           `json:"id"`
+	Name                 string           `json:"name,omitempty"`
+	ShortDescription     *Message         `json:"shortDescription,omitempty"`
+	FullDescription      *Message         `json:"fullDescription,omitempty"`
+	DefaultConfiguration *Configuration   `json:"defaultConfiguration,omitempty"`
+	Properties           PropertyBag      `json:"properties,omitempty"`
+	Help                 *MultiformatText `json:"help,omitempty"`
+	HelpURI              string           `json:"helpUri,omitempty"`
+}
+
+// Configuration is the default configuration of a rule.
+type Configuration struct {
+	Level Level `json:"level"`
+}
+
+// Message is a plain text message.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// MultiformatText is a message in plain text and, optionally, in markdown.
+type MultiformatText struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+// Result is a finding.
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               Level             `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          PropertyBag       `json:"properties,omitempty"`
+}
+
+// Location is where a result was found.
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is a location in an artifact.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation identifies an artifact (eg., a file) by its URI.
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a region of an artifact.
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// PropertyBag holds the additional properties of an object.
+type PropertyBag map[string]interface{}
+
+// Level is the level of a result.
+type Level string
+
+const (
+	LevelNone    Level = "none"
+	LevelNote    Level = "note"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
@@ -0,0 +1,208 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+)
+
+// CycloneDXSpecVersion is the version of the CycloneDX specification this package produces.
+const CycloneDXSpecVersion = "1.5"
+
+// CycloneDX is a CycloneDX JSON document.
+type CycloneDX struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        CycloneDXMetadata        `json:"metadata"`
+	Components      []CycloneDXComponent     `json:"components"`
+	Vulnerabilities []CycloneDXVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// CycloneDXMetadata is the metadata of a CycloneDX document.
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp"`
+	Tools     CycloneDXTools      `json:"tools"`
+	Component *CycloneDXComponent `json:"component,omitempty"`
+}
+
+// CycloneDXTools are the tools that created a CycloneDX document.
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXComponent is a component of a CycloneDX document.
+type CycloneDXComponent struct {
+	Type    string          `json:"type"`
+	BOMRef  string          `json:"bom-ref,omitempty"`
+	Group   string          `json:"group,omitempty"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	PURL    string          `json:"purl,omitempty"`
+	Hashes  []CycloneDXHash `json:"hashes,omitempty"`
+}
+
+// CycloneDXHash is the hash of a component.
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// CycloneDXVulnerability is a vulnerability (a verdict) of a CycloneDX document.
+type CycloneDXVulnerability struct {
+	ID          string              `json:"id"`
+	Source      CycloneDXSource     `json:"source"`
+	Ratings     []CycloneDXRating   `json:"ratings,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Created     string              `json:"created,omitempty"`
+	Affects     []CycloneDXAffect   `json:"affects"`
+	Properties  []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+// CycloneDXSource is the source of a vulnerability.
+type CycloneDXSource struct {
+	Name string `json:"name"`
+}
+
+// CycloneDXRating is the severity rating of a vulnerability.
+type CycloneDXRating struct {
+	Severity string `json:"severity"`
+	Method   string `json:"method"`
+}
+
+// CycloneDXAffect references the component a vulnerability affects.
+type CycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+// CycloneDXProperty is a name-value pair.
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Those are the names of the properties of the CycloneDX vulnerabilities.
+const (
+	CycloneDXPropertyFile        = "listendev:file"
+	CycloneDXPropertyFingerprint = "listendev:fingerprint"
+	CycloneDXPropertyCategory    = "listendev:category"
+)
+
+var cycloneDXHashAlgorithms = map[ecosystem.Ecosystem]string{
+	ecosystem.Npm:  "SHA-1",
+	ecosystem.Pypi: "BLAKE2b-256",
+}
+
+// NewCycloneDX returns the CycloneDX document of the dependency set.
+//
+// Every dependency becomes a library component identified by its package URL with its digest (see Dependency.BOMRef),
+// and every verdict about it becomes a vulnerability affecting it.
+// The packages the verdicts are about become components too, when missing from the dependency set.
+//
+// The references of the distinct dependencies having the same normalized package URL get a #<n> suffix,
+// since the references must be unique.
+func NewCycloneDX(set Set, options ...Option) (*CycloneDX, error) {
+	g := newGenerator(options...)
+	now := g.now().UTC().Format(time.RFC3339)
+
+	doc := &CycloneDX{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  CycloneDXSpecVersion,
+		SerialNumber: "urn:uuid:" + g.newUUID().String(),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: now,
+			Tools: CycloneDXTools{
+				Components: []CycloneDXComponent{{Type: "application", Name: g.toolName, Version: g.toolVersion}},
+			},
+		},
+		Components: []CycloneDXComponent{},
+	}
+	if set.Name != "" {
+		doc.Metadata.Component = &CycloneDXComponent{Type: "application", Name: set.Name}
+	}
+
+	deps, verdicts := set.findings()
+	refs := map[string]int{}
+	for i, d := range deps {
+		purl, err := d.PURL()
+		if err != nil {
+			return nil, err
+		}
+		ref, err := d.BOMRef()
+		if err != nil {
+			return nil, err
+		}
+		refs[ref]++
+		if n := refs[ref]; n > 1 {
+			ref = fmt.Sprintf("%s#%d", ref, n)
+		}
+		c := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  ref,
+			Name:    d.Pkg,
+			Version: d.Version,
+			PURL:    purl,
+		}
+		if d.Ecosystem == ecosystem.Npm {
+			c.Group = d.Org
+		}
+		if alg, ok := cycloneDXHashAlgorithms[d.Ecosystem]; ok && d.Digest != "" {
+			c.Hashes = []CycloneDXHash{{Algorithm: alg, Content: d.Digest}}
+		}
+		doc.Components = append(doc.Components, c)
+
+		for _, v := range verdicts[i] {
+			doc.Vulnerabilities = append(doc.Vulnerabilities, g.cycloneDXVulnerability(ref, v))
+		}
+	}
+
+	return doc, nil
+}
+
+func (g *generator) cycloneDXVulnerability(ref string, v models.Verdict) CycloneDXVulnerability {
+	vuln := CycloneDXVulnerability{
+		ID:          v.Code.String(),
+		Source:      CycloneDXSource{Name: g.toolName},
+		Description: v.Message,
+		Affects:     []CycloneDXAffect{{Ref: ref}},
+		Properties:  []CycloneDXProperty{{Name: CycloneDXPropertyFile, Value: v.File}},
+	}
+	if v.Severity != severity.Empty {
+		vuln.Ratings = []CycloneDXRating{{Severity: v.Severity.String(), Method: "other"}}
+	}
+	if v.CreatedAt != nil {
+		vuln.Created = v.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if v.Fingerprint != "" {
+		vuln.Properties = append(vuln.Properties, CycloneDXProperty{Name: CycloneDXPropertyFingerprint, Value: v.Fingerprint})
+	}
+	for _, c := range v.Categories {
+		vuln.Properties = append(vuln.Properties, CycloneDXProperty{Name: CycloneDXPropertyCategory, Value: string(c.Case())})
+	}
+
+	return vuln
+}
+
+// WriteCycloneDX writes the CycloneDX document of the dependency set (see NewCycloneDX) as indented JSON.
+func WriteCycloneDX(w io.Writer, set Set, options ...Option) error {
+	doc, err := NewCycloneDX(set, options...)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, doc)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
@@ -0,0 +1,177 @@
+package sbom
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/purl"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+const (
+	// DefaultToolName is the default name of the tool generating the SBOMs.
+	DefaultToolName = "listendev"
+	// DefaultNamespace is the default base of the SPDX document namespaces.
+	DefaultNamespace = "https://listen.dev/spdx"
+)
+
+// Dependency is a package of the dependency set.
+type Dependency struct {
+	Ecosystem ecosystem.Ecosystem `json:"ecosystem"`
+	Org       string              `json:"org,omitempty"`
+	Pkg       string              `json:"pkg"`
+	Version   string              `json:"version"`
+	// Digest is the shasum of the npm packages, or the BLAKE2b-256 digest of the pypi packages
+	Digest string `json:"digest,omitempty"`
+}
+
+// Name returns the name of the package, including its organization (eg., @vue/devtools).
+func (d Dependency) Name() string {
+	if d.Org != "" && d.Ecosystem == ecosystem.Npm {
+		return d.Org + "/" + d.Pkg
+	}
+
+	return d.Pkg
+}
+
+// PURL returns the package URL of the dependency.
+//
+// It does not carry the digest since the documents list it among the hashes of the dependency.
+func (d Dependency) PURL() (string, error) {
+	return d.purl("")
+}
+
+// BOMRef returns the package URL of the dependency with its digest as checksum.
+//
+// It identifies the dependency in the CycloneDX documents,
+// where the same package can come with different digests (eg., when a verdict is about another tarball).
+func (d Dependency) BOMRef() (string, error) {
+	return d.purl(d.Digest)
+}
+
+func (d Dependency) purl(digest string) (string, error) {
+	org := d.Org
+	if d.Ecosystem != ecosystem.Npm {
+		org = ""
+	}
+	p, err := purl.New(d.Ecosystem, org, d.Pkg, d.Version, digest)
+	if err != nil {
+		return "", fmt.Errorf("couldn't compose the package URL of %s@%s: %w", d.Name(), d.Version, err)
+	}
+
+	return p.String(), nil
+}
+
+// matches tells whether the verdict is about the dependency.
+func (d Dependency) matches(v models.Verdict) bool {
+	if d.Ecosystem != v.Ecosystem || d.Org != v.Org || d.Pkg != v.Pkg || d.Version != v.Version {
+		return false
+	}
+
+	return d.Digest == "" || v.Digest == "" || d.Digest == v.Digest
+}
+
+// Set is a dependency set, optionally with the verdicts about its dependencies.
+type Set struct {
+	// Name identifies what the dependency set belongs to (eg., the project)
+	Name         string
+	Dependencies []Dependency
+	Verdicts     models.Verdicts
+}
+
+// findings returns the dependencies, including the ones missing from the set the verdicts are about,
+// and the verdicts about each of them.
+//
+// It skips the duplicated dependencies, and the empty verdicts.
+func (s Set) findings() ([]Dependency, [][]models.Verdict) {
+	deps := []Dependency{}
+	for _, d := range s.Dependencies {
+		if !slices.Contains(deps, d) {
+			deps = append(deps, d)
+		}
+	}
+	verdicts := make([][]models.Verdict, len(deps))
+	for _, v := range s.Verdicts {
+		if v.Code == verdictcode.UNK {
+			continue
+		}
+		i := 0
+		for ; i < len(deps); i++ {
+			if deps[i].matches(v) {
+				break
+			}
+		}
+		if i == len(deps) {
+			deps = append(deps, Dependency{Ecosystem: v.Ecosystem, Org: v.Org, Pkg: v.Pkg, Version: v.Version, Digest: v.Digest})
+			verdicts = append(verdicts, nil)
+		}
+		verdicts[i] = append(verdicts[i], v)
+	}
+
+	return deps, verdicts
+}
+
+type Option func(*generator)
+
+// WithTool is an option to set the name and the version of the tool generating the SBOMs.
+func WithTool(name, version string) Option {
+	return func(g *generator) {
+		g.toolName = name
+		g.toolVersion = version
+	}
+}
+
+// WithNow is an option to set the function returning the creation time of the SBOMs.
+func WithNow(now func() time.Time) Option {
+	return func(g *generator) {
+		g.now = now
+	}
+}
+
+// WithUUID is an option to set the function returning the UUIDs identifying the SBOMs.
+func WithUUID(newUUID func() uuid.UUID) Option {
+	return func(g *generator) {
+		g.newUUID = newUUID
+	}
+}
+
+// WithNamespace is an option to set the base of the SPDX document namespaces.
+func WithNamespace(namespace string) Option {
+	return func(g *generator) {
+		g.namespace = namespace
+	}
+}
+
+type generator struct {
+	toolName    string
+	toolVersion string
+	namespace   string
+	now         func() time.Time
+	newUUID     func() uuid.UUID
+}
+
+func newGenerator(options ...Option) *generator {
+	g := &generator{
+		toolName:  DefaultToolName,
+		namespace: DefaultNamespace,
+		now:       time.Now,
+		newUUID:   uuid.New,
+	}
+	for _, opt := range options {
+		opt(g)
+	}
+
+	return g
+}
+
+func (g *generator) tool() string {
+	if g.toolVersion == "" {
+		return g.toolName
+	}
+
+	return g.toolName + "-" + g.toolVersion
+}
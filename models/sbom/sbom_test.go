@@ -0,0 +1,200 @@
+package sbom
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/purl"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+var testNow = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testOptions() []Option {
+	return []Option{
+		WithTool("lstn", "1.2.3"),
+		WithNow(func() time.Time { return testNow }),
+		WithUUID(func() uuid.UUID { return uuid.MustParse("8a4c2b9e-1d3f-4e5a-9b6c-7d8e9f0a1b2c") }),
+	}
+}
+
+func newVerdict(t *testing.T, org, pkg, version, file string, code verdictcode.Code, sev severity.Severity, cats ...category.Category) models.Verdict {
+	t.Helper()
+
+	created := testNow.Add(-time.Hour)
+	v := models.Verdict{
+		Ecosystem:  ecosystem.Npm,
+		Org:        org,
+		Pkg:        pkg,
+		Version:    version,
+		Digest:     "d957f370038b75ac572471e83be4c5ca9f8e8c45",
+		File:       file,
+		CreatedAt:  &created,
+		Code:       code,
+		Message:    pkg + " " + code.String(),
+		Severity:   sev,
+		Categories: cats,
+		Metadata:   map[string]interface{}{},
+	}
+	if !code.UniquelyIdentifies() {
+		v.Metadata["executable_path"] = "/bin/sh"
+		require.Nil(t, v.SetFingerprint())
+	}
+	require.Nil(t, v.Validate())
+
+	return v
+}
+
+func testSet(t *testing.T) Set {
+	t.Helper()
+
+	return Set{
+		Name: "listendev/app",
+		Dependencies: []Dependency{
+			{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2", Digest: "d957f370038b75ac572471e83be4c5ca9f8e8c45"},
+			{Ecosystem: ecosystem.Npm, Org: "@vue", Pkg: "devtools", Version: "6.5.0", Digest: "6bd9f4cfa95a39fb0c4fa7e7a7fbbfbe0c0cb4d0"},
+			{Ecosystem: ecosystem.Pypi, Pkg: "Typing_Extensions", Version: "4.9.0", Digest: "af72aea155e91adfc61c3ae9e0e342dbc0cba726d6cba4b6c72c1f34e47291cd"},
+		},
+		Verdicts: models.Verdicts{
+			newVerdict(t, "", "chalk", "5.1.2", "typosquat.json", verdictcode.TSN01, severity.Medium, category.Cybersquatting),
+			newVerdict(t, "", "chalk", "5.1.2", "dynamic!install!.json", verdictcode.FNI001, severity.High, category.Process, category.Network),
+			// Not in the dependency set
+			newVerdict(t, "", "react", "18.2.0", "typosquat.json", verdictcode.TSN01, severity.Low, category.Cybersquatting),
+			// Empty
+			{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2", File: "typosquat.json"},
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := path.Join("testdata", name)
+	if *updateGolden {
+		require.Nil(t, os.MkdirAll("testdata", 0o755))
+		require.Nil(t, os.WriteFile(golden, got, 0o600))
+	}
+	want, err := os.ReadFile(golden)
+	require.Nil(t, err)
+	assert.JSONEq(t, string(want), string(got))
+}
+
+func TestCycloneDX(t *testing.T) {
+	doc, err := NewCycloneDX(testSet(t), testOptions()...)
+	require.Nil(t, err)
+	assert.Equal(t, "urn:uuid:8a4c2b9e-1d3f-4e5a-9b6c-7d8e9f0a1b2c", doc.SerialNumber)
+	require.Len(t, doc.Components, 4)
+	assert.Equal(t, "pkg:npm/%40vue/devtools@6.5.0", doc.Components[1].PURL)
+	assert.Equal(t, "@vue", doc.Components[1].Group)
+	assert.Equal(t, "pkg:npm/react@18.2.0", doc.Components[3].PURL)
+	require.Len(t, doc.Vulnerabilities, 3)
+	assert.Equal(t, "pkg:npm/chalk@5.1.2?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45", doc.Vulnerabilities[1].Affects[0].Ref)
+
+	var b bytes.Buffer
+	require.Nil(t, WriteCycloneDX(&b, testSet(t), testOptions()...))
+	assertGolden(t, "cyclonedx.json", b.Bytes())
+}
+
+func TestSPDX(t *testing.T) {
+	doc, err := NewSPDX(testSet(t), testOptions()...)
+	require.Nil(t, err)
+	assert.Equal(t, "https://listen.dev/spdx/listendev-app-8a4c2b9e-1d3f-4e5a-9b6c-7d8e9f0a1b2c", doc.DocumentNamespace)
+	require.Len(t, doc.Packages, 4)
+	assert.Equal(t, "SPDXRef-Package-2-vue-devtools-6.5.0", doc.Packages[1].SPDXID)
+	assert.Len(t, doc.Packages[0].Annotations, 2)
+	assert.Empty(t, doc.Packages[1].Annotations)
+	assert.Len(t, doc.Relationships, 4)
+
+	var b bytes.Buffer
+	require.Nil(t, WriteSPDX(&b, testSet(t), testOptions()...))
+	assertGolden(t, "spdx.json", b.Bytes())
+}
+
+func TestEmptySet(t *testing.T) {
+	cdx, err := NewCycloneDX(Set{}, testOptions()...)
+	require.Nil(t, err)
+	assert.Empty(t, cdx.Components)
+	assert.Nil(t, cdx.Metadata.Component)
+	assert.Empty(t, cdx.Vulnerabilities)
+
+	spdx, err := NewSPDX(Set{}, testOptions()...)
+	require.Nil(t, err)
+	assert.Equal(t, "dependencies", spdx.Name)
+	assert.Empty(t, spdx.Packages)
+}
+
+func TestDependencyPURL(t *testing.T) {
+	cases := []struct {
+		dep  Dependency
+		want string
+	}{
+		{Dependency{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2"}, "pkg:npm/chalk@5.1.2"},
+		{Dependency{Ecosystem: ecosystem.Npm, Org: "@vue", Pkg: "devtools", Version: "6.5.0"}, "pkg:npm/%40vue/devtools@6.5.0"},
+		{Dependency{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2-beta+build.1"}, "pkg:npm/chalk@5.1.2-beta%2Bbuild.1"},
+		{Dependency{Ecosystem: ecosystem.Pypi, Pkg: "Typing_Extensions", Version: "4.9.0"}, "pkg:pypi/typing-extensions@4.9.0"},
+	}
+	for _, tc := range cases {
+		got, err := tc.dep.PURL()
+		require.Nil(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+
+	_, err := Dependency{Ecosystem: ecosystem.Npm, Version: "1.0.0"}.PURL()
+	assert.ErrorContains(t, err, "missing name")
+}
+
+func TestCycloneDXReferences(t *testing.T) {
+	chalk := Dependency{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2", Digest: "d957f370038b75ac572471e83be4c5ca9f8e8c45"}
+	set := Set{
+		Dependencies: []Dependency{
+			chalk,
+			chalk,
+			{Ecosystem: ecosystem.Pypi, Pkg: "Typing_Extensions", Version: "4.9.0"},
+			{Ecosystem: ecosystem.Pypi, Pkg: "typing-extensions", Version: "4.9.0"},
+		},
+		Verdicts: models.Verdicts{
+			newVerdict(t, "", "chalk", "5.1.2", "typosquat.json", verdictcode.TSN01, severity.Medium, category.Cybersquatting),
+		},
+	}
+	// A verdict about another tarball of the same package
+	other := newVerdict(t, "", "chalk", "5.1.2", "typosquat.json", verdictcode.TSN01, severity.Medium, category.Cybersquatting)
+	other.Digest = "0000000000000000000000000000000000000000"
+	set.Verdicts = append(set.Verdicts, other)
+
+	doc, err := NewCycloneDX(set, testOptions()...)
+	require.Nil(t, err)
+
+	refs := []string{}
+	for _, c := range doc.Components {
+		refs = append(refs, c.BOMRef)
+	}
+	assert.Equal(t, []string{
+		"pkg:npm/chalk@5.1.2?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45",
+		"pkg:pypi/typing-extensions@4.9.0",
+		"pkg:pypi/typing-extensions@4.9.0#2",
+		"pkg:npm/chalk@5.1.2?checksum=sha1:0000000000000000000000000000000000000000",
+	}, refs)
+	assert.Equal(t, "pkg:npm/chalk@5.1.2", doc.Components[3].PURL)
+	require.Len(t, doc.Vulnerabilities, 2)
+	assert.Equal(t, refs[0], doc.Vulnerabilities[0].Affects[0].Ref)
+	assert.Equal(t, refs[3], doc.Vulnerabilities[1].Affects[0].Ref)
+
+	// No empty references
+	_, err = NewCycloneDX(Set{Dependencies: []Dependency{{Ecosystem: ecosystem.Npm, Version: "1.0.0"}}}, testOptions()...)
+	assert.ErrorIs(t, err, purl.ErrInvalid)
+	_, err = NewSPDX(Set{Dependencies: []Dependency{{Pkg: "chalk"}}}, testOptions()...)
+	assert.ErrorIs(t, err, purl.ErrInvalid)
+}
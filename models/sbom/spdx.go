@@ -0,0 +1,182 @@
+package sbom
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+)
+
+// SPDXVersion is the version of the SPDX specification this package produces.
+const SPDXVersion = "SPDX-2.3"
+
+// SPDXDocumentID is the SPDX identifier of the SPDX documents.
+const SPDXDocumentID = "SPDXRef-DOCUMENT"
+
+// SPDX is an SPDX JSON document.
+type SPDX struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXCreationInfo tells who created an SPDX document, and when.
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// SPDXPackage is a package of an SPDX document.
+type SPDXPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+	Annotations      []SPDXAnnotation  `json:"annotations,omitempty"`
+}
+
+// SPDXChecksum is the checksum of a package.
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// SPDXExternalRef is a reference to an external source of information about a package (eg., its package URL).
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// SPDXAnnotation is an annotation (a verdict) of a package.
+type SPDXAnnotation struct {
+	AnnotationDate string `json:"annotationDate"`
+	AnnotationType string `json:"annotationType"`
+	Annotator      string `json:"annotator"`
+	Comment        string `json:"comment"`
+}
+
+// SPDXRelationship is a relationship between two SPDX elements.
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxChecksumAlgorithms = map[ecosystem.Ecosystem]string{
+	ecosystem.Npm:  "SHA1",
+	ecosystem.Pypi: "BLAKE2b-256",
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// NewSPDX returns the SPDX document of the dependency set.
+//
+// Every dependency becomes a package the document describes, referencing its package URL,
+// and every verdict about it becomes a review annotation of the package.
+// The packages the verdicts are about become packages too, when missing from the dependency set.
+func NewSPDX(set Set, options ...Option) (*SPDX, error) {
+	g := newGenerator(options...)
+	now := g.now().UTC().Format(time.RFC3339)
+
+	name := set.Name
+	if name == "" {
+		name = "dependencies"
+	}
+	doc := &SPDX{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            SPDXDocumentID,
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", strings.TrimSuffix(g.namespace, "/"), spdxIDInvalidChars.ReplaceAllString(name, "-"), g.newUUID()),
+		CreationInfo: SPDXCreationInfo{
+			Created:  now,
+			Creators: []string{"Tool: " + g.tool()},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	deps, verdicts := set.findings()
+	for i, d := range deps {
+		purl, err := d.PURL()
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, strings.Trim(spdxIDInvalidChars.ReplaceAllString(d.Name()+"-"+d.Version, "-"), "-"))
+		p := SPDXPackage{
+			Name:             d.Name(),
+			SPDXID:           id,
+			VersionInfo:      d.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []SPDXExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl},
+			},
+		}
+		if alg, ok := spdxChecksumAlgorithms[d.Ecosystem]; ok && d.Digest != "" {
+			p.Checksums = []SPDXChecksum{{Algorithm: alg, ChecksumValue: d.Digest}}
+		}
+		for _, v := range verdicts[i] {
+			p.Annotations = append(p.Annotations, g.spdxAnnotation(v, now))
+		}
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementID:      SPDXDocumentID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return doc, nil
+}
+
+// spdxAnnotation returns the annotation of the verdict, whose comment is like "FNI001 (high): message [file]".
+func (g *generator) spdxAnnotation(v models.Verdict, now string) SPDXAnnotation {
+	date := now
+	if v.CreatedAt != nil {
+		date = v.CreatedAt.UTC().Format(time.RFC3339)
+	}
+
+	var comment strings.Builder
+	comment.WriteString(v.Code.String())
+	if v.Severity != severity.Empty {
+		comment.WriteString(" (" + v.Severity.String() + ")")
+	}
+	if v.Message != "" {
+		comment.WriteString(": " + v.Message)
+	}
+	comment.WriteString(" [" + v.File + "]")
+	if v.Fingerprint != "" {
+		comment.WriteString(" fingerprint=" + v.Fingerprint)
+	}
+
+	return SPDXAnnotation{
+		AnnotationDate: date,
+		AnnotationType: "REVIEW",
+		Annotator:      "Tool: " + g.tool(),
+		Comment:        comment.String(),
+	}
+}
+
+// WriteSPDX writes the SPDX document of the dependency set (see NewSPDX) as indented JSON.
+func WriteSPDX(w io.Writer, set Set, options ...Option) error {
+	doc, err := NewSPDX(set, options...)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, doc)
+}
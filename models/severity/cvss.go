@@ -0,0 +1,58 @@
+package severity
+
+import "fmt"
+
+// cvssRanges are the ranges of the CVSS base scores of the severities.
+//
+// They are the qualitative severity ratings of both CVSS v3.x and CVSS v4.0,
+// where the informational severity is the none rating.
+var cvssRanges = map[Severity][2]float64{
+	Info:     {0.0, 0.0},
+	Low:      {0.1, 3.9},
+	Medium:   {4.0, 6.9},
+	High:     {7.0, 8.9},
+	Critical: {9.0, 10.0},
+}
+
+// cvssScores are the representative CVSS base scores of the severities.
+var cvssScores = map[Severity]float64{
+	Info:     0.0,
+	Low:      2.0,
+	Medium:   5.5,
+	High:     8.0,
+	Critical: 9.5,
+}
+
+// FromCVSS returns the severity of the given CVSS v3.x or v4.0 base score.
+func FromCVSS(score float64) (Severity, error) {
+	if score < 0 || score > 10 {
+		return Empty, fmt.Errorf("the CVSS base score %.1f is not between 0.0 and 10.0", score)
+	}
+	// The base scores have one decimal
+	score = float64(int(score*10+0.5)) / 10
+	for _, s := range all {
+		if r, ok := cvssRanges[s]; ok && score >= r[0] && score <= r[1] {
+			return s, nil
+		}
+	}
+
+	return Empty, fmt.Errorf("the CVSS base score %.1f is not valid", score)
+}
+
+// CVSSRange returns the range of the CVSS v3.x and v4.0 base scores of the severity.
+//
+// It returns false for the empty severity.
+func (s Severity) CVSSRange() (min, max float64, ok bool) {
+	r, ok := cvssRanges[s]
+
+	return r[0], r[1], ok
+}
+
+// CVSS returns the representative CVSS v3.x and v4.0 base score of the severity (eg., 8.0 for high).
+//
+// It returns false for the empty severity.
+func (s Severity) CVSS() (float64, bool) {
+	score, ok := cvssScores[s]
+
+	return score, ok
+}
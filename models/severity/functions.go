@@ -1,24 +1,84 @@
 package severity
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"strings"
 )
 
+// all are the severities, from the lowest to the highest.
+var all = []Severity{Empty, Info, Low, Medium, High, Critical}
+
+// Severities returns the severities, from the lowest to the highest.
+func Severities() []Severity {
+	return append([]Severity{}, all...)
+}
+
 func (s Severity) String() string {
 	switch s {
+	case Info:
+		fallthrough
 	case Low:
 		fallthrough
 	case Medium:
 		fallthrough
 	case High:
+		fallthrough
+	case Critical:
 		return string(s)
 	}
 
 	return string(Empty)
 }
 
+// Rank returns the position of the severity in the ordering of the severities.
+//
+// The empty severity (and any unknown one) is the lowest.
+func (s Severity) Rank() int {
+	for i, x := range all {
+		if x == s {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// Compare returns -1, 0, or +1 depending on whether the severity is lower than, equal to, or higher than the other one.
+func (s Severity) Compare(other Severity) int {
+	switch a, b := s.Rank(), other.Rank(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// AtLeast tells whether the severity is equal to or higher than the other one.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.Compare(other) >= 0
+}
+
+// Higher tells whether the severity is higher than the other one.
+func (s Severity) Higher(other Severity) bool {
+	return s.Compare(other) > 0
+}
+
+// Max returns the highest of the given severities, or the empty one.
+func Max(severities ...Severity) Severity {
+	res := Empty
+	for _, s := range severities {
+		if s.Higher(res) {
+			res = s
+		}
+	}
+
+	return res
+}
+
 // Scan implements the sql.Scanner interface.
 func (s *Severity) Scan(source any) error {
 	if x, ok := source.(string); ok {
@@ -31,19 +91,32 @@ func (s *Severity) Scan(source any) error {
 		return nil
 	}
 
-	return fmt.Errorf("cannot scan %T into Category", source)
+	return fmt.Errorf("cannot scan %T into Severity", source)
+}
+
+// Value implements the driver.Valuer interface.
+func (s Severity) Value() (driver.Value, error) {
+	if _, err := New(string(s)); err != nil {
+		return nil, err
+	}
+
+	return s.String(), nil
 }
 
 func New(input string) (Severity, error) {
 	s := strings.ToLower(input)
 
 	switch s {
+	case Info.String():
+		return Info, nil
 	case Low.String():
 		return Low, nil
 	case Medium.String():
 		return Medium, nil
 	case High.String():
 		return High, nil
+	case Critical.String():
+		return Critical, nil
 	case Empty.String():
 		return Empty, nil
 	}
@@ -0,0 +1,121 @@
+package severity
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	for _, s := range Severities() {
+		a, err := New(s.String())
+		assert.Nil(t, err)
+		assert.Equal(t, s, a)
+	}
+	c, err := New("CRITICAL")
+	assert.Nil(t, err)
+	assert.Equal(t, Critical, c)
+
+	_, err = New("urgent")
+	assert.Error(t, err)
+}
+
+func TestOrdering(t *testing.T) {
+	assert.Equal(t, []Severity{Empty, Info, Low, Medium, High, Critical}, Severities())
+
+	severities := Severities()
+	for i, a := range severities {
+		for j, b := range severities {
+			switch {
+			case i < j:
+				assert.Equal(t, -1, a.Compare(b))
+				assert.False(t, a.AtLeast(b))
+				assert.True(t, b.Higher(a))
+			case i > j:
+				assert.Equal(t, 1, a.Compare(b))
+				assert.True(t, a.AtLeast(b))
+			default:
+				assert.Equal(t, 0, a.Compare(b))
+				assert.True(t, a.AtLeast(b))
+				assert.False(t, a.Higher(b))
+			}
+		}
+	}
+
+	assert.Equal(t, 0, Severity("unknown").Rank())
+	assert.Equal(t, Critical, Max(Low, Critical, Medium))
+	assert.Equal(t, Empty, Max())
+}
+
+func TestCVSS(t *testing.T) {
+	cases := map[float64]Severity{
+		0.0:  Info,
+		0.1:  Low,
+		3.9:  Low,
+		3.94: Low,
+		3.96: Medium,
+		4.0:  Medium,
+		6.9:  Medium,
+		7.0:  High,
+		8.9:  High,
+		9.0:  Critical,
+		10.0: Critical,
+	}
+	for score, want := range cases {
+		got, err := FromCVSS(score)
+		assert.Nil(t, err, score)
+		assert.Equal(t, want, got, score)
+	}
+	for _, score := range []float64{-0.1, 10.1} {
+		_, err := FromCVSS(score)
+		assert.Error(t, err, score)
+	}
+
+	for _, s := range Severities() {
+		score, ok := s.CVSS()
+		min, max, rangeOk := s.CVSSRange()
+		assert.Equal(t, ok, rangeOk)
+		if s == Empty {
+			assert.False(t, ok)
+
+			continue
+		}
+		require.True(t, ok)
+		assert.True(t, score >= min && score <= max, s)
+		back, err := FromCVSS(score)
+		assert.Nil(t, err)
+		assert.Equal(t, s, back)
+	}
+}
+
+func TestScanValue(t *testing.T) {
+	for _, s := range Severities() {
+		v, err := s.Value()
+		assert.Nil(t, err)
+		assert.Equal(t, s.String(), v)
+
+		var scanned Severity
+		assert.Nil(t, scanned.Scan(v))
+		assert.Equal(t, s, scanned)
+	}
+	_, err := Severity("urgent").Value()
+	assert.Error(t, err)
+
+	var s Severity
+	assert.Error(t, s.Scan(1))
+}
+
+func TestMarshal(t *testing.T) {
+	for _, s := range Severities() {
+		r, err := json.Marshal(s)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("%q", s), string(r))
+
+		var u Severity
+		assert.Nil(t, json.Unmarshal(r, &u))
+		assert.Equal(t, s, u)
+	}
+}
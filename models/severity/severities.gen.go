@@ -5,10 +5,12 @@ package severity
 
 // Defines values for Severity.
 const (
-	Empty  Severity = ""
-	High   Severity = "high"
-	Low    Severity = "low"
-	Medium Severity = "medium"
+	Critical Severity = "critical"
+	Empty    Severity = ""
+	High     Severity = "high"
+	Info     Severity = "info"
+	Low      Severity = "low"
+	Medium   Severity = "medium"
 )
 
 // Severity defines model for Severity.
@@ -0,0 +1,3 @@
+package status
+
+//go:generate oapi-codegen -config cfg.yml statuses.yml
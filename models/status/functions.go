@@ -0,0 +1,149 @@
+package status
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrTransition is the error of the transitions the lifecycle of the verdicts does not allow.
+var ErrTransition = errors.New("invalid status transition")
+
+// all are the statuses, in the order of the lifecycle.
+var all = []Status{Open, Acknowledged, FalsePositive, AcceptedRisk, Fixed}
+
+// transitions maps the statuses to the ones they can move to.
+//
+// The resolved statuses can only go back to open (eg., when a fixed verdict shows up again).
+var transitions = map[Status][]Status{
+	Open:          {Acknowledged, FalsePositive, AcceptedRisk, Fixed},
+	Acknowledged:  {Open, FalsePositive, AcceptedRisk, Fixed},
+	FalsePositive: {Open},
+	AcceptedRisk:  {Open},
+	Fixed:         {Open},
+}
+
+// Statuses returns the statuses, in the order of the lifecycle.
+func Statuses() []Status {
+	return append([]Status{}, all...)
+}
+
+func (s Status) String() string {
+	if slices.Contains(all, s) {
+		return string(s)
+	}
+
+	return string(Empty)
+}
+
+// Current returns the status, considering the empty one as open.
+//
+// The verdicts nobody triaged yet have the empty status.
+func (s Status) Current() Status {
+	if s == Empty {
+		return Open
+	}
+
+	return s
+}
+
+// Resolved tells whether the status closes the verdict.
+func (s Status) Resolved() bool {
+	switch s {
+	case FalsePositive, AcceptedRisk, Fixed:
+		return true
+	}
+
+	return false
+}
+
+// RequiresReason tells whether moving to the status requires a reason.
+func (s Status) RequiresReason() bool {
+	return s == FalsePositive || s == AcceptedRisk
+}
+
+// Next returns the statuses the status can move to.
+func (s Status) Next() []Status {
+	return append([]Status{}, transitions[s.Current()]...)
+}
+
+// CanTransition tells whether the status can move to the given one.
+func (s Status) CanTransition(to Status) bool {
+	return slices.Contains(transitions[s.Current()], to)
+}
+
+// ValidateTransition checks the lifecycle allows moving from a status to another one.
+//
+// It returns an error wrapping ErrTransition when it does not.
+func ValidateTransition(from, to Status) error {
+	if _, err := New(string(from)); err != nil {
+		return fmt.Errorf("%w: %w", ErrTransition, err)
+	}
+	if _, err := New(string(to)); err != nil || to == Empty {
+		return fmt.Errorf("%w: the input %q is not a status", ErrTransition, string(to))
+	}
+	if !from.CanTransition(to) {
+		return fmt.Errorf("%w from %s to %s", ErrTransition, from.Current(), to)
+	}
+
+	return nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *Status) Scan(source any) error {
+	if x, ok := source.(string); ok {
+		o, err := New(x)
+		if err != nil {
+			return err
+		}
+		*s = o
+
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T into Status", source)
+}
+
+// Value implements the driver.Valuer interface.
+func (s Status) Value() (driver.Value, error) {
+	if _, err := New(string(s)); err != nil {
+		return nil, err
+	}
+
+	return s.String(), nil
+}
+
+func New(input string) (Status, error) {
+	s := Status(strings.ToLower(input))
+	if s == Empty || slices.Contains(all, s) {
+		return s, nil
+	}
+
+	return Empty, fmt.Errorf("the input %q is not a status", input)
+}
+
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("couldn't unmarshal status data %q", string(data))
+	}
+
+	o, e := New(str)
+	if e != nil {
+		return e
+	}
+	*s = o
+
+	return nil
+}
+
+func (s Status) MarshalJSON() ([]byte, error) {
+	if _, err := New(string(s)); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(s.String())
+}
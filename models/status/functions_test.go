@@ -0,0 +1,106 @@
+package status
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	for _, s := range append(Statuses(), Empty) {
+		a, err := New(s.String())
+		assert.Nil(t, err)
+		assert.Equal(t, s, a)
+	}
+	fp, err := New("False-Positive")
+	assert.Nil(t, err)
+	assert.Equal(t, FalsePositive, fp)
+
+	_, err = New("closed")
+	assert.Error(t, err)
+}
+
+func TestTransitions(t *testing.T) {
+	cases := []struct {
+		from Status
+		to   []Status
+	}{
+		{Empty, []Status{Acknowledged, FalsePositive, AcceptedRisk, Fixed}},
+		{Open, []Status{Acknowledged, FalsePositive, AcceptedRisk, Fixed}},
+		{Acknowledged, []Status{Open, FalsePositive, AcceptedRisk, Fixed}},
+		{FalsePositive, []Status{Open}},
+		{AcceptedRisk, []Status{Open}},
+		{Fixed, []Status{Open}},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.to, tc.from.Next(), tc.from)
+		for _, to := range Statuses() {
+			allowed := tc.from.CanTransition(to)
+			assert.Equal(t, contains(tc.to, to), allowed, "%s -> %s", tc.from, to)
+
+			err := ValidateTransition(tc.from, to)
+			if allowed {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrTransition)
+			}
+		}
+	}
+
+	err := ValidateTransition(Fixed, Acknowledged)
+	assert.EqualError(t, err, "invalid status transition from fixed to acknowledged")
+	assert.ErrorIs(t, ValidateTransition(Open, Empty), ErrTransition)
+	assert.ErrorIs(t, ValidateTransition(Open, "closed"), ErrTransition)
+	assert.ErrorIs(t, ValidateTransition("closed", Open), ErrTransition)
+}
+
+func contains(statuses []Status, s Status) bool {
+	for _, x := range statuses {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestResolved(t *testing.T) {
+	assert.False(t, Empty.Resolved())
+	assert.False(t, Open.Resolved())
+	assert.False(t, Acknowledged.Resolved())
+	assert.True(t, FalsePositive.Resolved())
+	assert.True(t, AcceptedRisk.Resolved())
+	assert.True(t, Fixed.Resolved())
+
+	assert.True(t, FalsePositive.RequiresReason())
+	assert.True(t, AcceptedRisk.RequiresReason())
+	assert.False(t, Fixed.RequiresReason())
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(AcceptedRisk)
+	require.Nil(t, err)
+	assert.Equal(t, `"accepted-risk"`, string(data))
+
+	var s Status
+	require.Nil(t, json.Unmarshal([]byte(`"acknowledged"`), &s))
+	assert.Equal(t, Acknowledged, s)
+	assert.Error(t, json.Unmarshal([]byte(`"closed"`), &s))
+	assert.Error(t, json.Unmarshal([]byte(`1`), &s))
+
+	_, err = json.Marshal(Status("closed"))
+	assert.Error(t, err)
+}
+
+func TestScanValue(t *testing.T) {
+	var s Status
+	require.Nil(t, s.Scan("fixed"))
+	assert.Equal(t, Fixed, s)
+	assert.Error(t, s.Scan(1))
+
+	v, err := Fixed.Value()
+	require.Nil(t, err)
+	assert.Equal(t, "fixed", v)
+}
@@ -0,0 +1,17 @@
+// Package status provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version 2.4.1 DO NOT EDIT.
+package status
+
+// Defines values for Status.
+const (
+	AcceptedRisk  Status = "accepted-risk"
+	Acknowledged  Status = "acknowledged"
+	Empty         Status = ""
+	FalsePositive Status = "false-positive"
+	Fixed         Status = "fixed"
+	Open          Status = "open"
+)
+
+// Status defines model for Status.
+type Status string
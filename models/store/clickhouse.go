@@ -0,0 +1,164 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/listendev/pkg/models"
+)
+
+var errClickHouseUnsupportedType = errors.New("unsupported type")
+
+// ClickHouseColumn is a column of a ClickHouse table.
+type ClickHouseColumn struct {
+	Name string
+	Type string
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+var clickHouseScalars = map[reflect.Kind]string{
+	reflect.Bool:    "Bool",
+	reflect.Int8:    "Int8",
+	reflect.Int16:   "Int16",
+	reflect.Int32:   "Int32",
+	reflect.Int64:   "Int64",
+	reflect.Uint8:   "UInt8",
+	reflect.Uint16:  "UInt16",
+	reflect.Uint32:  "UInt32",
+	reflect.Uint64:  "UInt64",
+	reflect.Float32: "Float32",
+	reflect.Float64: "Float64",
+}
+
+// ClickHouseColumns returns the columns of the fields of the given struct having a `ch` tag, in the order of the fields.
+//
+// It maps the types as follows:
+//   - strings to String, and the named string types (enumerations) to LowCardinality(String)
+//   - integers to the integer types of the same size, but for the named integer types implementing fmt.Stringer
+//     (enumerations) that become LowCardinality(String) since they are stored with their JSON representation
+//   - times to DateTime64(3, 'UTC'), wrapped in Nullable when they are optional pointers
+//   - slices to Array of their elements
+//   - structs to named Tuple of their fields having a `ch` tag
+//   - maps to String since they are stored as JSON.
+//
+// The pointers are optional unless their field has the `mandatory` validation.
+func ClickHouseColumns(v any) ([]ClickHouseColumn, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w %s: expecting a struct", errClickHouseUnsupportedType, t)
+	}
+
+	return clickHouseFields(t)
+}
+
+func clickHouseFields(t reflect.Type) ([]ClickHouseColumn, error) {
+	ret := []ClickHouseColumn{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("ch"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		typ, err := clickHouseType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't map field %s: %w", f.Name, err)
+		}
+		if f.Type.Kind() == reflect.Pointer && !strings.Contains(f.Tag.Get("validate"), "mandatory") {
+			typ = "Nullable(" + typ + ")"
+		}
+		ret = append(ret, ClickHouseColumn{Name: name, Type: typ})
+	}
+
+	return ret, nil
+}
+
+func clickHouseType(t reflect.Type) (string, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "DateTime64(3, 'UTC')", nil
+	}
+	named := t.PkgPath() != ""
+
+	switch t.Kind() {
+	case reflect.String:
+		if named {
+			return "LowCardinality(String)", nil
+		}
+
+		return "String", nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if named && t.Implements(stringerType) {
+			return "LowCardinality(String)", nil
+		}
+
+		return clickHouseScalars[t.Kind()], nil
+	case reflect.Bool, reflect.Float32, reflect.Float64:
+		return clickHouseScalars[t.Kind()], nil
+	case reflect.Slice:
+		elem, err := clickHouseType(t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		return "Array(" + elem + ")", nil
+	case reflect.Map:
+		return "String", nil
+	case reflect.Struct:
+		fields, err := clickHouseFields(t)
+		if err != nil {
+			return "", err
+		}
+		elems := make([]string, len(fields))
+		for i, f := range fields {
+			elems[i] = f.Name + " " + f.Type
+		}
+
+		return "Tuple(" + strings.Join(elems, ", ") + ")", nil
+	}
+
+	return "", fmt.Errorf("%w %s", errClickHouseUnsupportedType, t)
+}
+
+// ClickHouseSchema returns the statement creating the ClickHouse table of the verdicts,
+// derived from the `ch` tags of models.Verdict.
+//
+// The table deduplicates the verdicts having the same ID (see ID), keeping the latest created one.
+func ClickHouseSchema(table string) (string, error) {
+	columns, err := ClickHouseColumns(models.Verdict{})
+	if err != nil {
+		return "", err
+	}
+
+	width := 0
+	for _, c := range columns {
+		width = max(width, len(c.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s\n(\n", table)
+	for i, c := range columns {
+		fmt.Fprintf(&b, "    %-*s %s", width, c.Name, c.Type)
+		if i < len(columns)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(")\n")
+	b.WriteString("ENGINE = ReplacingMergeTree(created_at)\n")
+	b.WriteString("ORDER BY (ecosystem, org, pkg, version, digest, file, code, fingerprint);\n")
+
+	return b.String(), nil
+}
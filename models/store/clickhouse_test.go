@@ -0,0 +1,60 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickHouseSchema(t *testing.T) {
+	want, err := os.ReadFile(path.Join("testdata", "verdicts.sql"))
+	require.Nil(t, err)
+
+	got, err := ClickHouseSchema("verdicts")
+	require.Nil(t, err)
+	assert.Equal(t, string(want), got)
+}
+
+func TestClickHouseColumns(t *testing.T) {
+	type change struct {
+		Actor string    `ch:"actor"`
+		At    time.Time `ch:"at"`
+		Note  string
+	}
+	type row struct {
+		ID       uint32         `ch:"id"`
+		Score    float64        `ch:"score"`
+		Enabled  bool           `ch:"enabled"`
+		Tags     []string       `ch:"tags"`
+		Seen     *time.Time     `ch:"seen"`
+		Created  *time.Time     `ch:"created" validate:"mandatory"`
+		Raw      map[string]int `ch:"raw,omitempty"`
+		Changes  []change       `ch:"changes"`
+		Ignored  string
+		Excluded string `ch:"-"`
+	}
+
+	got, err := ClickHouseColumns(&row{})
+	require.Nil(t, err)
+	assert.Equal(t, []ClickHouseColumn{
+		{"id", "UInt32"},
+		{"score", "Float64"},
+		{"enabled", "Bool"},
+		{"tags", "Array(String)"},
+		{"seen", "Nullable(DateTime64(3, 'UTC'))"},
+		{"created", "DateTime64(3, 'UTC')"},
+		{"raw", "String"},
+		{"changes", "Array(Tuple(actor String, at DateTime64(3, 'UTC')))"},
+	}, got)
+
+	_, err = ClickHouseColumns(struct {
+		C chan int `ch:"c"`
+	}{})
+	assert.ErrorIs(t, err, errClickHouseUnsupportedType)
+	_, err = ClickHouseColumns(1)
+	assert.ErrorIs(t, err, errClickHouseUnsupportedType)
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/listendev/pkg/models"
+)
+
+var _ VerdictStore = (*MemoryStore)(nil)
+
+// MemoryStore is a VerdictStore keeping the verdicts in memory.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	verdicts map[string]models.Verdict
+	now      func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+//
+// The given function returns the current time, it defaults to time.Now when nil.
+func NewMemoryStore(now func() time.Time) *MemoryStore {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryStore{
+		verdicts: map[string]models.Verdict{},
+		now:      now,
+	}
+}
+
+func (s *MemoryStore) Upsert(_ context.Context, verdicts ...models.Verdict) error {
+	ids := make([]string, len(verdicts))
+	for i, v := range verdicts {
+		id, err := ID(v)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, v := range verdicts {
+		s.verdicts[ids[i]] = clone(v)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Find(_ context.Context, q Query) (models.Verdicts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	ids := slices.Sorted(maps.Keys(s.verdicts))
+	ret := models.Verdicts{}
+	for _, id := range ids {
+		if v := s.verdicts[id]; q.Match(v, now) {
+			ret = append(ret, clone(v))
+		}
+	}
+
+	return ret, nil
+}
+
+func (s *MemoryStore) Expire(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ret int64
+	for id, v := range s.verdicts {
+		if expired(v, now) {
+			delete(s.verdicts, id)
+			ret++
+		}
+	}
+
+	return ret, nil
+}
+
+// Len returns the number of stored verdicts, including the expired ones not removed yet.
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.verdicts)
+}
+
+// clone copies the given verdict so that the store and its users do not share its slices and maps.
+func clone(v models.Verdict) models.Verdict {
+	v.Categories = slices.Clone(v.Categories)
+	v.Metadata = maps.Clone(v.Metadata)
+	v.History = slices.Clone(v.History)
+	if v.CreatedAt != nil {
+		t := *v.CreatedAt
+		v.CreatedAt = &t
+	}
+	if v.ExpiresAt != nil {
+		t := *v.ExpiresAt
+		v.ExpiresAt = &t
+	}
+
+	return v
+}
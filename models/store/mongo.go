@@ -0,0 +1,299 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
+	"github.com/listendev/pkg/verdictcode"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var _ VerdictStore = (*MongoStore)(nil)
+
+// mongoVerdict is the MongoDB document of a verdict.
+//
+// Its field names are the ones of the ClickHouse columns,
+// and its enumerations are stored with their JSON representation.
+type mongoVerdict struct {
+	ID          string              `bson:"_id"`
+	Ecosystem   string              `bson:"ecosystem"`
+	Org         string              `bson:"org,omitempty"`
+	Pkg         string              `bson:"pkg"`
+	Version     string              `bson:"version"`
+	Digest      string              `bson:"digest"`
+	File        string              `bson:"file"`
+	Code        string              `bson:"code"`
+	Fingerprint string              `bson:"fingerprint,omitempty"`
+	Message     string              `bson:"message,omitempty"`
+	Severity    string              `bson:"severity"`
+	Categories  []string            `bson:"categories"`
+	Metadata    bson.Raw            `bson:"metadata,omitempty"`
+	CreatedAt   *time.Time          `bson:"created_at"`
+	ExpiresAt   *time.Time          `bson:"expires_at"`
+	Status      string              `bson:"status,omitempty"`
+	History     []mongoStatusChange `bson:"history,omitempty"`
+}
+
+// mongoStatusChange is the MongoDB document of a change of the status of a verdict.
+type mongoStatusChange struct {
+	From   string    `bson:"from"`
+	To     string    `bson:"to"`
+	Actor  string    `bson:"actor"`
+	Reason string    `bson:"reason,omitempty"`
+	At     time.Time `bson:"at"`
+}
+
+func toMongo(v models.Verdict) (*mongoVerdict, error) {
+	id, err := ID(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := &mongoVerdict{
+		ID:          id,
+		Ecosystem:   v.Ecosystem.Case(),
+		Org:         v.Org,
+		Pkg:         v.Pkg,
+		Version:     v.Version,
+		Digest:      v.Digest,
+		File:        v.File,
+		Code:        v.Code.String(),
+		Fingerprint: v.Fingerprint,
+		Message:     v.Message,
+		Severity:    v.Severity.String(),
+		Categories:  make([]string, len(v.Categories)),
+		CreatedAt:   v.CreatedAt,
+		ExpiresAt:   v.ExpiresAt,
+		Status:      v.Status.String(),
+	}
+	for i, c := range v.Categories {
+		doc.Categories[i] = string(c.Case())
+	}
+	for _, c := range v.History {
+		doc.History = append(doc.History, mongoStatusChange{
+			From:   c.From.String(),
+			To:     c.To.String(),
+			Actor:  c.Actor,
+			Reason: c.Reason,
+			At:     c.At,
+		})
+	}
+	if len(v.Metadata) > 0 {
+		doc.Metadata, err = bson.Marshal(v.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't marshal the metadata of verdict %q: %w", id, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (doc *mongoVerdict) verdict() (models.Verdict, error) {
+	v := models.Verdict{
+		Org:         doc.Org,
+		Pkg:         doc.Pkg,
+		Version:     doc.Version,
+		Digest:      doc.Digest,
+		File:        doc.File,
+		Fingerprint: doc.Fingerprint,
+		Message:     doc.Message,
+		Categories:  make([]category.Category, len(doc.Categories)),
+		Metadata:    map[string]interface{}{},
+		CreatedAt:   doc.CreatedAt,
+		ExpiresAt:   doc.ExpiresAt,
+	}
+
+	var err error
+	if v.Ecosystem, err = ecosystem.FromString(doc.Ecosystem); err != nil {
+		return v, err
+	}
+	if v.Code, err = verdictcode.FromString(doc.Code, true); err != nil && doc.Code != verdictcode.UNK.String() {
+		return v, err
+	}
+	if v.Severity, err = severity.New(doc.Severity); err != nil {
+		return v, err
+	}
+	for i, c := range doc.Categories {
+		if v.Categories[i], err = category.FromString(c); err != nil {
+			return v, err
+		}
+	}
+	if v.Status, err = status.New(doc.Status); err != nil {
+		return v, err
+	}
+	for _, c := range doc.History {
+		change := models.StatusChange{
+			Actor:  c.Actor,
+			Reason: c.Reason,
+			At:     c.At,
+		}
+		if change.From, err = status.New(c.From); err != nil {
+			return v, err
+		}
+		if change.To, err = status.New(c.To); err != nil {
+			return v, err
+		}
+		v.History = append(v.History, change)
+	}
+	if len(doc.Metadata) > 0 {
+		// Go through JSON so that the metadata have the same types they have in the JSON verdicts
+		data, err := bson.MarshalExtJSON(doc.Metadata, false, false)
+		if err != nil {
+			return v, err
+		}
+		if err := json.Unmarshal(data, &v.Metadata); err != nil {
+			return v, err
+		}
+	}
+
+	return v, nil
+}
+
+// MongoStore is a VerdictStore keeping the verdicts in a MongoDB collection.
+//
+// MongoDB removes the expired verdicts on its own thanks to a TTL index,
+// but it does it periodically, so the store filters them out on reads too.
+type MongoStore struct {
+	coll *mongo.Collection
+	now  func() time.Time
+}
+
+// NewMongoStore creates a store keeping the verdicts in the given collection, creating its indexes.
+func NewMongoStore(ctx context.Context, coll *mongo.Collection) (*MongoStore, error) {
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "ecosystem", Value: 1}, {Key: "pkg", Value: 1}, {Key: "version", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "code", Value: 1}, {Key: "severity", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create the indexes of collection %q: %w", coll.Name(), err)
+	}
+
+	return &MongoStore{
+		coll: coll,
+		now:  time.Now,
+	}, nil
+}
+
+func (s *MongoStore) Upsert(ctx context.Context, verdicts ...models.Verdict) error {
+	if len(verdicts) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(verdicts))
+	for i, v := range verdicts {
+		doc, err := toMongo(v)
+		if err != nil {
+			return err
+		}
+		writes[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: doc.ID}}).
+			SetReplacement(doc).
+			SetUpsert(true)
+	}
+	if _, err := s.coll.BulkWrite(ctx, writes); err != nil {
+		return fmt.Errorf("couldn't upsert the verdicts: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MongoStore) Find(ctx context.Context, q Query) (models.Verdicts, error) {
+	cursor, err := s.coll.Find(ctx, mongoFilter(q, s.now()), options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find the verdicts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	ret := models.Verdicts{}
+	for cursor.Next(ctx) {
+		var doc mongoVerdict
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		v, err := doc.verdict()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode verdict %q: %w", doc.ID, err)
+		}
+		ret = append(ret, v)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (s *MongoStore) Expire(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.coll.DeleteMany(ctx, bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: now}}}})
+	if err != nil {
+		return 0, fmt.Errorf("couldn't remove the expired verdicts: %w", err)
+	}
+
+	return res.DeletedCount, nil
+}
+
+// mongoFilter translates the given query to a MongoDB filter.
+func mongoFilter(q Query, now time.Time) bson.D {
+	ret := bson.D{}
+	if q.Ecosystem != ecosystem.None {
+		ret = append(ret, bson.E{Key: "ecosystem", Value: q.Ecosystem.Case()})
+	}
+	for _, f := range []struct{ key, value string }{
+		{"org", q.Org},
+		{"pkg", q.Pkg},
+		{"version", q.Version},
+		{"digest", q.Digest},
+	} {
+		if f.value != "" {
+			ret = append(ret, bson.E{Key: f.key, Value: f.value})
+		}
+	}
+	if len(q.Codes) > 0 {
+		codes := make(bson.A, len(q.Codes))
+		for i, c := range q.Codes {
+			codes[i] = c.String()
+		}
+		ret = append(ret, bson.E{Key: "code", Value: bson.D{{Key: "$in", Value: codes}}})
+	}
+	if len(q.Severities) > 0 {
+		severities := make(bson.A, len(q.Severities))
+		for i, s := range q.Severities {
+			severities[i] = s.String()
+		}
+		ret = append(ret, bson.E{Key: "severity", Value: bson.D{{Key: "$in", Value: severities}}})
+	}
+	if len(q.Statuses) > 0 {
+		statuses := make(bson.A, 0, len(q.Statuses)+1)
+		for _, s := range q.Statuses {
+			statuses = append(statuses, s.Current().String())
+			// The documents of the verdicts nobody triaged yet lack the status
+			if s.Current() == status.Open {
+				statuses = append(statuses, nil)
+			}
+		}
+		ret = append(ret, bson.E{Key: "status", Value: bson.D{{Key: "$in", Value: statuses}}})
+	}
+	if !q.IncludeExpired {
+		ret = append(ret, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "expires_at", Value: nil}},
+			bson.D{{Key: "expires_at", Value: bson.D{{Key: "$gte", Value: now}}}},
+		}})
+	}
+
+	return ret
+}
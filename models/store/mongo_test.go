@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TestMongoStore runs against the local mongod the MONGODB_URI environment variable points to (eg., mongodb://localhost:27017).
+func TestMongoStore(t *testing.T) {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("MONGODB_URI not set")
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	require.Nil(t, err)
+	t.Cleanup(func() {
+		_ = client.Disconnect(context.Background())
+	})
+
+	testVerdictStore(t, func(t *testing.T, now func() time.Time) VerdictStore {
+		t.Helper()
+
+		db := client.Database("listendev_pkg_test_" + strconv.FormatInt(time.Now().UnixNano(), 10))
+		t.Cleanup(func() {
+			_ = db.Drop(context.Background())
+		})
+		s, err := NewMongoStore(context.Background(), db.Collection("verdicts"))
+		require.Nil(t, err)
+		s.now = now
+
+		return s
+	})
+}
+
+func TestMongoDocument(t *testing.T) {
+	v := newVerdict(t, "chalk", "dynamic!install!.json", verdictcode.FNI001, "first", severity.High)
+	expiresAt := testNow.Add(time.Hour)
+	v.ExpiresAt = &expiresAt
+	require.Nil(t, v.Apply(models.Transition{To: status.AcceptedRisk, Actor: "alice", Reason: "sandboxed", At: testNow}))
+
+	doc, err := toMongo(v)
+	require.Nil(t, err)
+	assert.Equal(t, "npm/chalk/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/dynamic!install!.json#FNI001#first", doc.ID)
+	assert.Equal(t, "npm", doc.Ecosystem)
+	assert.Equal(t, "FNI001", doc.Code)
+	assert.Equal(t, "high", doc.Severity)
+	assert.Equal(t, []string{"network"}, doc.Categories)
+	assert.Equal(t, "accepted-risk", doc.Status)
+	assert.Equal(t, []mongoStatusChange{{From: "open", To: "accepted-risk", Actor: "alice", Reason: "sandboxed", At: testNow}}, doc.History)
+
+	// Through BSON
+	data, err := bson.Marshal(doc)
+	require.Nil(t, err)
+	var decoded mongoVerdict
+	require.Nil(t, bson.Unmarshal(data, &decoded))
+
+	got, err := decoded.verdict()
+	require.Nil(t, err)
+	assert.Equal(t, v, got)
+}
+
+func TestMongoFilter(t *testing.T) {
+	got := mongoFilter(Query{Pkg: "chalk", Codes: []verdictcode.Code{verdictcode.TSN01}, IncludeExpired: true}, testNow)
+	assert.Equal(t, bson.D{
+		{Key: "pkg", Value: "chalk"},
+		{Key: "code", Value: bson.D{{Key: "$in", Value: bson.A{"TSN01"}}}},
+	}, got)
+
+	got = mongoFilter(Query{Statuses: []status.Status{status.Open, status.Fixed}, IncludeExpired: true}, testNow)
+	assert.Equal(t, bson.D{
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"open", nil, "fixed"}}}},
+	}, got)
+
+	got = mongoFilter(Query{}, testNow)
+	assert.Equal(t, bson.D{
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "expires_at", Value: nil}},
+			bson.D{{Key: "expires_at", Value: bson.D{{Key: "$gte", Value: testNow}}}},
+		}},
+	}, got)
+}
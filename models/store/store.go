@@ -0,0 +1,92 @@
+// Package store provides the persistence layer of the verdicts.
+package store
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// VerdictStore persists verdicts.
+type VerdictStore interface {
+	// Upsert inserts the given verdicts, replacing the stored ones having the same ID (see ID)
+	Upsert(ctx context.Context, verdicts ...models.Verdict) error
+	// Find returns the stored verdicts matching the given query, sorted by ID
+	Find(ctx context.Context, q Query) (models.Verdicts, error)
+	// Expire removes the verdicts that expired before the given time, returning how many it removed
+	Expire(ctx context.Context, now time.Time) (int64, error)
+}
+
+// ID returns the identifier of the given verdict within a store.
+//
+// It is made of the verdict Key(), code, and fingerprint.
+// The code is part of it because the verdicts whose code uniquely identifies them can lack the fingerprint.
+func ID(v models.Verdict) (string, error) {
+	key, err := v.Key()
+	if err != nil {
+		return "", err
+	}
+
+	return key + "#" + v.Code.String() + "#" + v.Fingerprint, nil
+}
+
+// Query selects verdicts.
+//
+// Its zero value matches all the verdicts that did not expire.
+type Query struct {
+	// Ecosystem matches the verdicts of the given ecosystem (ecosystem.None matches any)
+	Ecosystem ecosystem.Ecosystem
+	// Org matches the verdicts of the given organization (empty matches any)
+	Org string
+	// Pkg matches the verdicts of the given package (empty matches any)
+	Pkg string
+	// Version matches the verdicts of the given version (empty matches any)
+	Version string
+	// Digest matches the verdicts of the given digest (empty matches any)
+	Digest string
+	// Codes matches the verdicts having one of the given codes (empty matches any)
+	Codes []verdictcode.Code
+	// Severities matches the verdicts having one of the given severities (empty matches any)
+	Severities []severity.Severity
+	// Statuses matches the verdicts having one of the given statuses, the empty one being open (empty matches any)
+	Statuses []status.Status
+	// IncludeExpired makes the query match also the expired verdicts not removed yet
+	IncludeExpired bool
+}
+
+// Match tells whether the given verdict matches the query at the given time.
+func (q Query) Match(v models.Verdict, now time.Time) bool {
+	switch {
+	case q.Ecosystem != ecosystem.None && v.Ecosystem != q.Ecosystem:
+		return false
+	case q.Org != "" && v.Org != q.Org:
+		return false
+	case q.Pkg != "" && v.Pkg != q.Pkg:
+		return false
+	case q.Version != "" && v.Version != q.Version:
+		return false
+	case q.Digest != "" && v.Digest != q.Digest:
+		return false
+	case len(q.Codes) > 0 && !slices.Contains(q.Codes, v.Code):
+		return false
+	case len(q.Severities) > 0 && !slices.Contains(q.Severities, v.Severity):
+		return false
+	case len(q.Statuses) > 0 && !slices.ContainsFunc(q.Statuses, func(s status.Status) bool { return s.Current() == v.Status.Current() }):
+		return false
+	case !q.IncludeExpired && expired(v, now):
+		return false
+	}
+
+	return true
+}
+
+// expired tells whether the given verdict expired before the given time.
+func expired(v models.Verdict, now time.Time) bool {
+	return v.ExpiresAt != nil && v.ExpiresAt.Before(now)
+}
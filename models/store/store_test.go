@@ -0,0 +1,178 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newVerdict(t *testing.T, pkg, file string, code verdictcode.Code, fingerprint string, sev severity.Severity) models.Verdict {
+	t.Helper()
+
+	created := testNow.Add(-time.Hour)
+	v := models.Verdict{
+		Ecosystem:   ecosystem.Npm,
+		Pkg:         pkg,
+		Version:     "5.1.2",
+		Digest:      "d957f370038b75ac572471e83be4c5ca9f8e8c45",
+		File:        file,
+		CreatedAt:   &created,
+		Code:        code,
+		Fingerprint: fingerprint,
+		Message:     pkg + " " + code.String(),
+		Severity:    sev,
+		Categories:  []category.Category{category.Network},
+		Metadata: map[string]interface{}{
+			"npm_package_name": pkg,
+			"nested": map[string]interface{}{
+				"line": float64(10),
+				"list": []interface{}{"a", "b"},
+			},
+		},
+	}
+	require.Nil(t, v.Validate())
+
+	return v
+}
+
+func ids(t *testing.T, verdicts models.Verdicts) []string {
+	t.Helper()
+
+	ret := []string{}
+	for _, v := range verdicts {
+		id, err := ID(v)
+		require.Nil(t, err)
+		ret = append(ret, id)
+	}
+
+	return ret
+}
+
+// testVerdictStore runs the behaviors every VerdictStore must have against the store the given function creates.
+func testVerdictStore(t *testing.T, newStore func(t *testing.T, now func() time.Time) VerdictStore) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := testNow
+	s := newStore(t, func() time.Time { return now })
+
+	typosquat := newVerdict(t, "chalk", "typosquat.json", verdictcode.TSN01, "", severity.Medium)
+	install1 := newVerdict(t, "chalk", "dynamic!install!.json", verdictcode.FNI001, "first", severity.High)
+	install2 := newVerdict(t, "chalk", "dynamic!install!.json", verdictcode.FNI001, "second", severity.Low)
+	react := newVerdict(t, "react", "typosquat.json", verdictcode.TSN01, "", severity.High)
+	expiring := newVerdict(t, "lodash", "dynamic!install!.json", verdictcode.FNI002, "x", severity.High)
+	expiresAt := now.Add(time.Minute)
+	expiring.ExpiresAt = &expiresAt
+	require.Nil(t, install2.Apply(models.Transition{To: status.FalsePositive, Actor: "alice", Reason: "node-gyp", At: now}))
+	require.Nil(t, react.Apply(models.Transition{To: status.Acknowledged, Actor: "bob", At: now}))
+
+	require.Nil(t, s.Upsert(ctx, typosquat, install1, install2, react, expiring))
+
+	all, err := s.Find(ctx, Query{})
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		"npm/chalk/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/dynamic!install!.json#FNI001#first",
+		"npm/chalk/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/dynamic!install!.json#FNI001#second",
+		"npm/chalk/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/typosquat.json#TSN01#",
+		"npm/lodash/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/dynamic!install!.json#FNI002#x",
+		"npm/react/5.1.2/d957f370038b75ac572471e83be4c5ca9f8e8c45/typosquat.json#TSN01#",
+	}, ids(t, all))
+	// The verdicts survive the round trip
+	assert.Equal(t, install1, all[0])
+	assert.Equal(t, install2, all[1])
+	assert.Equal(t, expiring, all[3])
+
+	t.Run("query", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			query Query
+			want  []models.Verdict
+		}{
+			{"by package", Query{Ecosystem: ecosystem.Npm, Pkg: "chalk", Version: "5.1.2"}, []models.Verdict{install1, install2, typosquat}},
+			{"by code", Query{Codes: []verdictcode.Code{verdictcode.TSN01}}, []models.Verdict{typosquat, react}},
+			{"by severity", Query{Severities: []severity.Severity{severity.High}}, []models.Verdict{install1, expiring, react}},
+			{"by package and severity", Query{Pkg: "chalk", Severities: []severity.Severity{severity.Medium, severity.Low}}, []models.Verdict{install2, typosquat}},
+			{"by digest", Query{Digest: "0000000000000000000000000000000000000000"}, []models.Verdict{}},
+			{"by ecosystem", Query{Ecosystem: ecosystem.Pypi}, []models.Verdict{}},
+			{"by status", Query{Statuses: []status.Status{status.Open}}, []models.Verdict{install1, typosquat, expiring}},
+			{"by statuses", Query{Statuses: []status.Status{status.FalsePositive, status.Acknowledged}}, []models.Verdict{install2, react}},
+		}
+		for _, tc := range cases {
+			got, err := s.Find(ctx, tc.query)
+			require.Nil(t, err)
+			assert.Equal(t, ids(t, tc.want), ids(t, got), tc.name)
+		}
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		updated := install1
+		updated.Message = "updated"
+		require.Nil(t, s.Upsert(ctx, updated))
+
+		got, err := s.Find(ctx, Query{Pkg: "chalk", Codes: []verdictcode.Code{verdictcode.FNI001}})
+		require.Nil(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "updated", got[0].Message)
+		assert.Equal(t, install2.Message, got[1].Message)
+	})
+
+	t.Run("invalid verdicts", func(t *testing.T) {
+		invalid := typosquat
+		invalid.Version = "latest"
+		assert.Error(t, s.Upsert(ctx, invalid))
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		now = expiresAt.Add(time.Second)
+
+		got, err := s.Find(ctx, Query{Pkg: "lodash"})
+		require.Nil(t, err)
+		assert.Empty(t, got)
+		got, err = s.Find(ctx, Query{Pkg: "lodash", IncludeExpired: true})
+		require.Nil(t, err)
+		assert.Len(t, got, 1)
+
+		n, err := s.Expire(ctx, now)
+		require.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+		got, err = s.Find(ctx, Query{IncludeExpired: true})
+		require.Nil(t, err)
+		assert.Len(t, got, 4)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testVerdictStore(t, func(_ *testing.T, now func() time.Time) VerdictStore {
+		return NewMemoryStore(now)
+	})
+}
+
+func TestMemoryStoreDoesNotShareVerdicts(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(nil)
+	v := newVerdict(t, "chalk", "typosquat.json", verdictcode.TSN01, "", severity.Medium)
+	require.Nil(t, s.Upsert(ctx, v))
+
+	v.Metadata["npm_package_name"] = "changed"
+	got, err := s.Find(ctx, Query{})
+	require.Nil(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "chalk", got[0].Metadata["npm_package_name"])
+
+	got[0].Categories[0] = category.Process
+	again, err := s.Find(ctx, Query{})
+	require.Nil(t, err)
+	assert.Equal(t, category.Network, again[0].Categories[0])
+	assert.Equal(t, 1, s.Len())
+}
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS verdicts
+(
+    categories  Array(LowCardinality(String)),
+    code        LowCardinality(String),
+    created_at  DateTime64(3, 'UTC'),
+    digest      String,
+    ecosystem   LowCardinality(String),
+    expires_at  Nullable(DateTime64(3, 'UTC')),
+    file        String,
+    fingerprint String,
+    history     Array(Tuple(actor String, at DateTime64(3, 'UTC'), from LowCardinality(String), reason String, to LowCardinality(String))),
+    message     String,
+    metadata    String,
+    org         String,
+    pkg         String,
+    severity    LowCardinality(String),
+    status      LowCardinality(String),
+    version     String
+)
+ENGINE = ReplacingMergeTree(created_at)
+ORDER BY (ecosystem, org, pkg, version, digest, file, code, fingerprint);
@@ -0,0 +1,391 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Format is the format of a stream of verdicts.
+type Format string
+
+const (
+	// FormatAuto detects the format from the first character of the stream
+	FormatAuto Format = ""
+	// FormatJSON is a JSON array of verdicts
+	FormatJSON Format = "json"
+	// FormatNDJSON is a verdict per line
+	FormatNDJSON Format = "ndjson"
+)
+
+// DefaultMaxRecordSize is the default maximum size in bytes of a verdict in a stream.
+const DefaultMaxRecordSize = 16 << 20
+
+var (
+	ErrRecordTooLarge = errors.New("record too large")
+	ErrUnknownFormat  = errors.New("unknown stream format")
+)
+
+// RecordError is the error of a verdict of a stream that does not abort the stream.
+type RecordError struct {
+	// Index is the position of the verdict in the stream, starting from 0
+	Index int
+	// Offset is the position in bytes of the verdict in the stream
+	Offset int64
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("verdict #%d (offset %d): %s", e.Index, e.Offset, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+type DecoderOption func(*Decoder)
+
+// WithFormat is an option to set the format of the stream, instead of detecting it.
+func WithFormat(f Format) DecoderOption {
+	return func(d *Decoder) {
+		d.format = f
+	}
+}
+
+// WithMaxRecordSize is an option to set the maximum size in bytes of a verdict.
+//
+// The NDJSON verdicts exceeding it are skipped without reading them into memory.
+func WithMaxRecordSize(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.maxRecordSize = n
+	}
+}
+
+// Decoder reads the verdicts from a stream one by one, so that its memory usage does not depend on the size of the stream.
+//
+// It validates every verdict it reads.
+type Decoder struct {
+	r             *bufio.Reader
+	format        Format
+	maxRecordSize int
+
+	json    *json.Decoder
+	started bool
+	index   int
+	offset  int64
+	err     error
+}
+
+// NewDecoder returns a decoder reading the verdicts from the given stream.
+func NewDecoder(r io.Reader, options ...DecoderOption) *Decoder {
+	d := &Decoder{
+		r:             bufio.NewReader(r),
+		maxRecordSize: DefaultMaxRecordSize,
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d
+}
+
+// Format returns the format of the stream, detecting it when needed.
+func (d *Decoder) Format() (Format, error) {
+	if err := d.start(); err != nil {
+		return FormatAuto, err
+	}
+
+	return d.format, nil
+}
+
+// Next returns the next verdict of the stream.
+//
+// It returns io.EOF at the end of the stream.
+// When a verdict is not valid it returns a *RecordError, and the stream can go on.
+// Any other error aborts the stream, and every following call returns it.
+func (d *Decoder) Next() (Verdict, error) {
+	if d.err != nil {
+		return Verdict{}, d.err
+	}
+	if err := d.start(); err != nil {
+		d.err = err
+
+		return Verdict{}, err
+	}
+
+	var (
+		data   []byte
+		offset int64
+		err    error
+	)
+	switch d.format {
+	case FormatJSON:
+		data, offset, err = d.nextElement()
+	default:
+		data, offset, err = d.nextLine()
+	}
+	if err != nil {
+		var recordErr *RecordError
+		if !errors.As(err, &recordErr) {
+			d.err = err
+		}
+
+		return Verdict{}, err
+	}
+
+	index := d.index
+	d.index++
+	var v Verdict
+	if err := json.Unmarshal(data, &v); err != nil {
+		return Verdict{}, &RecordError{Index: index, Offset: offset, Err: err}
+	}
+
+	return v, nil
+}
+
+// All returns an iterator over the verdicts of the stream, along with the errors of the ones not valid (see Next).
+//
+// It stops at the end of the stream, or after yielding an error aborting it.
+func (d *Decoder) All() iter.Seq2[Verdict, error] {
+	return func(yield func(Verdict, error) bool) {
+		for {
+			v, err := d.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(v, err) {
+				return
+			}
+			var recordErr *RecordError
+			if err != nil && !errors.As(err, &recordErr) {
+				return
+			}
+		}
+	}
+}
+
+// start detects the format of the stream, and enters the JSON array.
+func (d *Decoder) start() error {
+	if d.started {
+		return nil
+	}
+	d.started = true
+
+	if d.format == FormatAuto {
+		c, err := d.peek()
+		switch {
+		case errors.Is(err, io.EOF):
+			d.format = FormatNDJSON
+		case err != nil:
+			return err
+		case c == '[':
+			d.format = FormatJSON
+		default:
+			d.format = FormatNDJSON
+		}
+	}
+
+	switch d.format {
+	case FormatJSON:
+		d.json = json.NewDecoder(d.r)
+		tok, err := d.json.Token()
+		if err != nil {
+			return fmt.Errorf("couldn't read the verdicts array: %w", err)
+		}
+		if tok != json.Delim('[') {
+			return fmt.Errorf("couldn't read the verdicts array: unexpected %v", tok)
+		}
+	case FormatNDJSON:
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownFormat, d.format)
+	}
+
+	return nil
+}
+
+// peek returns the first non-whitespace byte of the stream, without consuming it.
+func (d *Decoder) peek() (byte, error) {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c, d.r.UnreadByte()
+		}
+		d.offset++
+	}
+}
+
+func (d *Decoder) nextElement() ([]byte, int64, error) {
+	if !d.json.More() {
+		tok, err := d.json.Token()
+		if err != nil {
+			return nil, 0, fmt.Errorf("couldn't read the verdicts array: %w", err)
+		}
+		if tok != json.Delim(']') {
+			return nil, 0, fmt.Errorf("couldn't read the verdicts array: unexpected %v", tok)
+		}
+
+		return nil, 0, io.EOF
+	}
+	var raw json.RawMessage
+	if err := d.json.Decode(&raw); err != nil {
+		return nil, 0, fmt.Errorf("couldn't read verdict #%d: %w", d.index, err)
+	}
+	offset := d.offset + d.json.InputOffset() - int64(len(raw))
+	if d.maxRecordSize > 0 && len(raw) > d.maxRecordSize {
+		index := d.index
+		d.index++
+
+		return nil, 0, &RecordError{Index: index, Offset: offset, Err: ErrRecordTooLarge}
+	}
+
+	return raw, offset, nil
+}
+
+// nextLine returns the next non-blank line, skipping the ones longer than the maximum record size.
+func (d *Decoder) nextLine() ([]byte, int64, error) {
+	for {
+		offset := d.offset
+		line, tooLarge, err := d.readLine()
+		if err != nil {
+			return nil, 0, err
+		}
+		if tooLarge {
+			index := d.index
+			d.index++
+
+			return nil, 0, &RecordError{Index: index, Offset: offset, Err: ErrRecordTooLarge}
+		}
+		indent := len(line) - len(bytes.TrimLeft(line, " \t\r\n"))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, offset + int64(indent), nil
+		}
+	}
+}
+
+// readLine reads a line, discarding it when it is longer than the maximum record size.
+//
+// It returns io.EOF only when there are no more bytes.
+func (d *Decoder) readLine() ([]byte, bool, error) {
+	var line []byte
+	tooLarge := false
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+		d.offset += int64(len(chunk))
+		if !tooLarge {
+			if d.maxRecordSize > 0 && len(line)+len(chunk) > d.maxRecordSize+2 { // Leaving room for \r\n
+				tooLarge = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		switch {
+		case err == nil:
+			return line, tooLarge, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF):
+			if len(line) == 0 && !tooLarge {
+				return nil, false, io.EOF
+			}
+
+			return line, tooLarge, nil
+		default:
+			return nil, false, err
+		}
+	}
+}
+
+// Encoder writes the verdicts to a stream one by one.
+//
+// It validates every verdict it writes.
+type Encoder struct {
+	w      *bufio.Writer
+	format Format
+	index  int
+	count  int
+	closed bool
+}
+
+// NewEncoder returns an encoder writing the verdicts to the given stream in the given format (FormatJSON when auto).
+func NewEncoder(w io.Writer, format Format) (*Encoder, error) {
+	switch format {
+	case FormatAuto:
+		format = FormatJSON
+	case FormatJSON, FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+
+	return &Encoder{w: bufio.NewWriter(w), format: format}, nil
+}
+
+// Encode writes the verdict to the stream.
+//
+// When the verdict is not valid it writes nothing and returns a *RecordError, and the stream can go on.
+func (e *Encoder) Encode(v Verdict) error {
+	if e.closed {
+		return errors.New("the encoder is closed")
+	}
+	index := e.index
+	e.index++
+	data, err := json.Marshal(v)
+	if err != nil {
+		return &RecordError{Index: index, Err: err}
+	}
+
+	if e.format == FormatJSON {
+		sep := byte(',')
+		if e.count == 0 {
+			sep = '['
+		}
+		if err := e.w.WriteByte(sep); err != nil {
+			return err
+		}
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	if e.format == FormatNDJSON {
+		if err := e.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	return nil
+}
+
+// Count returns how many verdicts the encoder wrote.
+func (e *Encoder) Count() int {
+	return e.count
+}
+
+// Close terminates the stream, and flushes it.
+//
+// It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.format == FormatJSON {
+		if e.count == 0 {
+			if err := e.w.WriteByte('['); err != nil {
+				return err
+			}
+		}
+		if _, err := e.w.WriteString("]\n"); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Flush()
+}
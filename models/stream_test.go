@@ -0,0 +1,217 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamVerdict(t *testing.T, i int) Verdict {
+	t.Helper()
+	v, err := NewEmptyVerdict(ecosystem.Npm, "", fmt.Sprintf("pkg%d", i), "1.0.0", "0123456789012345678901234567890123456789", "typosquat.json")
+	require.Nil(t, err)
+
+	return *v
+}
+
+func encodeStream(t *testing.T, format Format, verdicts ...Verdict) []byte {
+	t.Helper()
+	b := new(bytes.Buffer)
+	enc, err := NewEncoder(b, format)
+	require.Nil(t, err)
+	for _, v := range verdicts {
+		require.Nil(t, enc.Encode(v))
+	}
+	require.Nil(t, enc.Close())
+
+	return b.Bytes()
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	verdicts := []Verdict{}
+	for i := range 1000 {
+		verdicts = append(verdicts, streamVerdict(t, i))
+	}
+
+	for _, format := range []Format{FormatJSON, FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			data := encodeStream(t, format, verdicts...)
+			if format == FormatJSON {
+				got, err := FromBuffer(bytes.NewReader(data))
+				require.Nil(t, err)
+				assert.Len(t, got, len(verdicts))
+			} else {
+				assert.Equal(t, len(verdicts), bytes.Count(data, []byte("\n")))
+			}
+
+			// Small reads to exercise the buffering
+			dec := NewDecoder(iotest.HalfReader(bytes.NewReader(data)), WithMaxRecordSize(512))
+			i := 0
+			for v, err := range dec.All() {
+				require.Nil(t, err)
+				assert.Equal(t, verdicts[i].Pkg, v.Pkg)
+				i++
+			}
+			assert.Equal(t, len(verdicts), i)
+			detected, err := dec.Format()
+			require.Nil(t, err)
+			assert.Equal(t, format, detected)
+		})
+	}
+}
+
+func TestStreamEmpty(t *testing.T) {
+	assert.Equal(t, "[]\n", string(encodeStream(t, FormatJSON)))
+	assert.Equal(t, "", string(encodeStream(t, FormatNDJSON)))
+
+	for _, input := range []string{"", "  \n", "[]", " [ ] \n", "\n\n"} {
+		dec := NewDecoder(strings.NewReader(input))
+		_, err := dec.Next()
+		assert.ErrorIs(t, err, io.EOF, "input %q", input)
+	}
+}
+
+func TestStreamRecordErrors(t *testing.T) {
+	v0 := streamVerdict(t, 0)
+	v1 := streamVerdict(t, 1)
+	v1.Pkg = ""
+	v2 := streamVerdict(t, 2)
+
+	b := new(bytes.Buffer)
+	enc, err := NewEncoder(b, FormatNDJSON)
+	require.Nil(t, err)
+	require.Nil(t, enc.Encode(v0))
+	err = enc.Encode(v1)
+	var recordErr *RecordError
+	if assert.ErrorAs(t, err, &recordErr) {
+		assert.Equal(t, 1, recordErr.Index)
+	}
+	require.Nil(t, enc.Encode(v2))
+	require.Nil(t, enc.Close())
+	assert.Equal(t, 2, enc.Count())
+	assert.Error(t, enc.Encode(v0))
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 2)
+	invalid := strings.Replace(lines[0], `"pkg0"`, `""`, 1)
+	tooLarge := strings.Replace(lines[0], `"pkg0"`, `"`+strings.Repeat("a", 1024)+`"`, 1)
+
+	cases := []struct {
+		name   string
+		format Format
+		input  string
+		errs   map[int]error
+	}{
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			input:  strings.Join([]string{lines[0], invalid, "{not json", "", tooLarge, lines[1]}, "\r\n"),
+			errs:   map[int]error{1: nil, 2: nil, 3: ErrRecordTooLarge},
+		},
+		{
+			name:   "json",
+			format: FormatJSON,
+			input:  fmt.Sprintf("[%s, %s, null, 42, %s,\n%s]", lines[0], invalid, tooLarge, lines[1]),
+			errs:   map[int]error{1: nil, 2: nil, 3: nil, 4: ErrRecordTooLarge},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tc.input), WithMaxRecordSize(900))
+			pkgs := []string{}
+			i := 0
+			for v, err := range dec.All() {
+				if want, ok := tc.errs[i]; ok {
+					var recordErr *RecordError
+					if assert.ErrorAs(t, err, &recordErr) {
+						assert.Equal(t, i, recordErr.Index)
+						assert.Positive(t, recordErr.Offset)
+						if want != nil {
+							assert.ErrorIs(t, err, want)
+						}
+					}
+				} else {
+					require.Nil(t, err)
+					pkgs = append(pkgs, v.Pkg)
+				}
+				i++
+			}
+			assert.Equal(t, []string{"pkg0", "pkg2"}, pkgs)
+			assert.Equal(t, len(tc.errs)+2, i)
+		})
+	}
+}
+
+func TestStreamRecordErrorOffset(t *testing.T) {
+	line := string(encodeStream(t, FormatNDJSON, streamVerdict(t, 0)))
+	input := line + "\n  {}\n"
+
+	dec := NewDecoder(strings.NewReader(input), WithFormat(FormatNDJSON))
+	_, err := dec.Next()
+	require.Nil(t, err)
+	_, err = dec.Next()
+	var recordErr *RecordError
+	if assert.ErrorAs(t, err, &recordErr) {
+		assert.Equal(t, 1, recordErr.Index)
+		assert.Equal(t, int64(len(line)+3), recordErr.Offset)
+		assert.Equal(t, "{}", input[recordErr.Offset:recordErr.Offset+2])
+	}
+	_, err = dec.Next()
+	assert.ErrorIs(t, err, io.EOF)
+
+	dec = NewDecoder(strings.NewReader("[ " + input[:len(line)-1] + ",\n  {}]"))
+	_, err = dec.Next()
+	require.Nil(t, err)
+	_, err = dec.Next()
+	if assert.ErrorAs(t, err, &recordErr) {
+		assert.Equal(t, int64(len(line)+5), recordErr.Offset)
+	}
+}
+
+func TestStreamFatalErrors(t *testing.T) {
+	line := strings.TrimSpace(string(encodeStream(t, FormatNDJSON, streamVerdict(t, 0))))
+
+	cases := []struct {
+		name    string
+		input   string
+		options []DecoderOption
+		valid   int
+	}{
+		{"truncated array", "[" + line + "," + line[:20], nil, 1},
+		{"malformed array", "[" + line + "}", nil, 1},
+		{"not an array", `{"pkg": "test"}`, []DecoderOption{WithFormat(FormatJSON)}, 0},
+		{"unknown format", line, []DecoderOption{WithFormat("xml")}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tc.input), tc.options...)
+			valid := 0
+			var last error
+			for _, err := range dec.All() {
+				if err == nil {
+					valid++
+				}
+				last = err
+			}
+			assert.Equal(t, tc.valid, valid)
+			require.Error(t, last)
+			var recordErr *RecordError
+			assert.False(t, errors.As(last, &recordErr))
+
+			// The stream stays aborted
+			_, err := dec.Next()
+			assert.Equal(t, last, err)
+		})
+	}
+
+	_, err := NewEncoder(io.Discard, "xml")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -9,6 +9,7 @@ import (
 	externalRef0 "github.com/listendev/pkg/ecosystem"
 	externalRef1 "github.com/listendev/pkg/models/category"
 	externalRef2 "github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
 	externalRef3 "github.com/listendev/pkg/verdictcode"
 )
 
@@ -19,6 +20,15 @@ type Problem struct {
 	Type   string `json:"type"`
 }
 
+// StatusChange The change of the status of a verdict.
+type StatusChange struct {
+	Actor  string        `ch:"actor" human:"who changed the status" json:"actor" validate:"mandatory"`
+	At     time.Time     `ch:"at" human:"the moment the status changed" json:"at" validate:"mandatory"`
+	From   status.Status `ch:"from" human:"the previous status" json:"from" validate:"is_status"`
+	Reason string        `ch:"reason" human:"why the status changed" json:"reason,omitempty"`
+	To     status.Status `ch:"to" human:"the new status" json:"to" validate:"mandatory,is_status"`
+}
+
 // Verdict defines model for Verdict.
 type Verdict struct {
 	Categories  []externalRef1.Category `ch:"categories" human:"one or more verdict category" json:"categories,omitempty" validate:"required_with=Message,dive,is_category"`
@@ -29,10 +39,12 @@ type Verdict struct {
 	ExpiresAt   *time.Time              `ch:"expires_at" json:"expires_at"`
 	File        string                  `ch:"file" human:"the result file identifying the analysis type" json:"file" validate:"mandatory,is_resultsfile"`
 	Fingerprint string                  `ch:"fingerprint" human:"a string uniquely identifying this verdict instance" json:"fingerprint,omitempty"`
+	History     []StatusChange          `ch:"history" human:"the status changes" json:"history,omitempty" validate:"dive"`
 	Message     string                  `ch:"message" human:"the verdict message" json:"message,omitempty" validate:"omitempty,gt=1"`
 	Metadata    map[string]interface{}  `ch:"metadata" json:"metadata,omitempty"`
 	Org         string                  `ch:"org" human:"the organization name" json:"org,omitempty" validate:"omitempty"`
 	Pkg         string                  `ch:"pkg" human:"the package name" json:"pkg" validate:"mandatory"`
 	Severity    externalRef2.Severity   `ch:"severity" human:"the verdict severity" json:"severity,omitempty" validate:"required_with=Message,isdefault|is_severity"`
+	Status      status.Status           `ch:"status" human:"the verdict status" json:"status,omitempty" validate:"isdefault|is_status"`
 	Version     string                  `ch:"version" human:"the package version" json:"version" validate:"mandatory,semver"`
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/listendev/pkg/ecosystem"
 	maputil "github.com/listendev/pkg/map/util"
 	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/purl"
 	"github.com/listendev/pkg/validate"
 	"github.com/listendev/pkg/verdictcode"
 	"golang.org/x/exp/maps"
@@ -24,6 +26,9 @@ const (
 
 var CompactMetadata = true
 
+// StrictMetadata makes the validation of the verdicts check their metadata against the metadata type of their code (see metadata.Decode).
+var StrictMetadata = false
+
 func NewEmptyVerdict(eco ecosystem.Ecosystem, org, pkg, version, digest, file string) (*Verdict, error) {
 	now := time.Now()
 	v := Verdict{
@@ -82,6 +87,17 @@ func (o *Verdict) Validate() error {
 			}
 		}
 	}
+	if StrictMetadata && !codeError && o.Code != verdictcode.UNK {
+		if err := metadata.Validate(o.Code, o.Metadata); err != nil {
+			all["Metadata"] = err
+		}
+	}
+	// The history must lead to the status
+	if _, historyError := all["History"]; !historyError {
+		if err := o.validateHistory(); err != nil {
+			all["History"] = err
+		}
+	}
 	// Other contextual validations
 	switch o.Ecosystem {
 	case ecosystem.Npm:
@@ -208,8 +224,41 @@ func (o Verdict) Key() (string, error) {
 	return fmt.Sprintf("%s/%s/%s/%s/%s", o.Ecosystem.Case(), name, o.Version, o.Digest, o.File), nil
 }
 
+// PURL returns the package URL of the package the verdict is about, with its digest as checksum.
+func (o Verdict) PURL() (string, error) {
+	if err := o.Validate(); err != nil {
+		return "", err
+	}
+
+	p, err := purl.New(o.Ecosystem, o.Org, o.Pkg, o.Version, o.Digest)
+	if err != nil {
+		return "", err
+	}
+
+	return p.String(), nil
+}
+
+// TypedMetadata returns the metadata of the verdict as a pointer to the metadata type of its code (see metadata.Decode).
+func (o *Verdict) TypedMetadata() (any, error) {
+	return metadata.Decode(o.Code, o.Metadata)
+}
+
+// SetTypedMetadata sets the metadata of the verdict from (a pointer to) the metadata type of its code (see metadata.Encode).
+func (o *Verdict) SetTypedMetadata(m any) error {
+	res, err := metadata.Encode(o.Code, m)
+	if err != nil {
+		return err
+	}
+	o.Metadata = res
+
+	return nil
+}
+
 type Verdicts []Verdict
 
+// FromBuffer reads a JSON array of verdicts at once.
+//
+// Use NewDecoder to read large sets of verdicts.
 func FromBuffer(stream io.Reader) (Verdicts, error) {
 	b := new(bytes.Buffer)
 	_, err := b.ReadFrom(stream)
@@ -225,6 +274,9 @@ func FromBuffer(stream io.Reader) (Verdicts, error) {
 	return res, nil
 }
 
+// Buffer marshals the verdicts into a JSON array at once.
+//
+// Use NewEncoder to write large sets of verdicts.
 func (v *Verdicts) Buffer() (io.Reader, error) {
 	buf, err := json.Marshal(v)
 	if err != nil {
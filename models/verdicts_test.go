@@ -14,9 +14,11 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/listendev/pkg/ecosystem"
 	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/metadata"
 	"github.com/listendev/pkg/models/severity"
 	"github.com/listendev/pkg/verdictcode"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNPMVerdictValidation(t *testing.T) {
@@ -102,7 +104,7 @@ func TestNPMVerdictValidation(t *testing.T) {
 	e = v.Validate()
 	if assert.Error(t, e) {
 		assert.True(t, strings.HasPrefix(e.Error(), "validation errors:"))
-		assert.True(t, strings.Contains(e.Error(), "severity must be low, medium, or high"))
+		assert.True(t, strings.Contains(e.Error(), "severity must be info, low, medium, high, or critical"))
 	}
 	v.Severity, _ = severity.New("HIGH")
 
@@ -189,6 +191,26 @@ func TestKey(t *testing.T) {
 	assert.Equal(t, "pypi/boto3/1.33.8/879524fd7166d1a8659cd0f5d81800afb268d8c21231312213aasadsda213321/typosquat.json", k2)
 }
 
+func TestPURL(t *testing.T) {
+	v1, err1 := NewEmptyVerdict(ecosystem.Npm, "@phantom", "synpress", "4.0.0-alpha.19", "879524fd7166d1a8659cd0f5d81800afb268d8c2", "metadata(mismatches).json")
+	assert.Nil(t, err1)
+
+	p1, p1Err := v1.PURL()
+	assert.Nil(t, p1Err)
+	assert.Equal(t, "pkg:npm/%40phantom/synpress@4.0.0-alpha.19?checksum=sha1:879524fd7166d1a8659cd0f5d81800afb268d8c2", p1)
+
+	v2, err2 := NewEmptyVerdict(ecosystem.Pypi, "", "Boto3", "1.33.8", "879524fd7166d1a8659cd0f5d81800afb268d8c21231312213aasadsda213321", "typosquat.json")
+	assert.Nil(t, err2)
+
+	p2, p2Err := v2.PURL()
+	assert.Nil(t, p2Err)
+	assert.Equal(t, "pkg:pypi/boto3@1.33.8?checksum=blake2b-256:879524fd7166d1a8659cd0f5d81800afb268d8c21231312213aasadsda213321", p2)
+
+	v2.Version = "latest"
+	_, p3Err := v2.PURL()
+	assert.Error(t, p3Err)
+}
+
 func TestMarshalNPMOkVerdict(t *testing.T) {
 	now := time.Now()
 	v := Verdict{
@@ -627,3 +649,52 @@ func TestFromBuffer(t *testing.T) {
 		t.Fatalf("values are not the same:\n%s", cmp.Diff(got, want))
 	}
 }
+
+func TestTypedMetadata(t *testing.T) {
+	v, err := NewEmptyVerdict(ecosystem.Npm, "", "reqeusts", "1.0.0", "879524fd7166d1a8659cd0f5d81800afb268d8c2", "typosquat.json")
+	require.Nil(t, err)
+	v.Code = verdictcode.TSN01
+
+	require.Nil(t, v.SetTypedMetadata(&metadata.Typosquat{Target: "requests", Distance: 1}))
+	assert.Equal(t, map[string]interface{}{"target": "requests", "distance": float64(1)}, v.Metadata)
+
+	got, err := v.TypedMetadata()
+	require.Nil(t, err)
+	assert.Equal(t, &metadata.Typosquat{Target: "requests", Distance: 1}, got)
+
+	assert.Error(t, v.SetTypedMetadata(&metadata.Maintainer{Email: "someone@example.com"}))
+	v.Metadata["similar_to"] = "request"
+	_, err = v.TypedMetadata()
+	assert.Error(t, err)
+}
+
+func TestStrictMetadata(t *testing.T) {
+	data := []byte(`{
+		"created_at": "2024-01-02T03:04:05Z",
+		"ecosystem": "npm",
+		"pkg": "reqeusts",
+		"version": "1.0.0",
+		"digest": "879524fd7166d1a8659cd0f5d81800afb268d8c2",
+		"file": "typosquat.json",
+		"message": "reqeusts could be a typosquat of requests",
+		"severity": "medium",
+		"categories": ["cybersquatting"],
+		"code": "TSN01",
+		"metadata": {"target": "requests"}
+	}`)
+
+	var v Verdict
+	require.Nil(t, json.Unmarshal(data, &v))
+
+	StrictMetadata = true
+	defer func() {
+		StrictMetadata = false
+	}()
+	err := json.Unmarshal(data, &v)
+	if assert.Error(t, err) {
+		assert.Equal(t, "validation error: invalid TSN01 metadata: validation error: the edit distance from the name of the popular package is mandatory", err.Error())
+	}
+
+	v.Metadata["distance"] = float64(1)
+	assert.Nil(t, v.Validate())
+}
@@ -1,5 +1,12 @@
 package npm
 
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
 type Dist struct {
 	Shasum       string `json:"shasum"`
 	TarballURL   string `json:"tarball"`
@@ -11,3 +18,60 @@ type DistTags struct {
 	Latest string `json:"latest"`
 	Next   string `json:"next"`
 }
+
+// Bin maps the names of the commands a package installs to the paths of their files.
+//
+// The package.json can have the path of a single command named as the package instead,
+// which ends up under the empty name (see Commands).
+type Bin map[string]string
+
+func (b *Bin) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*b = Bin{}
+		if single != "" {
+			(*b)[""] = single
+		}
+
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("couldn't unmarshal bin data %q", string(data))
+	}
+	*b = m
+
+	return nil
+}
+
+// Commands returns the commands of the package with the given name, with their paths cleaned (eg., ./cli.js becomes cli.js).
+//
+// The single command of the package gets the name of the package, without its scope, as npm does.
+func (b Bin) Commands(pkg string) map[string]string {
+	res := map[string]string{}
+	for name, p := range b {
+		if name == "" {
+			_, name = SplitName(pkg)
+		}
+		res[name] = path.Clean(strings.TrimPrefix(p, "./"))
+	}
+
+	return res
+}
+
+// Engines maps the engines (eg., node) to the ranges of their versions a package works with.
+//
+// The legacy array form is ignored, as npm does.
+type Engines map[string]string
+
+func (e *Engines) UnmarshalJSON(data []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		*e = Engines{}
+
+		return nil //nolint:nilerr // Not an object
+	}
+	*e = m
+
+	return nil
+}
@@ -11,6 +11,9 @@ import (
 
 type PackageJSON struct {
 	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Bin                  Bin               `json:"bin"`
+	Engines              Engines           `json:"engines"`
 	Scripts              map[string]string `json:"scripts"`
 	Dependencies         map[string]string `json:"dependencies"`
 	DevDependencies      map[string]string `json:"devDependencies"`
@@ -145,6 +145,27 @@ func TestNewPackageJSONFromReader(t *testing.T) {
 			},
 			wantErr: "",
 		},
+		{
+			desc: "version-bin-engines",
+			input: heredoc.Doc(`{
+	"name": "@acme/tool",
+	"version": "1.0.0",
+	"bin": "./cli.js",
+	"engines": ["node >= 0.4"]
+}`),
+			output: &PackageJSON{
+				Name:    "@acme/tool",
+				Version: "1.0.0",
+				Bin:     Bin{"": "./cli.js"},
+				Engines: Engines{},
+			},
+		},
+		{
+			desc:    "invalid-bin",
+			input:   `{"name": "xxx", "bin": 42}`,
+			output:  nil,
+			wantErr: "couldn't instantiate from the input package.json contents",
+		},
 	}
 
 	for _, tc := range tests {
@@ -162,3 +183,9 @@ func TestNewPackageJSONFromReader(t *testing.T) {
 		})
 	}
 }
+
+func TestBinCommands(t *testing.T) {
+	assert.Equal(t, map[string]string{"tool": "cli.js"}, Bin{"": "./cli.js"}.Commands("@acme/tool"))
+	assert.Equal(t, map[string]string{"a": "bin/a.js", "b": "b.js"}, Bin{"a": "./bin/../bin/a.js", "b": "b.js"}.Commands("pkg"))
+	assert.Empty(t, Bin(nil).Commands("pkg"))
+}
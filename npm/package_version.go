@@ -14,14 +14,17 @@ type PackageMaintainers []PackageMaintainer
 
 // PackageVersion represents the NPM registry response for the route <package_name>/<version>.
 type PackageVersion struct {
-	Name            string             `json:"name"`
-	Description     string             `json:"description"`
-	Version         string             `json:"version"`
-	Dist            Dist               `json:"dist"`
-	Maintainers     PackageMaintainers `json:"maintainers"`
-	Scripts         map[string]string  `json:"scripts"`
-	Dependencies    map[string]string  `json:"dependencies"`
-	DevDependencies map[string]string  `json:"devDependencies"`
+	Name                 string             `json:"name"`
+	Description          string             `json:"description"`
+	Version              string             `json:"version"`
+	Dist                 Dist               `json:"dist"`
+	Maintainers          PackageMaintainers `json:"maintainers"`
+	Bin                  Bin                `json:"bin"`
+	Engines              Engines            `json:"engines"`
+	Scripts              map[string]string  `json:"scripts"`
+	Dependencies         map[string]string  `json:"dependencies"`
+	DevDependencies      map[string]string  `json:"devDependencies"`
+	OptionalDependencies map[string]string  `json:"optionalDependencies"`
 }
 
 func (pm PackageMaintainers) Emails() []string {
@@ -0,0 +1,134 @@
+package tracer
+
+import (
+	"context"
+	"maps"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ propagation.TextMapCarrier = (AMQPCarrier)(nil)
+
+// MessagingRabbitmqRedeliveredKey is the span attribute telling whether a RabbitMQ message has been redelivered.
+const MessagingRabbitmqRedeliveredKey = attribute.Key("messaging.rabbitmq.redelivered")
+
+// Those are the AMQP headers carrying the W3C traceparent.
+const (
+	// HeaderTraceParent is the header the propagator injects
+	HeaderTraceParent = "traceparent"
+	// HeaderRequestTraceParent is the header carrying the traceparent of the metadata of the analysis requests
+	HeaderRequestTraceParent = "x-request-traceparent"
+)
+
+// propagator propagates the W3C trace context and baggage.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// AMQPCarrier adapts the AMQP headers to a TextMapCarrier.
+type AMQPCarrier amqp.Table
+
+func (c AMQPCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+
+	return v
+}
+
+func (c AMQPCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c AMQPCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// InjectAMQP injects the trace context of the given context into the AMQP headers.
+//
+// It returns the headers, creating them when nil.
+func InjectAMQP(ctx context.Context, headers amqp.Table) amqp.Table {
+	if headers == nil {
+		headers = amqp.Table{}
+	}
+	propagator.Inject(ctx, AMQPCarrier(headers))
+
+	return headers
+}
+
+// ExtractAMQP returns a copy of the given context carrying the trace context of the AMQP headers.
+//
+// It falls back to the traceparent of the metadata of the analysis requests (see HeaderRequestTraceParent)
+// when the headers have no traceparent, as with the publishings composed without a context.
+func ExtractAMQP(ctx context.Context, headers amqp.Table) context.Context {
+	if headers == nil {
+		return ctx
+	}
+	carrier := AMQPCarrier(headers)
+	if fallback, ok := headers[HeaderRequestTraceParent].(string); ok && carrier.Get(HeaderTraceParent) == "" {
+		carrier = AMQPCarrier(maps.Clone(headers))
+		carrier.Set(HeaderTraceParent, fallback)
+	}
+
+	return propagator.Extract(ctx, carrier)
+}
+
+// AMQPPublishingAttributes returns the span attributes describing a publishing.
+func AMQPPublishingAttributes(exchange, key string, msg amqp.Publishing) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("rabbitmq"),
+		semconv.MessagingDestinationKey.String(exchange),
+		semconv.MessagingRabbitmqRoutingKeyKey.String(key),
+		semconv.MessagingMessageIDKey.String(msg.MessageId),
+	}
+}
+
+// AMQPDeliveryAttributes returns the span attributes describing a delivery.
+func AMQPDeliveryAttributes(d amqp.Delivery) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("rabbitmq"),
+		semconv.MessagingDestinationKey.String(d.Exchange),
+		semconv.MessagingRabbitmqRoutingKeyKey.String(d.RoutingKey),
+		semconv.MessagingMessageIDKey.String(d.MessageId),
+		MessagingRabbitmqRedeliveredKey.Bool(d.Redelivered),
+	}
+}
+
+// StartAMQPDeliverySpan starts a consumer span continuing the trace the given delivery carries.
+//
+// It uses the tracer in the given context, if any, otherwise a no-op one.
+func StartAMQPDeliverySpan(ctx context.Context, name string, d amqp.Delivery) (context.Context, trace.Span) {
+	t := FromContext(ctx)
+	if t == nil {
+		t = NewNoopTracerProvider().Tracer("noop")
+	}
+	ctx = ExtractAMQP(ctx, d.Headers)
+
+	return t.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(AMQPDeliveryAttributes(d)...),
+		trace.WithAttributes(semconv.MessagingOperationProcess),
+	)
+}
+
+// StartAMQPPublishingSpan starts a producer span for the given publishing, injecting its trace context into the publishing headers.
+//
+// It uses the tracer in the given context, if any, otherwise a no-op one.
+func StartAMQPPublishingSpan(ctx context.Context, name, exchange, key string, msg *amqp.Publishing) (context.Context, trace.Span) {
+	t := FromContext(ctx)
+	if t == nil {
+		t = NewNoopTracerProvider().Tracer("noop")
+	}
+	ctx, span := t.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(AMQPPublishingAttributes(exchange, key, *msg)...),
+	)
+	msg.Headers = InjectAMQP(ctx, msg.Headers)
+
+	return ctx, span
+}
@@ -0,0 +1,96 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAMQPCarrier(t *testing.T) {
+	c := AMQPCarrier(amqp.Table{"x-int": int64(1)})
+	c.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", c.Get("traceparent"))
+	assert.Equal(t, "", c.Get("x-int"))
+	assert.Equal(t, "", c.Get("missing"))
+	assert.ElementsMatch(t, []string{"traceparent", "x-int"}, c.Keys())
+}
+
+func TestExtractWithoutHeaders(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, ctx, ExtractAMQP(ctx, nil))
+	assert.False(t, trace.SpanContextFromContext(ExtractAMQP(ctx, amqp.Table{})).IsValid())
+}
+
+func TestAMQPPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx := NewContext(context.Background(), tp.Tracer("test"))
+
+	msg := &amqp.Publishing{MessageId: "1524854487523524608"}
+	_, pubSpan := StartAMQPPublishingSpan(ctx, "publish", "analysisrequest.hoarding", "typosquat.npm", msg)
+	pubSpan.End()
+	require.Contains(t, msg.Headers, "traceparent")
+	assert.NoError(t, msg.Headers.Validate())
+
+	d := amqp.Delivery{
+		Headers:     msg.Headers,
+		MessageId:   msg.MessageId,
+		Exchange:    "analysisrequest.hoarding",
+		RoutingKey:  "typosquat.npm",
+		Redelivered: true,
+	}
+	consumerCtx, conSpan := StartAMQPDeliverySpan(ctx, "consume", d)
+	conSpan.End()
+
+	// The consumer span continues the trace of the producer span
+	assert.Equal(t, pubSpan.SpanContext().TraceID(), trace.SpanContextFromContext(consumerCtx).TraceID())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind())
+	assert.Equal(t, pubSpan.SpanContext().SpanID(), spans[1].Parent().SpanID())
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[1].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "1524854487523524608", attrs["messaging.message_id"].AsString())
+	assert.Equal(t, "typosquat.npm", attrs["messaging.rabbitmq.routing_key"].AsString())
+	assert.True(t, attrs[MessagingRabbitmqRedeliveredKey].AsBool())
+}
+
+func TestAMQPPropagationWithoutTracer(t *testing.T) {
+	// Remote span context
+	remote := amqp.Table{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx, span := StartAMQPDeliverySpan(context.Background(), "consume", amqp.Delivery{Headers: remote})
+	defer span.End()
+
+	// Keep propagating it
+	msg := &amqp.Publishing{}
+	_, pubSpan := StartAMQPPublishingSpan(ctx, "publish", "", "", msg)
+	defer pubSpan.End()
+	assert.Equal(t, remote["traceparent"], msg.Headers["traceparent"])
+}
+
+func TestExtractRequestTraceParent(t *testing.T) {
+	headers := amqp.Table{HeaderRequestTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	sc := trace.SpanContextFromContext(ExtractAMQP(context.Background(), headers))
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	// The headers stay untouched
+	assert.NotContains(t, headers, HeaderTraceParent)
+
+	// The traceparent of the propagator wins
+	headers[HeaderTraceParent] = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	sc = trace.SpanContextFromContext(ExtractAMQP(context.Background(), headers))
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", sc.TraceID().String())
+}
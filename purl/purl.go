@@ -0,0 +1,271 @@
+package purl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/listendev/pkg/ecosystem"
+	"golang.org/x/exp/maps"
+)
+
+// Scheme is the scheme of the package URLs.
+const Scheme = "pkg"
+
+// ChecksumQualifier is the qualifier carrying the checksums of the package (eg., sha1:<digest>).
+const ChecksumQualifier = "checksum"
+
+// Those are the checksum algorithms of the package digests of the supported ecosystems.
+const (
+	SHA1       = "sha1"
+	BLAKE2b256 = "blake2b-256"
+)
+
+var (
+	ErrInvalid     = errors.New("invalid package URL")
+	ErrUnsupported = errors.New("unsupported package URL type")
+)
+
+// digestAlgorithms are the checksum algorithms of the package digests of the ecosystems.
+var digestAlgorithms = map[ecosystem.Ecosystem]string{
+	ecosystem.Npm:  SHA1,
+	ecosystem.Pypi: BLAKE2b256,
+}
+
+// PURL is a package URL (see https://github.com/package-url/purl-spec).
+//
+// Its components are not percent-encoded.
+type PURL struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+	Subpath    string
+}
+
+// normalizers normalize the package URLs of the types having rules other than the generic ones.
+//
+// The names of the npm packages are not lowercased since the legacy ones can have uppercase letters.
+var normalizers = map[string]func(p *PURL){
+	"npm": func(p *PURL) {
+		p.Namespace = strings.ToLower(p.Namespace)
+	},
+	"pypi": func(p *PURL) {
+		p.Name = strings.ToLower(strings.ReplaceAll(p.Name, "_", "-"))
+	},
+}
+
+func (p *PURL) normalize() {
+	p.Type = strings.ToLower(p.Type)
+	if n, ok := normalizers[p.Type]; ok {
+		n(p)
+	}
+}
+
+// New returns the package URL of the given package.
+//
+// The organization is the namespace (eg., the scope of the npm packages),
+// while the digest becomes the checksum qualifier.
+func New(eco ecosystem.Ecosystem, org, pkg, version, digest string) (PURL, error) {
+	if eco == ecosystem.None {
+		return PURL{}, fmt.Errorf("%w: missing ecosystem", ErrInvalid)
+	}
+	if pkg == "" {
+		return PURL{}, fmt.Errorf("%w: missing name", ErrInvalid)
+	}
+	p := PURL{
+		Type:      eco.Case(),
+		Namespace: org,
+		Name:      pkg,
+		Version:   version,
+	}
+	if digest != "" {
+		alg, ok := digestAlgorithms[eco]
+		if !ok {
+			return PURL{}, fmt.Errorf("%w: %s digests", ErrUnsupported, eco.Case())
+		}
+		p.Qualifiers = map[string]string{ChecksumQualifier: alg + ":" + digest}
+	}
+	p.normalize()
+
+	return p, nil
+}
+
+// Parse parses the given package URL, normalizing it.
+func Parse(s string) (PURL, error) {
+	p := PURL{}
+
+	rest, subpath, _ := strings.Cut(s, "#")
+	segments := []string{}
+	for _, seg := range strings.Split(subpath, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			continue
+		}
+		seg, err := url.PathUnescape(seg)
+		if err != nil {
+			return PURL{}, fmt.Errorf("%w: subpath: %w", ErrInvalid, err)
+		}
+		segments = append(segments, seg)
+	}
+	p.Subpath = strings.Join(segments, "/")
+
+	rest, qualifiers, _ := strings.Cut(rest, "?")
+	if qualifiers != "" {
+		p.Qualifiers = map[string]string{}
+		for _, pair := range strings.Split(qualifiers, "&") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok || k == "" {
+				return PURL{}, fmt.Errorf("%w: qualifier %q", ErrInvalid, pair)
+			}
+			v, err := url.PathUnescape(v)
+			if err != nil {
+				return PURL{}, fmt.Errorf("%w: qualifier %q: %w", ErrInvalid, k, err)
+			}
+			if v != "" {
+				p.Qualifiers[strings.ToLower(k)] = v
+			}
+		}
+	}
+
+	scheme, rest, ok := strings.Cut(rest, ":")
+	if !ok || !strings.EqualFold(scheme, Scheme) {
+		return PURL{}, fmt.Errorf("%w: missing the %s scheme", ErrInvalid, Scheme)
+	}
+	rest = strings.Trim(rest, "/")
+
+	typ, rest, ok := strings.Cut(rest, "/")
+	if !ok || typ == "" {
+		return PURL{}, fmt.Errorf("%w: missing type or name", ErrInvalid)
+	}
+	p.Type = typ
+
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		version, err := url.PathUnescape(rest[i+1:])
+		if err != nil {
+			return PURL{}, fmt.Errorf("%w: version: %w", ErrInvalid, err)
+		}
+		p.Version = version
+		rest = rest[:i]
+	}
+
+	namespace := []string{}
+	for _, seg := range strings.Split(rest, "/") {
+		if seg == "" {
+			continue
+		}
+		seg, err := url.PathUnescape(seg)
+		if err != nil {
+			return PURL{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+		namespace = append(namespace, seg)
+	}
+	if len(namespace) == 0 {
+		return PURL{}, fmt.Errorf("%w: missing name", ErrInvalid)
+	}
+	p.Name = namespace[len(namespace)-1]
+	p.Namespace = strings.Join(namespace[:len(namespace)-1], "/")
+	p.normalize()
+
+	return p, nil
+}
+
+// String returns the canonical form of the package URL.
+func (p PURL) String() string {
+	var b strings.Builder
+	b.WriteString(Scheme)
+	b.WriteByte(':')
+	b.WriteString(strings.ToLower(p.Type))
+	b.WriteByte('/')
+	for _, seg := range strings.Split(p.Namespace, "/") {
+		if seg != "" {
+			b.WriteString(escape(seg))
+			b.WriteByte('/')
+		}
+	}
+	b.WriteString(escape(p.Name))
+	if p.Version != "" {
+		b.WriteByte('@')
+		b.WriteString(escape(p.Version))
+	}
+	if len(p.Qualifiers) > 0 {
+		keys := maps.Keys(p.Qualifiers)
+		slices.Sort(keys)
+		sep := byte('?')
+		for _, k := range keys {
+			if p.Qualifiers[k] == "" {
+				continue
+			}
+			b.WriteByte(sep)
+			b.WriteString(strings.ToLower(k))
+			b.WriteByte('=')
+			b.WriteString(escape(p.Qualifiers[k]))
+			sep = '&'
+		}
+	}
+	if p.Subpath != "" {
+		b.WriteByte('#')
+		segments := []string{}
+		for _, seg := range strings.Split(p.Subpath, "/") {
+			if seg != "" && seg != "." && seg != ".." {
+				segments = append(segments, escape(seg))
+			}
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+
+	return b.String()
+}
+
+// Ecosystem returns the ecosystem of the package URL type.
+func (p PURL) Ecosystem() (ecosystem.Ecosystem, error) {
+	eco, err := ecosystem.FromString(p.Type)
+	if err != nil {
+		return ecosystem.None, fmt.Errorf("%w %q", ErrUnsupported, p.Type)
+	}
+
+	return eco, nil
+}
+
+// FullName returns the name of the package including its namespace (eg., @vue/devtools).
+func (p PURL) FullName() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+
+	return p.Namespace + "/" + p.Name
+}
+
+// Digest returns the package digest from the checksum qualifier, if it has a checksum of the digest algorithm of its ecosystem.
+func (p PURL) Digest() string {
+	eco, err := p.Ecosystem()
+	if err != nil {
+		return ""
+	}
+	alg := digestAlgorithms[eco]
+	for _, checksum := range strings.Split(p.Qualifiers[ChecksumQualifier], ",") {
+		if a, digest, ok := strings.Cut(checksum, ":"); ok && strings.EqualFold(a, alg) {
+			return digest
+		}
+	}
+
+	return ""
+}
+
+// escape percent-encodes all the characters but the unreserved ones and the colon.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~:", c) >= 0 {
+			b.WriteByte(c)
+
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package purl
+
+import (
+	"testing"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input string
+		want  PURL
+		str   string
+	}{
+		{
+			"pkg:npm/chalk@5.1.2",
+			PURL{Type: "npm", Name: "chalk", Version: "5.1.2"},
+			"pkg:npm/chalk@5.1.2",
+		},
+		{
+			"pkg:npm/%40vue/devtools@6.5.0",
+			PURL{Type: "npm", Namespace: "@vue", Name: "devtools", Version: "6.5.0"},
+			"pkg:npm/%40vue/devtools@6.5.0",
+		},
+		{
+			"pkg:npm/@Vue/devtools@6.5.0",
+			PURL{Type: "npm", Namespace: "@vue", Name: "devtools", Version: "6.5.0"},
+			"pkg:npm/%40vue/devtools@6.5.0",
+		},
+		{
+			"PKG:NPM/JSONStream@1.3.5",
+			PURL{Type: "npm", Name: "JSONStream", Version: "1.3.5"},
+			"pkg:npm/JSONStream@1.3.5",
+		},
+		{
+			"pkg:pypi/Typing_Extensions@4.9.0",
+			PURL{Type: "pypi", Name: "typing-extensions", Version: "4.9.0"},
+			"pkg:pypi/typing-extensions@4.9.0",
+		},
+		{
+			"pkg://npm/chalk@5.1.2-beta%2Bbuild.1?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45&Arch=&repository_url=https://registry.npmjs.org#/dist/./index.js",
+			PURL{
+				Type:    "npm",
+				Name:    "chalk",
+				Version: "5.1.2-beta+build.1",
+				Qualifiers: map[string]string{
+					"checksum":       "sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45",
+					"repository_url": "https://registry.npmjs.org",
+				},
+				Subpath: "dist/index.js",
+			},
+			"pkg:npm/chalk@5.1.2-beta%2Bbuild.1?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45&repository_url=https:%2F%2Fregistry.npmjs.org#dist/index.js",
+		},
+		{
+			// Future ecosystems
+			"pkg:maven/org.apache.commons/io@1.3.4",
+			PURL{Type: "maven", Namespace: "org.apache.commons", Name: "io", Version: "1.3.4"},
+			"pkg:maven/org.apache.commons/io@1.3.4",
+		},
+		{
+			"pkg:golang/github.com/listendev/pkg",
+			PURL{Type: "golang", Namespace: "github.com/listendev", Name: "pkg"},
+			"pkg:golang/github.com/listendev/pkg",
+		},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.input)
+		require.Nil(t, err, tc.input)
+		assert.Equal(t, tc.want, got, tc.input)
+		assert.Equal(t, tc.str, got.String(), tc.input)
+
+		// Round trip
+		again, err := Parse(got.String())
+		require.Nil(t, err, tc.input)
+		assert.Equal(t, got, again, tc.input)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"npm/chalk@5.1.2",
+		"http://npm/chalk",
+		"pkg:npm",
+		"pkg:/chalk",
+		"pkg:npm/@5.1.2",
+		"pkg:npm/chalk@5.1.2?checksum",
+		"pkg:npm/chalk@5.1.2?=x",
+		"pkg:npm/ch%zzalk",
+		"pkg:npm/chalk@%zz",
+	} {
+		_, err := Parse(input)
+		assert.ErrorIs(t, err, ErrInvalid, input)
+	}
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(ecosystem.Npm, "@vue", "devtools", "6.5.0", "d957f370038b75ac572471e83be4c5ca9f8e8c45")
+	require.Nil(t, err)
+	assert.Equal(t, "pkg:npm/%40vue/devtools@6.5.0?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45", p.String())
+	assert.Equal(t, "@vue/devtools", p.FullName())
+	assert.Equal(t, "d957f370038b75ac572471e83be4c5ca9f8e8c45", p.Digest())
+	eco, err := p.Ecosystem()
+	require.Nil(t, err)
+	assert.Equal(t, ecosystem.Npm, eco)
+
+	p, err = New(ecosystem.Pypi, "", "Typing_Extensions", "4.9.0", "af72aea155e91adfc61c3ae9e0e342dbc0cba726d6cba4b6c72c1f34e47291cd")
+	require.Nil(t, err)
+	assert.Equal(t, "pkg:pypi/typing-extensions@4.9.0?checksum=blake2b-256:af72aea155e91adfc61c3ae9e0e342dbc0cba726d6cba4b6c72c1f34e47291cd", p.String())
+	assert.Equal(t, "af72aea155e91adfc61c3ae9e0e342dbc0cba726d6cba4b6c72c1f34e47291cd", p.Digest())
+
+	p, err = New(ecosystem.Npm, "", "chalk", "", "")
+	require.Nil(t, err)
+	assert.Equal(t, "pkg:npm/chalk", p.String())
+	assert.Empty(t, p.Digest())
+
+	_, err = New(ecosystem.None, "", "chalk", "5.1.2", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = New(ecosystem.Npm, "", "", "5.1.2", "")
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestDigest(t *testing.T) {
+	p, err := Parse("pkg:npm/chalk@5.1.2?checksum=sha256:abc,SHA1:def")
+	require.Nil(t, err)
+	assert.Equal(t, "def", p.Digest())
+
+	p, err = Parse("pkg:npm/chalk@5.1.2?checksum=sha256:abc")
+	require.Nil(t, err)
+	assert.Empty(t, p.Digest())
+
+	p, err = Parse("pkg:maven/org.apache.commons/io@1.3.4?checksum=sha1:abc")
+	require.Nil(t, err)
+	assert.Empty(t, p.Digest())
+	_, err = p.Ecosystem()
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -49,6 +49,9 @@ type PackageVersion struct {
 	PackageType string    `json:"packagetype"`
 	UploadTime  time.Time `json:"upload_time_iso_8601"`
 	Filename    string    `json:"filename"`
+	// Yanked tells whether the file was yanked, thus the installers ignore it unless pinned
+	Yanked       bool   `json:"yanked"`
+	YankedReason string `json:"yanked_reason"`
 }
 
 type PackageVersions []PackageVersion
@@ -0,0 +1,72 @@
+package typosquat
+
+// bkTree is a Burkhard-Keller tree, indexing the words by their distance to find the ones near a word
+// without comparing it to all of them.
+//
+// It relies on the triangle inequality of the distance.
+type bkTree struct {
+	root *bkNode
+	size int
+}
+
+type bkNode struct {
+	word     string
+	children map[int]*bkNode
+}
+
+// Insert adds the word to the tree, unless it is already there.
+func (t *bkTree) Insert(word string) {
+	if t.root == nil {
+		t.root = &bkNode{word: word}
+		t.size++
+
+		return
+	}
+	n := t.root
+	for {
+		d := Distance(word, n.word)
+		if d == 0 {
+			return
+		}
+		child, ok := n.children[d]
+		if !ok {
+			if n.children == nil {
+				n.children = map[int]*bkNode{}
+			}
+			n.children[d] = &bkNode{word: word}
+			t.size++
+
+			return
+		}
+		n = child
+	}
+}
+
+// Search returns the words at most the given distance away from the word, with their distances.
+func (t *bkTree) Search(word string, maxDistance int) map[string]int {
+	res := map[string]int{}
+	if t.root == nil {
+		return res
+	}
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := Distance(word, n.word)
+		if d <= maxDistance {
+			res[n.word] = d
+		}
+		for cd, child := range n.children {
+			if cd >= d-maxDistance && cd <= d+maxDistance {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	return res
+}
+
+// Len returns how many words the tree has.
+func (t *bkTree) Len() int {
+	return t.size
+}
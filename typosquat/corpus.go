@@ -0,0 +1,176 @@
+package typosquat
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/listendev/pkg/ecosystem"
+)
+
+//go:embed corpus/*.txt
+var corpora embed.FS
+
+var ErrUnsupportedEcosystem = errors.New("unsupported ecosystem")
+
+// Package is a popular package, the target of the typosquats.
+type Package struct {
+	Name string
+	// Downloads is the popularity of the package
+	Downloads int64
+}
+
+// Corpus is a set of popular packages, indexed to find the ones with names similar to a given one.
+type Corpus struct {
+	eco          ecosystem.Ecosystem
+	packages     map[string]Package
+	tree         bkTree
+	skeletons    map[string][]string
+	unseparated  map[string][]string
+	maxDownloads int64
+}
+
+// NewCorpus returns the corpus of the given popular packages.
+//
+// The packages whose names are the same once normalized (see Normalize) count once, with the most downloads.
+func NewCorpus(eco ecosystem.Ecosystem, packages []Package) (*Corpus, error) {
+	if eco != ecosystem.Npm && eco != ecosystem.Pypi {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedEcosystem, eco.Case())
+	}
+	c := &Corpus{
+		eco:         eco,
+		packages:    map[string]Package{},
+		skeletons:   map[string][]string{},
+		unseparated: map[string][]string{},
+	}
+	for _, p := range packages {
+		if strings.TrimSpace(p.Name) == "" {
+			return nil, errors.New("the corpus has a package without name")
+		}
+		if p.Downloads < 0 {
+			return nil, fmt.Errorf("the package %q has negative downloads", p.Name)
+		}
+		key := Normalize(eco, p.Name)
+		if prev, ok := c.packages[key]; ok {
+			if prev.Downloads < p.Downloads {
+				c.packages[key] = p
+			}
+
+			continue
+		}
+		c.packages[key] = p
+		c.tree.Insert(key)
+		c.skeletons[skeleton(key)] = append(c.skeletons[skeleton(key)], key)
+		c.unseparated[unseparated(key)] = append(c.unseparated[unseparated(key)], key)
+	}
+	for _, p := range c.packages {
+		c.maxDownloads = max(c.maxDownloads, p.Downloads)
+	}
+
+	return c, nil
+}
+
+// ParseCorpus reads the corpus of popular packages from lines made of their name and downloads, separated by spaces.
+//
+// It skips the blank lines, and the ones starting with #.
+func ParseCorpus(eco ecosystem.Ecosystem, r io.Reader) (*Corpus, error) {
+	packages := []Package{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expecting the package name and its downloads", line)
+		}
+		downloads, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid downloads %q", line, fields[1])
+		}
+		packages = append(packages, Package{Name: fields[0], Downloads: downloads})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewCorpus(eco, packages)
+}
+
+var defaultCorpora = map[ecosystem.Ecosystem]func() (*Corpus, error){
+	ecosystem.Npm:  sync.OnceValues(func() (*Corpus, error) { return embedded(ecosystem.Npm) }),
+	ecosystem.Pypi: sync.OnceValues(func() (*Corpus, error) { return embedded(ecosystem.Pypi) }),
+}
+
+func embedded(eco ecosystem.Ecosystem) (*Corpus, error) {
+	f, err := corpora.Open(fmt.Sprintf("corpus/%s.txt", eco.Case()))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseCorpus(eco, f)
+}
+
+// DefaultCorpus returns the corpus of the popular packages of the ecosystem embedded in this package.
+//
+// It is a snapshot of the most downloaded packages, with their approximate weekly downloads.
+func DefaultCorpus(eco ecosystem.Ecosystem) (*Corpus, error) {
+	get, ok := defaultCorpora[eco]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedEcosystem, eco.Case())
+	}
+
+	return get()
+}
+
+// Ecosystem returns the ecosystem of the packages of the corpus.
+func (c *Corpus) Ecosystem() ecosystem.Ecosystem {
+	return c.eco
+}
+
+// Len returns how many packages the corpus has.
+func (c *Corpus) Len() int {
+	return len(c.packages)
+}
+
+// Get returns the package with the given name, once normalized.
+func (c *Corpus) Get(name string) (Package, bool) {
+	p, ok := c.packages[Normalize(c.eco, name)]
+
+	return p, ok
+}
+
+// popularity maps the downloads of a package into [0, 1], on a logarithmic scale.
+func (c *Corpus) popularity(p Package) float64 {
+	if c.maxDownloads <= 0 {
+		return 0
+	}
+
+	return math.Log1p(float64(p.Downloads)) / math.Log1p(float64(c.maxDownloads))
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Normalize returns the form of the name of a package the registry of the ecosystem considers the same.
+//
+// The npm names are lowercased.
+// The PyPI names are lowercased, and their runs of -, _, and . become a single - (see PEP 503).
+func Normalize(eco ecosystem.Ecosystem, name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if eco == ecosystem.Pypi {
+		name = pypiSeparators.ReplaceAllString(name, "-")
+	}
+
+	return name
+}
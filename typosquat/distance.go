@@ -0,0 +1,147 @@
+package typosquat
+
+import "strings"
+
+// Distance returns the Damerau-Levenshtein distance between two strings.
+//
+// It counts the insertions, deletions, substitutions, and transpositions of adjacent characters
+// needed to turn a string into the other one.
+// Unlike the optimal string alignment distance it satisfies the triangle inequality, thus it is a metric.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	inf := len(ra) + len(rb)
+	d := make([][]int, len(ra)+2)
+	for i := range d {
+		d[i] = make([]int, len(rb)+2)
+	}
+	d[0][0] = inf
+	for i := 0; i <= len(ra); i++ {
+		d[i+1][0] = inf
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(rb); j++ {
+		d[0][j+1] = inf
+		d[1][j+1] = j
+	}
+
+	// last maps the characters to the last row they appear in
+	last := map[rune]int{}
+	for i := 1; i <= len(ra); i++ {
+		lastCol := 0
+		for j := 1; j <= len(rb); j++ {
+			k, l := last[rb[j-1]], lastCol
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+				lastCol = j
+			}
+			d[i+1][j+1] = min(
+				d[i][j]+cost,              // Substitution
+				d[i+1][j]+1,               // Insertion
+				d[i][j+1]+1,               // Deletion
+				d[k][l]+(i-k-1)+1+(j-l-1), // Transposition
+			)
+		}
+		last[ra[i-1]] = i
+	}
+
+	return d[len(ra)+1][len(rb)+1]
+}
+
+// keyboard is the QWERTY layout, whose rows are shifted by half a key from the previous ones.
+var keyboard = []string{
+	"1234567890-",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+// keys maps the keys to their row and column.
+var keys = func() map[rune][2]int {
+	res := map[rune][2]int{}
+	for r, row := range keyboard {
+		for c, k := range row {
+			res[k] = [2]int{r, c}
+		}
+	}
+
+	return res
+}()
+
+// adjacent tells whether two keys are next to each other on a QWERTY keyboard.
+func adjacent(a, b rune) bool {
+	ka, okA := keys[a]
+	kb, okB := keys[b]
+	if !okA || !okB || a == b {
+		return false
+	}
+	switch kb[0] - ka[0] {
+	case 0:
+		return kb[1]-ka[1] == 1 || ka[1]-kb[1] == 1
+	case -1: // The row above is shifted to the right
+		return kb[1] == ka[1] || kb[1] == ka[1]+1
+	case 1: // The row below is shifted to the left
+		return kb[1] == ka[1] || kb[1] == ka[1]-1
+	}
+
+	return false
+}
+
+// mistyped tells whether a string turns into the other one by hitting a key next to the right one.
+func mistyped(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) != len(rb) {
+		return false
+	}
+	diff := -1
+	for i := range ra {
+		if ra[i] != rb[i] {
+			if diff >= 0 {
+				return false
+			}
+			diff = i
+		}
+	}
+
+	return diff >= 0 && adjacent(ra[diff], rb[diff])
+}
+
+// homoglyphs replaces the sequences of characters looking like other ones.
+var homoglyphs = strings.NewReplacer(
+	"rn", "m",
+	"vv", "w",
+	"cl", "d",
+	"0", "o",
+	"1", "l",
+	"i", "l",
+	"|", "l",
+	"5", "s",
+	"а", "a", // Cyrillic
+	"с", "c",
+	"е", "e",
+	"о", "o",
+	"р", "p",
+	"х", "x",
+	"у", "y",
+	"і", "l",
+)
+
+// skeleton returns the form of a name the names looking like it share.
+func skeleton(name string) string {
+	return homoglyphs.Replace(strings.ToLower(name))
+}
+
+// separators are the characters separating the words of the package names.
+var separators = strings.NewReplacer("-", "", "_", "", ".", "")
+
+// unseparated returns the name without the separators of its words.
+func unseparated(name string) string {
+	return separators.Replace(name)
+}
@@ -0,0 +1,75 @@
+package typosquat
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"lodash", "lodash", 0},
+		{"lodash", "lodahs", 1},
+		{"lodash", "lodas", 1},
+		{"lodash", "llodash", 1},
+		{"lodash", "lodesh", 1},
+		{"express", "exrpess", 1},
+		{"ca", "abc", 2}, // The optimal string alignment distance is 3
+		{"kitten", "sitting", 3},
+		{"réact", "react", 1},
+		{"@babel/core", "@babel/cores", 1},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, Distance(tc.a, tc.b), "%q vs %q", tc.a, tc.b)
+		assert.Equal(t, tc.want, Distance(tc.b, tc.a), "%q vs %q", tc.b, tc.a)
+	}
+}
+
+func TestDistanceIsMetric(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	word := func() string {
+		b := make([]byte, r.IntN(7))
+		for i := range b {
+			b[i] = "abcd"[r.IntN(4)]
+		}
+
+		return string(b)
+	}
+	for range 2000 {
+		a, b, c := word(), word(), word()
+		assert.LessOrEqual(t, Distance(a, c), Distance(a, b)+Distance(b, c), "%q, %q, %q", a, b, c)
+	}
+}
+
+func TestAdjacent(t *testing.T) {
+	for _, pair := range []string{"as", "qw", "qa", "wa", "sz", "gb", "gh", "ty", "0o", "0p", "az", "p-", "mk", "mj"} {
+		a, b := rune(pair[0]), rune(pair[1])
+		assert.True(t, adjacent(a, b), pair)
+		assert.True(t, adjacent(b, a), pair)
+	}
+	for _, pair := range []string{"aa", "ad", "qs", "qz", "ax", "ml", "@a", "/s"} {
+		assert.False(t, adjacent(rune(pair[0]), rune(pair[1])), pair)
+	}
+
+	assert.True(t, mistyped("lodash", "lodasj"))
+	assert.False(t, mistyped("lodash", "lodasv"))
+	assert.False(t, mistyped("lodash", "lodahs"))
+	assert.False(t, mistyped("lodash", "lodash"))
+	assert.False(t, mistyped("lodash", "lodashh"))
+}
+
+func TestSkeleton(t *testing.T) {
+	assert.Equal(t, skeleton("mocha"), skeleton("rnocha"))
+	assert.Equal(t, skeleton("lodash"), skeleton("l0dash"))
+	assert.Equal(t, skeleton("lodash"), skeleton("1odash"))
+	assert.Equal(t, skeleton("lodash"), skeleton("lоdash")) // Cyrillic o
+	assert.Equal(t, skeleton("webpack"), skeleton("vvebpack"))
+	assert.NotEqual(t, skeleton("lodash"), skeleton("lodahs"))
+}
@@ -0,0 +1,237 @@
+// Package typosquat finds the popular packages whose names are similar to the name of a package,
+// for the typosquatting verdicts (TSN01, TSP01).
+package typosquat
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/verdictcode"
+)
+
+// Technique is the way a typosquat name resembles the name of its target.
+type Technique string
+
+const (
+	// TechniqueEdit is a name few insertions, deletions, substitutions, or transpositions away from the target
+	TechniqueEdit Technique = "edit"
+	// TechniqueKeyboard is a name having a character next to the right one on the keyboard (eg., "lodasj" for "lodash")
+	TechniqueKeyboard Technique = "keyboard"
+	// TechniqueHomoglyph is a name having characters looking like the ones of the target (eg., "rnocha" for "mocha")
+	TechniqueHomoglyph Technique = "homoglyph"
+	// TechniqueSeparator is a name with other separators between its words (eg., "react_dom" for "react-dom")
+	TechniqueSeparator Technique = "separator"
+	// TechniqueScope is a scoped npm name of an unscoped target (eg., "@types/lodash" for "lodash")
+	TechniqueScope Technique = "scope"
+)
+
+// similarities are the similarities of the names matching by the techniques not measured by their edit distance.
+var similarities = map[Technique]float64{
+	TechniqueHomoglyph: 0.95,
+	TechniqueSeparator: 0.9,
+	TechniqueScope:     0.8,
+}
+
+const (
+	DefaultMaxDistance = 2
+	DefaultLimit       = 5
+)
+
+// Match is a popular package the name of a package could be a typosquat of.
+type Match struct {
+	Target string
+	// Distance is the Damerau-Levenshtein distance between the names
+	Distance  int
+	Downloads int64
+	// Techniques are the ways the names resemble, sorted
+	Techniques []Technique
+	// Score ranks the matches, from 0 to 1, considering how similar the names are and how popular the target is
+	Score float64
+}
+
+// Metadata returns the metadata of the typosquatting verdict of the match.
+func (m Match) Metadata() metadata.Typosquat {
+	return metadata.Typosquat{
+		Target:    m.Target,
+		Distance:  max(m.Distance, 1),
+		Downloads: m.Downloads,
+	}
+}
+
+type Option func(*Detector)
+
+// WithCorpus is an option to use a corpus of popular packages other than the default one.
+func WithCorpus(c *Corpus) Option {
+	return func(d *Detector) {
+		d.corpus = c
+	}
+}
+
+// WithMaxDistance is an option to set the maximum edit distance of the matches of the names of at least 8 characters.
+//
+// The names shorter than 8 characters match at distance 1, and the ones shorter than 4 characters do not match by edit distance,
+// since the short names are too near each other.
+func WithMaxDistance(n int) Option {
+	return func(d *Detector) {
+		d.maxDistance = n
+	}
+}
+
+// WithLimit is an option to set the maximum number of matches, zero meaning no limit.
+func WithLimit(n int) Option {
+	return func(d *Detector) {
+		d.limit = n
+	}
+}
+
+// Detector finds the popular packages of an ecosystem the names of the packages could be typosquats of.
+type Detector struct {
+	eco         ecosystem.Ecosystem
+	corpus      *Corpus
+	maxDistance int
+	limit       int
+}
+
+// New returns a detector for the given ecosystem, using its default corpus unless told otherwise.
+func New(eco ecosystem.Ecosystem, options ...Option) (*Detector, error) {
+	d := &Detector{
+		eco:         eco,
+		maxDistance: DefaultMaxDistance,
+		limit:       DefaultLimit,
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	if d.maxDistance < 0 {
+		return nil, fmt.Errorf("invalid maximum distance %d", d.maxDistance)
+	}
+	if d.corpus == nil {
+		c, err := DefaultCorpus(eco)
+		if err != nil {
+			return nil, err
+		}
+		d.corpus = c
+	}
+	if d.corpus.Ecosystem() != eco {
+		return nil, fmt.Errorf("the corpus is for %s, not %s", d.corpus.Ecosystem().Case(), eco.Case())
+	}
+
+	return d, nil
+}
+
+// Code returns the code of the typosquatting verdicts of the ecosystem of the detector.
+func (d *Detector) Code() verdictcode.Code {
+	if d.eco == ecosystem.Pypi {
+		return verdictcode.TSP01
+	}
+
+	return verdictcode.TSN01
+}
+
+// Check returns the popular packages the name could be a typosquat of, from the highest score.
+//
+// It returns no matches when the name is the one of a popular package.
+func (d *Detector) Check(name string) []Match {
+	key := Normalize(d.eco, name)
+	if key == "" {
+		return nil
+	}
+	if _, ok := d.corpus.packages[key]; ok {
+		return nil
+	}
+
+	found := map[string]map[Technique]bool{}
+	add := func(target string, t Technique) {
+		if found[target] == nil {
+			found[target] = map[Technique]bool{}
+		}
+		found[target][t] = true
+	}
+
+	for target, dist := range d.corpus.tree.Search(key, d.allowedDistance(key)) {
+		add(target, TechniqueEdit)
+		if dist == 1 && mistyped(key, target) {
+			add(target, TechniqueKeyboard)
+		}
+	}
+	for _, target := range d.corpus.skeletons[skeleton(key)] {
+		add(target, TechniqueHomoglyph)
+	}
+	for _, target := range d.corpus.unseparated[unseparated(key)] {
+		add(target, TechniqueSeparator)
+	}
+	if d.eco == ecosystem.Npm && strings.HasPrefix(key, "@") {
+		if _, bare, ok := strings.Cut(key, "/"); ok {
+			if _, popular := d.corpus.packages[bare]; popular {
+				add(bare, TechniqueScope)
+			}
+		}
+	}
+
+	res := make([]Match, 0, len(found))
+	for target, techniques := range found {
+		p := d.corpus.packages[target]
+		m := Match{
+			Target:    p.Name,
+			Distance:  Distance(key, target),
+			Downloads: p.Downloads,
+		}
+		similarity := 0.0
+		for t := range techniques {
+			m.Techniques = append(m.Techniques, t)
+			s, ok := similarities[t]
+			if !ok {
+				s = 1 - float64(m.Distance)/float64(max(utf8.RuneCountInString(key), utf8.RuneCountInString(target)))
+				if t == TechniqueKeyboard {
+					// The slips of the fingers are more likely than the other edits
+					s += (1 - s) / 2
+				}
+			}
+			similarity = max(similarity, s)
+		}
+		slices.Sort(m.Techniques)
+		m.Score = similarity * (0.5 + 0.5*d.corpus.popularity(p))
+		res = append(res, m)
+	}
+	slices.SortFunc(res, func(a, b Match) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score > b.Score {
+				return -1
+			}
+
+			return 1
+		case a.Distance != b.Distance:
+			return a.Distance - b.Distance
+		case a.Downloads != b.Downloads:
+			if a.Downloads > b.Downloads {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a.Target, b.Target)
+	})
+	if d.limit > 0 && len(res) > d.limit {
+		res = res[:d.limit]
+	}
+
+	return res
+}
+
+// allowedDistance returns the maximum edit distance of the matches of the name, depending on its length.
+func (d *Detector) allowedDistance(name string) int {
+	switch n := utf8.RuneCountInString(name); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return min(d.maxDistance, 1)
+	}
+
+	return d.maxDistance
+}
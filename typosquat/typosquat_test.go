@@ -0,0 +1,202 @@
+package typosquat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/listendev/pkg/ecosystem"
+	"github.com/listendev/pkg/models/metadata"
+	"github.com/listendev/pkg/validate"
+	"github.com/listendev/pkg/verdictcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		eco        ecosystem.Ecosystem
+		name       string
+		target     string
+		distance   int
+		techniques []Technique
+	}{
+		{ecosystem.Npm, "lodahs", "lodash", 1, []Technique{TechniqueEdit}},
+		{ecosystem.Npm, "lodasj", "lodash", 1, []Technique{TechniqueEdit, TechniqueKeyboard}},
+		{ecosystem.Npm, "rnocha", "mocha", 2, []Technique{TechniqueHomoglyph}},
+		{ecosystem.Npm, "vvebpack", "webpack", 2, []Technique{TechniqueEdit, TechniqueHomoglyph}},
+		{ecosystem.Npm, "react_dom", "react-dom", 1, []Technique{TechniqueEdit, TechniqueSeparator}},
+		{ecosystem.Npm, "reactdom", "react-dom", 1, []Technique{TechniqueEdit, TechniqueSeparator}},
+		{ecosystem.Npm, "jsonweb-token", "jsonwebtoken", 1, []Technique{TechniqueEdit, TechniqueSeparator}},
+		{ecosystem.Npm, "@evil/chalk", "chalk", 6, []Technique{TechniqueScope}},
+		{ecosystem.Npm, "@types/express-foo", "", 0, nil},
+		{ecosystem.Npm, "@angullar/core", "@angular/core", 1, []Technique{TechniqueEdit}},
+		{ecosystem.Npm, "Expres", "express", 1, []Technique{TechniqueEdit}},
+		{ecosystem.Pypi, "reqeusts", "requests", 1, []Technique{TechniqueEdit}},
+		{ecosystem.Pypi, "beautifulsoup", "beautifulsoup4", 1, []Technique{TechniqueEdit}},
+		{ecosystem.Pypi, "djang0", "django", 1, []Technique{TechniqueEdit, TechniqueHomoglyph, TechniqueKeyboard}},
+		{ecosystem.Pypi, "pythondateutil", "python-dateutil", 1, []Technique{TechniqueEdit, TechniqueSeparator}},
+		{ecosystem.Pypi, "python__dateutils", "python-dateutil", 1, []Technique{TechniqueEdit}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.eco.Case()+"/"+tc.name, func(t *testing.T) {
+			d, err := New(tc.eco)
+			require.Nil(t, err)
+
+			got := d.Check(tc.name)
+			if tc.target == "" {
+				assert.Empty(t, got)
+
+				return
+			}
+			require.NotEmpty(t, got)
+			assert.Equal(t, tc.target, got[0].Target)
+			assert.Equal(t, tc.distance, got[0].Distance)
+			assert.Equal(t, tc.techniques, got[0].Techniques)
+			assert.Positive(t, got[0].Downloads)
+			assert.True(t, got[0].Score > 0 && got[0].Score <= 1)
+		})
+	}
+}
+
+func TestCheckPopular(t *testing.T) {
+	npm, err := New(ecosystem.Npm)
+	require.Nil(t, err)
+	for _, name := range []string{"lodash", "Lodash", "@types/lodash", "react-dom", "", "ms", "foo"} {
+		assert.Empty(t, npm.Check(name), name)
+	}
+
+	pypi, err := New(ecosystem.Pypi)
+	require.Nil(t, err)
+	for _, name := range []string{"requests", "Python_Dateutil", "python.dateutil", "typing_extensions"} {
+		assert.Empty(t, pypi.Check(name), name)
+	}
+	// PyPI has no scopes
+	assert.Empty(t, pypi.Check("@evil/requests"))
+}
+
+func TestCheckRanking(t *testing.T) {
+	c, err := NewCorpus(ecosystem.Npm, []Package{
+		{Name: "colors", Downloads: 100},
+		{Name: "color", Downloads: 1_000_000},
+		{Name: "coolor", Downloads: 1_000_000},
+		{Name: "colour", Downloads: 10},
+	})
+	require.Nil(t, err)
+
+	d, err := New(ecosystem.Npm, WithCorpus(c), WithLimit(0))
+	require.Nil(t, err)
+	got := d.Check("colr")
+	targets := []string{}
+	for _, m := range got {
+		targets = append(targets, m.Target)
+	}
+	// The short names match at distance 1 only
+	assert.Equal(t, []string{"color"}, targets)
+
+	got = d.Check("colorr")
+	targets = targets[:0]
+	for _, m := range got {
+		targets = append(targets, m.Target)
+	}
+	assert.Equal(t, []string{"color", "colors", "colour"}, targets)
+	for i := 1; i < len(got); i++ {
+		assert.GreaterOrEqual(t, got[i-1].Score, got[i].Score)
+	}
+
+	d, err = New(ecosystem.Npm, WithCorpus(c), WithLimit(1))
+	require.Nil(t, err)
+	assert.Len(t, d.Check("colorr"), 1)
+
+	d, err = New(ecosystem.Npm, WithCorpus(c), WithMaxDistance(0))
+	require.Nil(t, err)
+	assert.Empty(t, d.Check("colorr"))
+}
+
+func TestCheckDistanceByLength(t *testing.T) {
+	c, err := NewCorpus(ecosystem.Npm, []Package{
+		{Name: "abc", Downloads: 1},
+		{Name: "abcdef", Downloads: 1},
+		{Name: "abcdefghij", Downloads: 1},
+	})
+	require.Nil(t, err)
+	d, err := New(ecosystem.Npm, WithCorpus(c), WithMaxDistance(3))
+	require.Nil(t, err)
+
+	assert.Empty(t, d.Check("abd"))
+	assert.Len(t, d.Check("abcdxf"), 1)
+	assert.Empty(t, d.Check("abxdxf"))
+	assert.Len(t, d.Check("abxdxfxhij"), 1)
+	assert.Empty(t, d.Check("axxdxfxhij"))
+}
+
+func TestMatchMetadata(t *testing.T) {
+	for _, eco := range []ecosystem.Ecosystem{ecosystem.Npm, ecosystem.Pypi} {
+		d, err := New(eco)
+		require.Nil(t, err)
+		code := verdictcode.TSN01
+		if eco == ecosystem.Pypi {
+			code = verdictcode.TSP01
+		}
+		assert.Equal(t, code, d.Code())
+
+		for _, m := range d.Check("reqeusts") {
+			md := m.Metadata()
+			assert.Empty(t, validate.Validate(md))
+			encoded, err := metadata.Encode(d.Code(), md)
+			require.Nil(t, err)
+			assert.Equal(t, m.Target, encoded["target"])
+		}
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	for _, eco := range []ecosystem.Ecosystem{ecosystem.Npm, ecosystem.Pypi} {
+		c, err := DefaultCorpus(eco)
+		require.Nil(t, err)
+		assert.Equal(t, eco, c.Ecosystem())
+		assert.Greater(t, c.Len(), 100)
+		assert.Equal(t, c.Len(), c.tree.Len())
+		again, _ := DefaultCorpus(eco)
+		assert.Same(t, c, again)
+	}
+
+	c, err := ParseCorpus(ecosystem.Pypi, strings.NewReader("# Comment\n\nPython_Dateutil 10\npython-dateutil 20\n  six 5  \n"))
+	require.Nil(t, err)
+	assert.Equal(t, 2, c.Len())
+	p, ok := c.Get("python.dateutil")
+	assert.True(t, ok)
+	assert.Equal(t, Package{Name: "python-dateutil", Downloads: 20}, p)
+
+	_, err = ParseCorpus(ecosystem.Npm, strings.NewReader("lodash\n"))
+	assert.ErrorContains(t, err, "line 1")
+	_, err = ParseCorpus(ecosystem.Npm, strings.NewReader("lodash many\n"))
+	assert.ErrorContains(t, err, "invalid downloads")
+	_, err = NewCorpus(ecosystem.Npm, []Package{{Name: " "}})
+	assert.Error(t, err)
+	_, err = NewCorpus(ecosystem.None, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedEcosystem)
+	_, err = DefaultCorpus(ecosystem.None)
+	assert.ErrorIs(t, err, ErrUnsupportedEcosystem)
+
+	_, err = New(ecosystem.Npm, WithCorpus(c))
+	assert.ErrorContains(t, err, "the corpus is for pypi, not npm")
+	_, err = New(ecosystem.Npm, WithMaxDistance(-1))
+	assert.Error(t, err)
+}
+
+func TestBKTree(t *testing.T) {
+	c, err := DefaultCorpus(ecosystem.Npm)
+	require.Nil(t, err)
+
+	for _, word := range []string{"lodash", "reakt", "@babel/cor", "x", "typescirpt", "commanderr"} {
+		for maxDistance := range 4 {
+			want := map[string]int{}
+			for key := range c.packages {
+				if d := Distance(word, key); d <= maxDistance {
+					want[key] = d
+				}
+			}
+			assert.Equal(t, want, c.tree.Search(word, maxDistance), "%q within %d", word, maxDistance)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/listendev/pkg/purl"
+)
+
+// isPURL tells whether the field is a package URL of a supported ecosystem.
+//
+// The optional parameter restricts the package URL types (eg., purl=npm).
+func isPURL(fl validator.FieldLevel) bool {
+	field := fl.Field()
+
+	if field.Kind() == reflect.String {
+		p, err := purl.Parse(field.String())
+		if err != nil {
+			return false
+		}
+		if _, err := p.Ecosystem(); err != nil {
+			return false
+		}
+		if param := fl.Param(); param != "" {
+			return strings.EqualFold(p.Type, param)
+		}
+
+		return true
+	}
+
+	panic(fmt.Sprintf("bad field type: %T", field.Interface()))
+}
@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type purlTest struct {
+	Any string `validate:"purl"`
+	Npm string `validate:"omitempty,purl=npm"`
+}
+
+type badPURLTest struct {
+	Wrong []uint8 `validate:"purl"`
+}
+
+func TestPURLValidatorBadFieldType(t *testing.T) {
+	input := badPURLTest{
+		Wrong: []uint8("pkg:npm/chalk@5.1.2"),
+	}
+	require.PanicsWithValue(t, "bad field type: []uint8", func() {
+		//nolint:errcheck // we are checking it panics
+		Singleton.Struct(input)
+	})
+}
+
+func TestPURLValidator(t *testing.T) {
+	valid := []purlTest{
+		{Any: "pkg:npm/%40vue/devtools@6.5.0", Npm: "pkg:npm/chalk@5.1.2?checksum=sha1:d957f370038b75ac572471e83be4c5ca9f8e8c45"},
+		{Any: "pkg:pypi/typing-extensions@4.9.0"},
+	}
+	for _, v := range valid {
+		assert.NoError(t, Singleton.Struct(v), v.Any)
+	}
+
+	invalid := []purlTest{
+		{Any: "npm/chalk@5.1.2"},
+		{Any: "pkg:maven/org.apache.commons/io@1.3.4"},
+		{Any: "pkg:npm/chalk@5.1.2", Npm: "pkg:pypi/requests@2.31.0"},
+	}
+	for _, v := range invalid {
+		errs := Validate(v)
+		require.Len(t, errs, 1, v.Any)
+		assert.Contains(t, errs[0].Error(), "must be a valid package URL")
+	}
+}
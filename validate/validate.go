@@ -15,6 +15,7 @@ import (
 	informationaltype "github.com/listendev/pkg/informational/type"
 	"github.com/listendev/pkg/models/category"
 	"github.com/listendev/pkg/models/severity"
+	"github.com/listendev/pkg/models/status"
 	"github.com/listendev/pkg/verdictcode"
 	"golang.org/x/exp/slices"
 )
@@ -64,6 +65,20 @@ func init() {
 		panic(err)
 	}
 
+	if err := Singleton.RegisterValidation("is_status", func(fl validator.FieldLevel) bool {
+		f := fl.Field()
+
+		if f.Kind() == reflect.String {
+			_, err := status.New(f.String())
+
+			return err == nil
+		}
+
+		panic(fmt.Sprintf("bad field type: %T", f.Interface()))
+	}); err != nil {
+		panic(err)
+	}
+
 	if err := Singleton.RegisterValidation("is_category", func(fl validator.FieldLevel) bool {
 		f := fl.Field()
 
@@ -210,6 +225,10 @@ func init() {
 		panic(err)
 	}
 
+	if err := Singleton.RegisterValidation("purl", isPURL); err != nil {
+		panic(err)
+	}
+
 	eng := en.New()
 	Translator, _ = (ut.New(eng, eng)).GetTranslator("en")
 	if err := en_translations.RegisterDefaultTranslations(Singleton, Translator); err != nil {
@@ -235,7 +254,7 @@ func init() {
 		"is_severity",
 		Translator,
 		func(ut ut.Translator) error {
-			return ut.Add("is_severity", "{0} must be low, medium, or high", true)
+			return ut.Add("is_severity", "{0} must be info, low, medium, high, or critical", true)
 		},
 		func(ut ut.Translator, fe validator.FieldError) string {
 			t, _ := ut.T("is_severity", fe.Field())
@@ -246,6 +265,21 @@ func init() {
 		panic(err)
 	}
 
+	if err := Singleton.RegisterTranslation(
+		"is_status",
+		Translator,
+		func(ut ut.Translator) error {
+			return ut.Add("is_status", "{0} must be open, acknowledged, false-positive, accepted-risk, or fixed", true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("is_status", fe.Field())
+
+			return t
+		},
+	); err != nil {
+		panic(err)
+	}
+
 	if err := Singleton.RegisterTranslation(
 		"is_detection_event_type",
 		Translator,
@@ -296,7 +330,7 @@ func init() {
 		Translator,
 
 		func(ut ut.Translator) error {
-			return ut.Add("isdefault|is_severity", "{0} must be low, medium, or high", true)
+			return ut.Add("isdefault|is_severity", "{0} must be info, low, medium, high, or critical", true)
 		},
 		func(ut ut.Translator, fe validator.FieldError) string {
 			t, _ := ut.T("isdefault|is_severity", fe.Field())
@@ -307,6 +341,22 @@ func init() {
 		panic(err)
 	}
 
+	if err := Singleton.RegisterTranslation(
+		"isdefault|is_status",
+		Translator,
+
+		func(ut ut.Translator) error {
+			return ut.Add("isdefault|is_status", "{0} must be open, acknowledged, false-positive, accepted-risk, or fixed", true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("isdefault|is_status", fe.Field())
+
+			return t
+		},
+	); err != nil {
+		panic(err)
+	}
+
 	if err := Singleton.RegisterTranslation(
 		"shasum",
 		Translator,
@@ -489,6 +539,21 @@ func init() {
 		panic(err)
 	}
 
+	if err := Singleton.RegisterTranslation(
+		"purl",
+		Translator,
+		func(ut ut.Translator) error {
+			return ut.Add("purl", "{0} must be a valid package URL", true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("purl", fe.Field())
+
+			return t
+		},
+	); err != nil {
+		panic(err)
+	}
+
 	if err := Singleton.RegisterTranslation(
 		"required_with",
 		Translator,
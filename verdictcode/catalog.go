@@ -0,0 +1,225 @@
+package verdictcode
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/invopop/yaml"
+	"github.com/listendev/pkg/models/category"
+	"github.com/listendev/pkg/models/severity"
+)
+
+//go:embed code.yml
+var codeSpec []byte
+
+//go:embed catalog.yml
+var catalogSpec []byte
+
+// Info describes a verdict code.
+type Info struct {
+	// Code is the verdict code
+	Code Code `json:"code"`
+	// Title is a short human-readable name of the verdicts with the code
+	Title string `json:"title"`
+	// Description explains what the verdicts with the code detect and why it matters
+	Description string `json:"description"`
+	// Severity is the default severity of the verdicts with the code
+	Severity severity.Severity `json:"severity"`
+	// Categories are the default categories of the verdicts with the code
+	Categories []category.Category `json:"categories"`
+	// Remediation explains what to do about the verdicts with the code
+	Remediation string `json:"remediation"`
+	// References are links to further information
+	References []string `json:"references,omitempty"`
+	// Since is the version of the catalog introducing the code
+	Since string `json:"since"`
+	// Deprecated tells whether the code is deprecated
+	Deprecated bool `json:"deprecated,omitempty"`
+	// ReplacedBy is the code replacing the deprecated one, if any
+	ReplacedBy Code `json:"replaced_by,omitempty"`
+}
+
+var (
+	// codes are the codes (but UNK) in the order of the spec.
+	codes []Code
+	// catalog maps the codes to their info.
+	catalog map[Code]Info
+	// catalogVersion is the version of the catalog.
+	catalogVersion string
+)
+
+func init() {
+	var err error
+	codes, err = parseCodes(codeSpec)
+	if err != nil {
+		panic(err)
+	}
+	catalogVersion, catalog, err = parseCatalog(catalogSpec)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Codes returns all the codes (but UNK), deprecated ones included.
+func Codes() []Code {
+	return append([]Code{}, codes...)
+}
+
+// Catalog returns the info of all the codes, in the order of the codes.
+func Catalog() []Info {
+	res := make([]Info, 0, len(codes))
+	for _, c := range codes {
+		res = append(res, catalog[c])
+	}
+
+	return res
+}
+
+// CatalogVersion returns the version of the catalog.
+func CatalogVersion() string {
+	return catalogVersion
+}
+
+// Info returns the catalog entry of the receiving Code.
+func (c Code) Info() (Info, error) {
+	info, ok := catalog[c]
+	if !ok {
+		return Info{}, fmt.Errorf("couldn't find the code %q in the catalog", c.String())
+	}
+	// Do not leak the slices of the catalog
+	info.Categories = append([]category.Category{}, info.Categories...)
+	info.References = append([]string{}, info.References...)
+
+	return info, nil
+}
+
+// parseCodes reads the codes from the parallel lists of values and names of the spec.
+func parseCodes(data []byte) ([]Code, error) {
+	spec := struct {
+		Components struct {
+			Schemas struct {
+				Code struct {
+					Enum  []uint64 `json:"enum"`
+					Names []string `json:"x-enumNames"`
+				} `json:"Code"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("couldn't parse the codes spec: %w", err)
+	}
+	enum := spec.Components.Schemas.Code
+	if len(enum.Enum) != len(enum.Names) {
+		return nil, errors.New("the values and the names of the codes spec are not parallel lists")
+	}
+	res := []Code{}
+	for i, v := range enum.Enum {
+		c := Code(v)
+		if c.String() != enum.Names[i] {
+			return nil, fmt.Errorf("the code %q of the spec does not match its value (%d)", enum.Names[i], v)
+		}
+		if c != UNK {
+			res = append(res, c)
+		}
+	}
+
+	return res, nil
+}
+
+// parseCatalog reads the catalog, checking it describes every code once.
+func parseCatalog(data []byte) (string, map[Code]Info, error) {
+	spec := struct {
+		Version string `json:"version"`
+		Codes   []struct {
+			Code        string   `json:"code"`
+			Title       string   `json:"title"`
+			Description string   `json:"description"`
+			Severity    string   `json:"severity"`
+			Categories  []string `json:"categories"`
+			Remediation string   `json:"remediation"`
+			References  []string `json:"references"`
+			Since       string   `json:"since"`
+			Deprecated  bool     `json:"deprecated"`
+			ReplacedBy  string   `json:"replaced_by"`
+		} `json:"codes"`
+	}{}
+	if err := yaml.Unmarshal(data, &spec, disallowUnknownFields); err != nil {
+		return "", nil, fmt.Errorf("couldn't parse the catalog: %w", err)
+	}
+
+	byName := make(map[string]Code, len(codes))
+	for _, c := range codes {
+		byName[c.String()] = c
+	}
+	res := make(map[Code]Info, len(spec.Codes))
+	for _, entry := range spec.Codes {
+		c, ok := byName[entry.Code]
+		if !ok {
+			return "", nil, fmt.Errorf("the catalog describes the unknown code %q", entry.Code)
+		}
+		if _, ok := res[c]; ok {
+			return "", nil, fmt.Errorf("the catalog describes the code %q more than once", entry.Code)
+		}
+		if entry.Title == "" || entry.Description == "" || entry.Remediation == "" || entry.Since == "" {
+			return "", nil, fmt.Errorf("the catalog entry of the code %q misses its title, description, remediation, or since version", entry.Code)
+		}
+		sev, err := severity.New(entry.Severity)
+		if err != nil || sev == severity.Empty {
+			return "", nil, fmt.Errorf("the catalog entry of the code %q has an invalid severity (%q)", entry.Code, entry.Severity)
+		}
+		if len(entry.Categories) == 0 {
+			return "", nil, fmt.Errorf("the catalog entry of the code %q misses its categories", entry.Code)
+		}
+		cats := make([]category.Category, 0, len(entry.Categories))
+		for _, x := range entry.Categories {
+			cat, err := category.FromString(x)
+			if err != nil {
+				return "", nil, fmt.Errorf("the catalog entry of the code %q has an invalid category: %w", entry.Code, err)
+			}
+			cats = append(cats, cat)
+		}
+		info := Info{
+			Code:        c,
+			Title:       entry.Title,
+			Description: strings.TrimSpace(entry.Description),
+			Severity:    sev,
+			Categories:  cats,
+			Remediation: strings.TrimSpace(entry.Remediation),
+			References:  entry.References,
+			Since:       entry.Since,
+			Deprecated:  entry.Deprecated,
+		}
+		if entry.ReplacedBy != "" {
+			if !entry.Deprecated {
+				return "", nil, fmt.Errorf("the catalog entry of the code %q has a replacement but it is not deprecated", entry.Code)
+			}
+			replacement, ok := byName[entry.ReplacedBy]
+			if !ok {
+				return "", nil, fmt.Errorf("the catalog entry of the code %q is replaced by the unknown code %q", entry.Code, entry.ReplacedBy)
+			}
+			info.ReplacedBy = replacement
+		}
+		res[c] = info
+	}
+	for _, c := range codes {
+		if _, ok := res[c]; !ok {
+			return "", nil, fmt.Errorf("the catalog does not describe the code %q", c.String())
+		}
+	}
+	for _, info := range res {
+		if info.ReplacedBy != UNK && res[info.ReplacedBy].Deprecated {
+			return "", nil, fmt.Errorf("the code %q is replaced by the deprecated code %q", info.Code.String(), info.ReplacedBy.String())
+		}
+	}
+
+	return spec.Version, res, nil
+}
+
+func disallowUnknownFields(d *json.Decoder) *json.Decoder {
+	d.DisallowUnknownFields()
+
+	return d
+}
@@ -0,0 +1,140 @@
+package verdictcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/listendev/pkg/models/severity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodes(t *testing.T) {
+	all := Codes()
+	assert.Len(t, all, 43)
+	assert.NotContains(t, all, UNK)
+	assert.Equal(t, FNI001, all[0])
+	assert.Equal(t, STP010, all[len(all)-1])
+
+	// The codes are a copy
+	all[0] = UNK
+	assert.Equal(t, FNI001, Codes()[0])
+}
+
+func TestCatalog(t *testing.T) {
+	assert.Equal(t, "1.0.0", CatalogVersion())
+
+	for _, info := range Catalog() {
+		t.Run(info.Code.String(), func(t *testing.T) {
+			assert.NotEmpty(t, info.Title)
+			assert.NotEmpty(t, info.Description)
+			assert.NotEmpty(t, info.Remediation)
+			assert.NotEmpty(t, info.Since)
+			assert.NotEqual(t, severity.Empty, info.Severity)
+			assert.NotEmpty(t, info.Categories)
+
+			// The deprecation of the catalog is consistent with the mapping
+			for _, codemap := range mapping {
+				if notDeprecated, ok := codemap[info.Code]; ok {
+					assert.Equal(t, !notDeprecated, info.Deprecated)
+				}
+			}
+			if info.ReplacedBy != UNK {
+				assert.True(t, info.Deprecated)
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	info, err := MDN06.Info()
+	require.Nil(t, err)
+	assert.Equal(t, MDN06, info.Code)
+	assert.Equal(t, "Package scripts mismatch", info.Title)
+	assert.Equal(t, severity.High, info.Severity)
+	assert.Equal(t, "metadata", string(info.Categories[0].Case()))
+	assert.NotContains(t, info.Description, "\n")
+
+	// The info is a copy
+	info.Categories[0] = 0
+	info, _ = MDN06.Info()
+	assert.Equal(t, "metadata", string(info.Categories[0].Case()))
+
+	_, err = UNK.Info()
+	assert.Error(t, err)
+	_, err = Code(9999).Info()
+	assert.Error(t, err)
+}
+
+func TestInfoJSON(t *testing.T) {
+	info, err := STN007.Info()
+	require.Nil(t, err)
+
+	data, err := json.Marshal(info)
+	require.Nil(t, err)
+	got := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "STN007", got["code"])
+	assert.Equal(t, "high", got["severity"])
+	assert.Equal(t, []interface{}{"advisory", "network"}, got["categories"])
+	assert.NotContains(t, got, "deprecated")
+	assert.NotContains(t, got, "replaced_by")
+}
+
+func TestParseCatalog(t *testing.T) {
+	cases := []struct {
+		desc string
+		spec string
+		err  string
+	}{
+		{
+			desc: "unknown code",
+			spec: "codes:\n  - code: XYZ01",
+			err:  `the catalog describes the unknown code "XYZ01"`,
+		},
+		{
+			desc: "unknown field",
+			spec: "codes:\n  - code: FNI001\n    color: red",
+			err:  `unknown field "color"`,
+		},
+		{
+			desc: "missing title",
+			spec: "codes:\n  - code: FNI001\n    description: d\n    remediation: r\n    since: 1.0.0",
+			err:  `the catalog entry of the code "FNI001" misses its title, description, remediation, or since version`,
+		},
+		{
+			desc: "invalid severity",
+			spec: "codes:\n  - code: FNI001\n    title: t\n    description: d\n    remediation: r\n    since: 1.0.0\n    severity: huge",
+			err:  `the catalog entry of the code "FNI001" has an invalid severity ("huge")`,
+		},
+		{
+			desc: "replacement of a code not deprecated",
+			spec: "codes:\n  - code: FNI001\n    title: t\n    description: d\n    remediation: r\n    since: 1.0.0\n    severity: low\n    categories: [process]\n    replaced_by: FNI002",
+			err:  `the catalog entry of the code "FNI001" has a replacement but it is not deprecated`,
+		},
+		{
+			desc: "missing codes",
+			spec: "codes:\n  - code: FNI001\n    title: t\n    description: d\n    remediation: r\n    since: 1.0.0\n    severity: low\n    categories: [process]\n    deprecated: true\n    replaced_by: FNI002",
+			err:  `the catalog does not describe the code "FNI002"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, _, err := parseCatalog([]byte(tc.spec))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, WriteMarkdown(&buf))
+
+	want, err := os.ReadFile("CODES.md")
+	require.Nil(t, err)
+	assert.Equal(t, string(want), buf.String(), "CODES.md is outdated: run go generate")
+}
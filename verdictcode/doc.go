@@ -2,3 +2,4 @@ package verdictcode
 
 //go:generate oapi-codegen -config cfg.yml code.yml
 //go:generate stringer -type=Code
+//go:generate go run ./internal/docgen CODES.md
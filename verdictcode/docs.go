@@ -0,0 +1,72 @@
+package verdictcode
+
+import (
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/listendev/pkg/models/category"
+)
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"categories": func(cats []category.Category) string {
+		names := make([]string, 0, len(cats))
+		for _, c := range cats {
+			names = append(names, string(c.Case()))
+		}
+
+		return strings.Join(names, ", ")
+	},
+	"anchor": func(c Code) string {
+		return strings.ToLower(c.String())
+	},
+	"oneline": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+}).Parse(`# Verdict codes
+
+<!-- Code generated by go generate; DO NOT EDIT. -->
+
+Catalog version {{ .Version }}.
+
+| Code | Title | Severity | Categories |
+| ---- | ----- | -------- | ---------- |
+{{- range .Codes }}
+| [{{ .Code }}](#{{ anchor .Code }}) | {{ .Title }}{{ if .Deprecated }} (deprecated){{ end }} | {{ .Severity }} | {{ categories .Categories }} |
+{{- end }}
+{{ range .Codes }}
+## {{ .Code }}
+
+**{{ .Title }}**
+{{ if .Deprecated }}
+> Deprecated{{ if .ReplacedBy }}: use [{{ .ReplacedBy }}](#{{ anchor .ReplacedBy }}) instead{{ end }}.
+{{ end }}
+{{ oneline .Description }}
+
+- Severity: {{ .Severity }}
+- Categories: {{ categories .Categories }}
+- Since: {{ .Since }}
+
+### Remediation
+
+{{ oneline .Remediation }}
+{{- if .References }}
+
+### References
+{{ range .References }}
+- <{{ . }}>
+{{- end }}
+{{- end }}
+{{ end -}}
+`))
+
+// WriteMarkdown writes the documentation of the catalog of the codes in Markdown.
+func WriteMarkdown(w io.Writer) error {
+	return docsTemplate.Execute(w, struct {
+		Version string
+		Codes   []Info
+	}{
+		Version: CatalogVersion(),
+		Codes:   Catalog(),
+	})
+}
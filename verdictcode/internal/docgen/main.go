@@ -0,0 +1,26 @@
+// Docgen writes the Markdown documentation of the catalog of the verdict codes into the given file.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/listendev/pkg/verdictcode"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: docgen <output file>")
+		os.Exit(2)
+	}
+	var buf bytes.Buffer
+	if err := verdictcode.WriteMarkdown(&buf); err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't generate the docs: %s\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(os.Args[1], buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't write the docs: %s\n", err)
+		os.Exit(1)
+	}
+}